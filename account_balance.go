@@ -10,6 +10,10 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/proof"
+	"github.com/obingo31/go-eth/withdrawals"
 )
 
 func main() {
@@ -19,6 +23,12 @@ func main() {
 	rpcFlag := flag.String("rpc", defaultRPC, "Ethereum RPC endpoint (Infura, Alchemy, local node, etc.)")
 	addrFlag := flag.String("addr", defaultAddr, "Hex-encoded Ethereum account address")
 	blockFlag := flag.Int64("block", 5532993, "Historical block number to inspect")
+	atFlag := flag.String("at", "", "inspect the last block at or before this time instead of --block (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	proofFlag := flag.Bool("proof", false, "verify the latest and historical balances with eth_getProof against each block's stateRoot")
+	explainFlag := flag.Bool("explain", false, "explain the balance change from --block to --explain-to: withdrawals, transactions, fees and priority fees")
+	explainToFlag := flag.Int64("explain-to", -1, "last block of the --explain range (-1 for latest)")
+	explainMaxFlag := flag.Uint64("explain-max", 10000, "most blocks --explain may fetch (0 for no limit)")
 	flag.Parse()
 
 	if !common.IsHexAddress(*addrFlag) {
@@ -34,11 +44,18 @@ func main() {
 	ctx := context.Background()
 	account := common.HexToAddress(*addrFlag)
 
+	if err := blocktime.SetBlock(ctx, client, *blockTimeCacheFlag, *atFlag, blockFlag); err != nil {
+		log.Fatalf("resolve --at: %v", err)
+	}
+
 	latestBalance, err := client.BalanceAt(ctx, account, nil)
 	if err != nil {
 		log.Fatalf("latest balance: %v", err)
 	}
 	fmt.Printf("Latest balance (wei): %s\n", latestBalance)
+	if *proofFlag {
+		checkProof(ctx, client, account, nil, latestBalance)
+	}
 
 	historicalBlock := big.NewInt(*blockFlag)
 	historicalBalance, err := client.BalanceAt(ctx, account, historicalBlock)
@@ -46,6 +63,9 @@ func main() {
 		log.Fatalf("balance at block %d: %v", *blockFlag, err)
 	}
 	fmt.Printf("Balance at block %d (wei): %s\n", historicalBlock, historicalBalance)
+	if *proofFlag {
+		checkProof(ctx, client, account, historicalBlock, historicalBalance)
+	}
 
 	fbalance := new(big.Float).SetInt(historicalBalance)
 	denom := big.NewFloat(math.Pow10(18))
@@ -57,4 +77,68 @@ func main() {
 		log.Fatalf("pending balance: %v", err)
 	}
 	fmt.Printf("Pending balance (wei): %s\n", pendingBalance)
+
+	if *explainFlag {
+		explain(ctx, client, account, uint64(*blockFlag), *explainToFlag, *explainMaxFlag)
+	}
+}
+
+// explain attributes the balance change from block to the block to (-1 for
+// the head) to withdrawals, transactions and fees. Every block in between
+// is fetched with its receipts, so ranges over limit (unless 0) are refused.
+func explain(ctx context.Context, client *ethclient.Client, account common.Address, block uint64, to int64, limit uint64) {
+	end := uint64(to)
+	if to < 0 {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			log.Fatalf("fetch block number: %v", err)
+		}
+		end = head
+	}
+	if end < block {
+		log.Fatalf("--explain-to %d is before --block %d", end, block)
+	}
+	if limit > 0 && end-block > limit {
+		log.Fatalf("--explain would fetch %d blocks (%d-%d), more than --explain-max %d; narrow it with --explain-to or raise --explain-max (0 for no limit)", end-block, block+1, end, limit)
+	}
+	e, err := withdrawals.Explain(ctx, client, account, block, end, 8)
+	if err != nil {
+		log.Fatalf("explain balance change: %v", err)
+	}
+	fmt.Printf("Balance change from block %d to %d (wei): %s\n", e.From, e.To, signed(e.Change))
+	fmt.Printf("  Withdrawals      : %s (%d)\n", signed(e.Withdrawn), len(e.Withdrawals))
+	fmt.Printf("  Received by txs  : %s\n", signed(e.Received))
+	fmt.Printf("  Sent by txs      : %s\n", signed(new(big.Int).Neg(e.Sent)))
+	fmt.Printf("  Fees paid        : %s\n", signed(new(big.Int).Neg(e.Fees)))
+	fmt.Printf("  Priority fees    : %s\n", signed(e.Tips))
+	fmt.Printf("  Other (internal) : %s\n", signed(e.Other))
+	for _, w := range e.Withdrawals {
+		fmt.Printf("    block %d: withdrawal #%d from validator %d, %d gwei\n", w.Block, w.Index, w.Validator, w.Amount)
+	}
+}
+
+func signed(v *big.Int) string {
+	if v.Sign() > 0 {
+		return "+" + v.String()
+	}
+	return v.String()
+}
+
+// checkProof proves the account at block and compares the proven balance
+// with the one BalanceAt returned. The latest block may have moved on since
+// BalanceAt, so a mismatch there is only reported.
+func checkProof(ctx context.Context, client *ethclient.Client, account common.Address, block, balance *big.Int) {
+	bundle, err := proof.Fetch(ctx, client, account, nil, block)
+	if err != nil {
+		log.Fatalf("prove balance: %v", err)
+	}
+	proven := bundle.Account.Balance.ToInt()
+	if proven.Cmp(balance) != 0 {
+		if block == nil {
+			fmt.Printf("  proven balance at block %d differs: %s\n", bundle.BlockNumber, proven)
+			return
+		}
+		log.Fatalf("balance at block %d: node returned %s, proof says %s", bundle.BlockNumber, balance, proven)
+	}
+	fmt.Printf("  proven against stateRoot of block %d (%s)\n", bundle.BlockNumber, bundle.BlockHash.Hex())
 }
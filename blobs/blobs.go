@@ -0,0 +1,189 @@
+// Package blobs inspects EIP-4844 blob data: the blob gas fields of a
+// header, the versioned hashes carried by blob transactions, and sidecars
+// whose KZG commitments and proofs are checked against those hashes.
+package blobs
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Header is the blob gas accounting of a block.
+type Header struct {
+	BlobGasUsed   uint64
+	ExcessBlobGas uint64
+	Blobs         int      // BlobGasUsed / GasPerBlob
+	BlobBaseFee   *big.Int // nil when the fork schedule is unknown
+}
+
+// HeaderInfo reads the blob gas fields of header. It returns false for
+// headers from before Cancun. cfg (which may be nil) is needed to compute the
+// blob base fee, whose update fraction depends on the fork.
+func HeaderInfo(cfg *params.ChainConfig, header *types.Header) (Header, bool) {
+	if header.BlobGasUsed == nil || header.ExcessBlobGas == nil {
+		return Header{}, false
+	}
+	h := Header{
+		BlobGasUsed:   *header.BlobGasUsed,
+		ExcessBlobGas: *header.ExcessBlobGas,
+		Blobs:         int(*header.BlobGasUsed / params.BlobTxBlobGasPerBlob),
+	}
+	if cfg != nil && cfg.IsCancun(header.Number, header.Time) && cfg.BlobScheduleConfig != nil {
+		h.BlobBaseFee = eip4844.CalcBlobFee(cfg, header)
+	}
+	return h, true
+}
+
+// Tx is a blob transaction of a block.
+type Tx struct {
+	Hash          common.Hash
+	Index         int // position in the block
+	BlobGasFeeCap *big.Int
+	BlobHashes    []common.Hash
+}
+
+// Transactions returns the block's blob transactions in block order.
+func Transactions(block *types.Block) []Tx {
+	var txs []Tx
+	for i, tx := range block.Transactions() {
+		if tx.Type() != types.BlobTxType {
+			continue
+		}
+		txs = append(txs, Tx{Hash: tx.Hash(), Index: i, BlobGasFeeCap: tx.BlobGasFeeCap(), BlobHashes: tx.BlobHashes()})
+	}
+	return txs
+}
+
+// Hashes returns the versioned hashes of txs in order, which is also the
+// order of the block's blob sidecars on the consensus layer.
+func Hashes(txs []Tx) []common.Hash {
+	var hashes []common.Hash
+	for _, tx := range txs {
+		hashes = append(hashes, tx.BlobHashes...)
+	}
+	return hashes
+}
+
+// Blob is one blob of a sidecar with its commitment and either a single
+// blob proof or, since Osaka, kzg4844.CellProofsPerBlob cell proofs.
+type Blob struct {
+	Index      int
+	Blob       *kzg4844.Blob
+	Commitment kzg4844.Commitment
+	Proofs     []kzg4844.Proof
+}
+
+// VersionedHash returns the hash that commits to the blob in transactions.
+func (b *Blob) VersionedHash() common.Hash {
+	return kzg4844.CalcBlobHashV1(sha256.New(), &b.Commitment)
+}
+
+// VerifyProof checks the blob against its commitment.
+func (b *Blob) VerifyProof() error {
+	switch len(b.Proofs) {
+	case 1:
+		return kzg4844.VerifyBlobProof(b.Blob, b.Commitment, b.Proofs[0])
+	case kzg4844.CellProofsPerBlob:
+		return kzg4844.VerifyCellProofs([]kzg4844.Blob{*b.Blob}, []kzg4844.Commitment{b.Commitment}, b.Proofs)
+	default:
+		return fmt.Errorf("%d proofs, want 1 blob proof or %d cell proofs", len(b.Proofs), kzg4844.CellProofsPerBlob)
+	}
+}
+
+// Result is the outcome of checking one expected versioned hash.
+type Result struct {
+	Hash  common.Hash // versioned hash from the transaction
+	Blob  int         // sidecar index of the blob committing to it, -1 if none
+	Error error       // nil when the blob was found and its proof verifies
+}
+
+func (r Result) String() string {
+	if r.Error != nil {
+		return fmt.Sprintf("%s: %v", r.Hash.Hex(), r.Error)
+	}
+	return fmt.Sprintf("%s: blob %d, KZG proof verified", r.Hash.Hex(), r.Blob)
+}
+
+// Verify checks a sidecar against the versioned hashes the chain committed
+// to. Blobs are matched by the versioned hash of their commitment, so a
+// sidecar may cover a single transaction or a whole block. Every hash gets a
+// Result; blobs no hash refers to are returned as extra.
+func Verify(sidecar []Blob, hashes []common.Hash) (results []Result, extra []int) {
+	byHash := make(map[common.Hash]int, len(sidecar))
+	for i := range sidecar {
+		byHash[sidecar[i].VersionedHash()] = i
+	}
+	used := make(map[int]bool)
+	for _, h := range hashes {
+		r := Result{Hash: h, Blob: -1}
+		if i, ok := byHash[h]; ok {
+			used[i] = true
+			r.Blob = sidecar[i].Index
+			if err := sidecar[i].VerifyProof(); err != nil {
+				r.Error = fmt.Errorf("KZG proof: %w", err)
+			}
+		} else {
+			r.Error = fmt.Errorf("no blob in the sidecar commits to this hash")
+		}
+		results = append(results, r)
+	}
+	for i := range sidecar {
+		if !used[i] {
+			extra = append(extra, sidecar[i].Index)
+		}
+	}
+	return results, extra
+}
+
+// Report is the outcome of Check.
+type Report struct {
+	Blobs   int      // blobs in the sidecar
+	Results []Result // one per versioned hash
+	Extra   []int    // sidecar indexes no hash refers to
+	Partial bool     // the hashes are only part of what the sidecar covers
+}
+
+// Check verifies a sidecar against hashes like Verify. Partial says the
+// hashes are a subset of the block the sidecar belongs to (for example one
+// transaction's), so blobs left over are expected rather than a failure.
+func Check(sidecar []Blob, hashes []common.Hash, partial bool) *Report {
+	results, extra := Verify(sidecar, hashes)
+	return &Report{Blobs: len(sidecar), Results: results, Extra: extra, Partial: partial}
+}
+
+// Err is nil when every hash is backed by a blob whose proof verifies and,
+// unless the report is partial, no blob is left over.
+func (r *Report) Err() error {
+	failed := 0
+	for _, res := range r.Results {
+		if res.Error != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d versioned hashes are not backed by a valid blob", failed, len(r.Results))
+	}
+	if !r.Partial && len(r.Extra) > 0 {
+		return fmt.Errorf("the sidecar has %d blobs the block does not commit to", len(r.Extra))
+	}
+	return nil
+}
+
+// Print writes one line per versioned hash and the leftover blobs to w.
+func (r *Report) Print(w io.Writer) {
+	fmt.Fprintf(w, "Sidecar: %d blobs for %d versioned hashes\n", r.Blobs, len(r.Results))
+	for _, res := range r.Results {
+		fmt.Fprintf(w, "  %s\n", res)
+	}
+	if len(r.Extra) > 0 {
+		fmt.Fprintf(w, "  blobs %v match no versioned hash\n", r.Extra)
+	}
+}
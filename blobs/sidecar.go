@@ -0,0 +1,160 @@
+package blobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+)
+
+// bundle is the execution-layer sidecar layout, as in the engine API's
+// blobsBundle: parallel lists, with one proof or CellProofsPerBlob cell
+// proofs per blob.
+type bundle struct {
+	Blobs       []kzg4844.Blob       `json:"blobs"`
+	Commitments []kzg4844.Commitment `json:"commitments"`
+	Proofs      []kzg4844.Proof      `json:"proofs"`
+}
+
+// beaconSidecar is one entry of the beacon API's blob_sidecars response.
+type beaconSidecar struct {
+	Index         string             `json:"index"`
+	Blob          *kzg4844.Blob      `json:"blob"`
+	KZGCommitment kzg4844.Commitment `json:"kzg_commitment"`
+	KZGProof      kzg4844.Proof      `json:"kzg_proof"`
+}
+
+// Load reads a sidecar file: a blobsBundle object, a beacon API
+// blob_sidecars response, or the bare list of its data.
+func Load(path string) ([]Blob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return blobs, nil
+}
+
+// Fetch downloads the blob sidecars of a beacon block from a beacon node.
+// blockID is a slot, a block root, "head" or "finalized". The beacon block
+// carrying execution block N has the root that block N+1 records as its
+// parentBeaconBlockRoot.
+func Fetch(ctx context.Context, client *http.Client, beaconURL, blockID string) ([]Blob, error) {
+	url := strings.TrimRight(beaconURL, "/") + "/eth/v1/beacon/blob_sidecars/" + blockID
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch blob sidecars: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read blob sidecars: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("beacon node answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return parse(body)
+}
+
+// HeaderReader is the part of ethclient.Client needed by FetchBlock.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// FetchBlock downloads the blob sidecars of execution block number, looking
+// up its beacon block root in the following block. The block therefore
+// needs a successor before its sidecars can be fetched this way.
+func FetchBlock(ctx context.Context, chain HeaderReader, client *http.Client, beaconURL string, number uint64) ([]Blob, error) {
+	child, err := chain.HeaderByNumber(ctx, new(big.Int).SetUint64(number+1))
+	if err != nil {
+		return nil, fmt.Errorf("fetch block %d for the beacon block root: %w", number+1, err)
+	}
+	if child.ParentBeaconRoot == nil {
+		return nil, fmt.Errorf("block %d has no parent beacon block root", number+1)
+	}
+	blobs, err := Fetch(ctx, client, beaconURL, child.ParentBeaconRoot.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("beacon block %s: %w", child.ParentBeaconRoot.Hex(), err)
+	}
+	return blobs, nil
+}
+
+func parse(data []byte) ([]Blob, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		return parseBeacon(data)
+	}
+	var probe struct {
+		Data  json.RawMessage `json:"data"`
+		Blobs json.RawMessage `json:"blobs"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	switch {
+	case probe.Data != nil:
+		return parseBeacon(probe.Data)
+	case probe.Blobs != nil:
+		return parseBundle(data)
+	default:
+		return nil, fmt.Errorf("neither a blobs bundle nor blob sidecars")
+	}
+}
+
+func parseBeacon(data []byte) ([]Blob, error) {
+	var sidecars []beaconSidecar
+	if err := json.Unmarshal(data, &sidecars); err != nil {
+		return nil, err
+	}
+	blobs := make([]Blob, len(sidecars))
+	for i, s := range sidecars {
+		if s.Blob == nil {
+			return nil, fmt.Errorf("sidecar %d has no blob", i)
+		}
+		index, err := strconv.Atoi(s.Index)
+		if err != nil {
+			return nil, fmt.Errorf("sidecar %d: index %q: %w", i, s.Index, err)
+		}
+		blobs[i] = Blob{Index: index, Blob: s.Blob, Commitment: s.KZGCommitment, Proofs: []kzg4844.Proof{s.KZGProof}}
+	}
+	return blobs, nil
+}
+
+func parseBundle(data []byte) ([]Blob, error) {
+	var b bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, err
+	}
+	if len(b.Commitments) != len(b.Blobs) {
+		return nil, fmt.Errorf("%d commitments for %d blobs", len(b.Commitments), len(b.Blobs))
+	}
+	perBlob := 1
+	switch len(b.Proofs) {
+	case len(b.Blobs):
+	case len(b.Blobs) * kzg4844.CellProofsPerBlob:
+		perBlob = kzg4844.CellProofsPerBlob
+	default:
+		return nil, fmt.Errorf("%d proofs for %d blobs", len(b.Proofs), len(b.Blobs))
+	}
+	blobs := make([]Blob, len(b.Blobs))
+	for i := range b.Blobs {
+		blobs[i] = Blob{Index: i, Blob: &b.Blobs[i], Commitment: b.Commitments[i], Proofs: b.Proofs[i*perBlob : (i+1)*perBlob]}
+	}
+	return blobs, nil
+}
@@ -5,9 +5,11 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/obingo31/go-eth/reorg"
+	"github.com/obingo31/go-eth/subscription"
 )
 
 func main() {
@@ -15,14 +17,22 @@ func main() {
 }
 
 func subscribeToBlocks() {
-	wsURL := flag.String("ws", "ws://127.0.0.1:8545", "WebSocket RPC endpoint (Ganache, Anvil, etc.)")
+	wsURL := flag.String("ws", "ws://127.0.0.1:8545", "RPC endpoint (Ganache, Anvil, etc.); http:// falls back to filter polling")
+	modeFlag := flag.String("mode", "auto", "subscription mechanism: auto, ws, filter or poll")
+	pollFlag := flag.Duration("poll", 2*time.Second, "polling interval when the endpoint cannot push new heads")
+	windowFlag := flag.Int("window", 128, "recent blocks remembered to detect reorgs")
 	flag.Parse()
 
+	mode, err := subscription.ParseMode(*modeFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	ctx := context.Background()
 
-	client, err := ethclient.Dial(*wsURL)
+	client, err := subscription.Dial(ctx, *wsURL, subscription.Options{Mode: mode, Interval: *pollFlag})
 	if err != nil {
-		log.Fatalf("dial websocket: %v", err)
+		log.Fatalf("dial rpc: %v", err)
 	}
 	defer client.Close()
 
@@ -32,6 +42,9 @@ func subscribeToBlocks() {
 		log.Fatalf("subscribe new heads: %v", err)
 	}
 	defer sub.Unsubscribe()
+	log.Printf("receiving new heads via %s", client.Mode())
+
+	detector := reorg.NewDetector(client, reorg.Config{Window: *windowFlag})
 
 	for {
 		select {
@@ -40,6 +53,12 @@ func subscribeToBlocks() {
 		case header := <-headers:
 			fmt.Printf("new head %s\n", header.Hash().Hex())
 
+			if ev, err := detector.Add(ctx, header); err != nil {
+				log.Printf("reorg check: %v", err)
+			} else if ev != nil {
+				fmt.Printf("%s\n", ev)
+			}
+
 			block, err := client.BlockByHash(ctx, header.Hash())
 			if err != nil {
 				log.Fatalf("fetch block %s: %v", header.Hash(), err)
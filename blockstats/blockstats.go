@@ -0,0 +1,297 @@
+// Package blockstats walks a block range and collects per-block series and
+// range aggregates: gas used against the EIP-1559 target, base fee and its
+// deltas, blob gas, the transaction type mix, top senders and recipients,
+// contract creations and empty blocks.
+package blockstats
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/obingo31/go-eth/parallel"
+)
+
+// BlockReader is the part of ethclient.Client the collector needs.
+type BlockReader interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Config tunes a collection. Zero fields take the defaults.
+type Config struct {
+	Concurrency int // blocks fetched in parallel (default 8)
+	Top         int // senders and recipients kept in the summary (default 10)
+}
+
+func (c Config) withDefaults() Config {
+	if c.Concurrency <= 0 {
+		c.Concurrency = 8
+	}
+	if c.Top <= 0 {
+		c.Top = 10
+	}
+	return c
+}
+
+// Block is one row of the per-block series.
+type Block struct {
+	Number        uint64
+	Hash          common.Hash
+	Time          uint64
+	Txs           int
+	GasUsed       uint64
+	GasLimit      uint64
+	GasTarget     uint64   // GasLimit / elasticity multiplier
+	BaseFee       *big.Int // nil before London
+	BaseFeeDelta  *big.Int // change from the parent block; nil without a base fee
+	BlobGasUsed   uint64
+	ExcessBlobGas uint64
+	Blobs         int
+	Types         map[uint8]int // transactions per type
+	Creations     int
+
+	senders, recipients map[common.Address]int
+}
+
+// Empty reports whether the block has no transactions.
+func (b *Block) Empty() bool { return b.Txs == 0 }
+
+// GasRatio is gas used over the target: 1 is exactly on target, 2 a full
+// block. It is 0 when the target is unknown.
+func (b *Block) GasRatio() float64 {
+	if b.GasTarget == 0 {
+		return 0
+	}
+	return float64(b.GasUsed) / float64(b.GasTarget)
+}
+
+// Count is an address with its number of transactions.
+type Count struct {
+	Address common.Address
+	Txs     int
+}
+
+// Summary aggregates a range.
+type Summary struct {
+	From, To      uint64
+	Blocks        int
+	Empty         int
+	Txs           int
+	GasUsed       uint64
+	GasTarget     uint64
+	AboveTarget   int // blocks using more gas than the target
+	BelowTarget   int
+	BaseFeeFirst  *big.Int
+	BaseFeeLast   *big.Int
+	BaseFeeMin    *big.Int
+	BaseFeeMax    *big.Int
+	MaxIncrease   *big.Int // largest block-to-block base fee rise
+	MaxDecrease   *big.Int // largest block-to-block base fee drop, as a positive amount
+	BlobGasUsed   uint64
+	Blobs         int
+	Types         map[uint8]int
+	Creations     int
+	Senders       int // distinct
+	Recipients    int // distinct
+	TopSenders    []Count
+	TopRecipients []Count
+}
+
+// GasRatio is the range's total gas used over its total target.
+func (s *Summary) GasRatio() float64 {
+	if s.GasTarget == 0 {
+		return 0
+	}
+	return float64(s.GasUsed) / float64(s.GasTarget)
+}
+
+// Report is the result of Collect.
+type Report struct {
+	Blocks  []*Block // in block order
+	Summary Summary
+}
+
+// TypeName names a transaction type.
+func TypeName(t uint8) string {
+	switch t {
+	case types.LegacyTxType:
+		return "legacy"
+	case types.AccessListTxType:
+		return "access-list"
+	case types.DynamicFeeTxType:
+		return "dynamic-fee"
+	case types.BlobTxType:
+		return "blob"
+	case types.SetCodeTxType:
+		return "set-code"
+	}
+	return fmt.Sprintf("type-%d", t)
+}
+
+// Collect fetches blocks from..to (inclusive) with full transactions and
+// builds the report. The parent of from is read too, so the first block has
+// a base fee delta.
+func Collect(ctx context.Context, client BlockReader, from, to uint64, cfg Config) (*Report, error) {
+	if to < from {
+		return nil, fmt.Errorf("empty range %d-%d", from, to)
+	}
+	cfg = cfg.withDefaults()
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch chain ID: %w", err)
+	}
+	signer := types.LatestSignerForChainID(chainID)
+
+	var parentFee *big.Int
+	if from > 0 {
+		parent, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(from-1))
+		if err != nil {
+			return nil, fmt.Errorf("fetch header %d: %w", from-1, err)
+		}
+		parentFee = parent.BaseFee
+	}
+
+	blocks := make([]*Block, to-from+1)
+	err = parallel.Run(ctx, len(blocks), cfg.Concurrency, func(ctx context.Context, i int) error {
+		number := from + uint64(i)
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return fmt.Errorf("fetch block %d: %w", number, err)
+		}
+		blocks[i] = inspect(block, signer)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, b := range blocks {
+		if b.BaseFee != nil && parentFee != nil {
+			b.BaseFeeDelta = new(big.Int).Sub(b.BaseFee, parentFee)
+		}
+		parentFee = b.BaseFee
+	}
+	return &Report{Blocks: blocks, Summary: summarize(from, to, blocks, cfg.Top)}, nil
+}
+
+func inspect(block *types.Block, signer types.Signer) *Block {
+	b := &Block{
+		Number:     block.NumberU64(),
+		Hash:       block.Hash(),
+		Time:       block.Time(),
+		Txs:        len(block.Transactions()),
+		GasUsed:    block.GasUsed(),
+		GasLimit:   block.GasLimit(),
+		BaseFee:    block.BaseFee(),
+		Types:      make(map[uint8]int),
+		senders:    make(map[common.Address]int),
+		recipients: make(map[common.Address]int),
+	}
+	if b.BaseFee != nil {
+		b.GasTarget = b.GasLimit / params.DefaultElasticityMultiplier
+	}
+	if used := block.BlobGasUsed(); used != nil {
+		b.BlobGasUsed = *used
+	}
+	if excess := block.ExcessBlobGas(); excess != nil {
+		b.ExcessBlobGas = *excess
+	}
+	for _, tx := range block.Transactions() {
+		b.Types[tx.Type()]++
+		b.Blobs += len(tx.BlobHashes())
+		// ethclient caches the sender the node reported, so this rarely
+		// needs to recover the signature.
+		if from, err := types.Sender(signer, tx); err == nil {
+			b.senders[from]++
+		}
+		if to := tx.To(); to != nil {
+			b.recipients[*to]++
+		} else {
+			b.Creations++
+		}
+	}
+	return b
+}
+
+func summarize(from, to uint64, blocks []*Block, top int) Summary {
+	s := Summary{From: from, To: to, Blocks: len(blocks), Types: make(map[uint8]int)}
+	senders := make(map[common.Address]int)
+	recipients := make(map[common.Address]int)
+	for _, b := range blocks {
+		if b.Empty() {
+			s.Empty++
+		}
+		s.Txs += b.Txs
+		s.GasUsed += b.GasUsed
+		s.GasTarget += b.GasTarget
+		if b.GasTarget > 0 {
+			if b.GasUsed > b.GasTarget {
+				s.AboveTarget++
+			} else if b.GasUsed < b.GasTarget {
+				s.BelowTarget++
+			}
+		}
+		if b.BaseFee != nil {
+			if s.BaseFeeFirst == nil {
+				s.BaseFeeFirst = b.BaseFee
+			}
+			s.BaseFeeLast = b.BaseFee
+			if s.BaseFeeMin == nil || b.BaseFee.Cmp(s.BaseFeeMin) < 0 {
+				s.BaseFeeMin = b.BaseFee
+			}
+			if s.BaseFeeMax == nil || b.BaseFee.Cmp(s.BaseFeeMax) > 0 {
+				s.BaseFeeMax = b.BaseFee
+			}
+		}
+		if d := b.BaseFeeDelta; d != nil {
+			if d.Sign() > 0 && (s.MaxIncrease == nil || d.Cmp(s.MaxIncrease) > 0) {
+				s.MaxIncrease = d
+			}
+			if d.Sign() < 0 && (s.MaxDecrease == nil || d.CmpAbs(s.MaxDecrease) > 0) {
+				s.MaxDecrease = new(big.Int).Neg(d)
+			}
+		}
+		s.BlobGasUsed += b.BlobGasUsed
+		s.Blobs += b.Blobs
+		for t, n := range b.Types {
+			s.Types[t] += n
+		}
+		s.Creations += b.Creations
+		for a, n := range b.senders {
+			senders[a] += n
+		}
+		for a, n := range b.recipients {
+			recipients[a] += n
+		}
+	}
+	s.Senders, s.Recipients = len(senders), len(recipients)
+	s.TopSenders = topCounts(senders, top)
+	s.TopRecipients = topCounts(recipients, top)
+	return s
+}
+
+// topCounts returns the n addresses with the most transactions, ties broken
+// by address so the output is stable.
+func topCounts(m map[common.Address]int, n int) []Count {
+	out := make([]Count, 0, len(m))
+	for a, txs := range m {
+		out = append(out, Count{Address: a, Txs: txs})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Txs != out[j].Txs {
+			return out[i].Txs > out[j].Txs
+		}
+		return out[i].Address.Cmp(out[j].Address) < 0
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
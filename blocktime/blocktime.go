@@ -0,0 +1,337 @@
+// Package blocktime maps timestamps to block numbers. Block timestamps
+// only grow, so a Resolver binary-searches the headers between genesis and
+// the head. Every header it fetches becomes a known point that narrows
+// later searches; points deep enough below the head to be final can be kept
+// in a cache file, keyed by genesis hash, so later runs start from them.
+package blocktime
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// finality is how far below the head a point must be to be cached; younger
+// blocks may still be reorganised away.
+const finality = 64
+
+// HeaderReader is the part of *ethclient.Client a Resolver needs.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Point is a block number with its timestamp.
+type Point struct {
+	Number uint64 `json:"number"`
+	Time   uint64 `json:"time"`
+}
+
+// Timestamp returns the block time in UTC.
+func (p Point) Timestamp() time.Time { return time.Unix(int64(p.Time), 0).UTC() }
+
+// ErrNoBlock is returned when no block lies on the requested side of a time:
+// nothing at or before a time earlier than genesis, nothing at or after a
+// time later than the head.
+var ErrNoBlock = errors.New("no block")
+
+// Resolver finds blocks by timestamp. It is not safe for concurrent use.
+type Resolver struct {
+	client  HeaderReader
+	path    string
+	genesis string
+	head    Point
+	points  []Point // known points sorted by number, genesis and head included
+	added   bool    // whether points were learnt that the cache file lacks
+}
+
+// cacheFile maps genesis hashes to the points cached for that chain.
+type cacheFile map[string][]Point
+
+// Open returns a resolver for the chain behind client, seeded with the
+// points cached at path. An empty path keeps the points in memory only.
+func Open(ctx context.Context, client HeaderReader, path string) (*Resolver, error) {
+	genesis, err := client.HeaderByNumber(ctx, big.NewInt(0))
+	if err != nil {
+		return nil, fmt.Errorf("fetch genesis header: %w", err)
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch head header: %w", err)
+	}
+	r := &Resolver{
+		client:  client,
+		path:    path,
+		genesis: genesis.Hash().Hex(),
+		head:    Point{Number: head.Number.Uint64(), Time: head.Time},
+	}
+	r.points = []Point{{Number: 0, Time: genesis.Time}}
+	if path != "" {
+		cache, err := loadCache(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range cache[r.genesis] {
+			if p.Number > 0 && p.Number < r.head.Number {
+				r.points = append(r.points, p)
+			}
+		}
+	}
+	if r.head.Number > 0 {
+		r.points = append(r.points, r.head)
+	}
+	slices.SortFunc(r.points, func(a, b Point) int { return cmp.Compare(a.Number, b.Number) })
+	r.points = slices.CompactFunc(r.points, func(a, b Point) bool { return a.Number == b.Number })
+	return r, nil
+}
+
+// Head is the head block seen when the resolver was opened.
+func (r *Resolver) Head() Point { return r.head }
+
+// After returns the first block whose timestamp is at or after t.
+func (r *Resolver) After(ctx context.Context, t time.Time) (Point, error) {
+	ts := unixCeil(t)
+	n, err := r.firstAtOrAfter(ctx, ts)
+	if err != nil {
+		return Point{}, err
+	}
+	if n > r.head.Number {
+		return Point{}, fmt.Errorf("%w at or after %s: head %d is from %s", ErrNoBlock, t.UTC().Format(time.RFC3339), r.head.Number, r.head.Timestamp().Format(time.RFC3339))
+	}
+	return r.point(ctx, n)
+}
+
+// Before returns the last block whose timestamp is at or before t, i.e.
+// the block whose state was current at t.
+func (r *Resolver) Before(ctx context.Context, t time.Time) (Point, error) {
+	var n uint64
+	if t.Unix() >= 0 {
+		var err error
+		if n, err = r.firstAtOrAfter(ctx, uint64(t.Unix())+1); err != nil {
+			return Point{}, err
+		}
+	}
+	if n == 0 {
+		return Point{}, fmt.Errorf("%w at or before %s: genesis is from %s", ErrNoBlock, t.UTC().Format(time.RFC3339), r.points[0].Timestamp().Format(time.RFC3339))
+	}
+	return r.point(ctx, n-1)
+}
+
+// firstAtOrAfter returns the first block with a timestamp of at least ts,
+// or head+1 if there is none yet.
+func (r *Resolver) firstAtOrAfter(ctx context.Context, ts uint64) (uint64, error) {
+	// Narrow [lo, hi) to the known points either side of ts.
+	i, _ := slices.BinarySearchFunc(r.points, ts, func(p Point, ts uint64) int { return cmp.Compare(p.Time, ts) })
+	lo, hi := uint64(0), r.head.Number+1
+	if i > 0 {
+		lo = r.points[i-1].Number + 1
+	}
+	if i < len(r.points) {
+		hi = r.points[i].Number
+	}
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		p, err := r.point(ctx, mid)
+		if err != nil {
+			return 0, err
+		}
+		if p.Time >= ts {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, nil
+}
+
+// point returns block n's point, from the known points or the node.
+func (r *Resolver) point(ctx context.Context, n uint64) (Point, error) {
+	i, found := slices.BinarySearchFunc(r.points, n, func(p Point, n uint64) int { return cmp.Compare(p.Number, n) })
+	if found {
+		return r.points[i], nil
+	}
+	h, err := r.client.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return Point{}, fmt.Errorf("fetch header %d: %w", n, err)
+	}
+	p := Point{Number: n, Time: h.Time}
+	r.points = slices.Insert(r.points, i, p)
+	if n+finality <= r.head.Number {
+		r.added = true
+	}
+	return p, nil
+}
+
+// Save adds the final points learnt since Open to the cache file. It does
+// nothing for an in-memory resolver or when nothing new was learnt.
+func (r *Resolver) Save() error {
+	if r.path == "" || !r.added {
+		return nil
+	}
+	// Reread the file so points other runs or chains saved meanwhile are kept.
+	cache, err := loadCache(r.path)
+	if err != nil {
+		return err
+	}
+	if cache == nil {
+		cache = make(cacheFile)
+	}
+	points := cache[r.genesis]
+	for _, p := range r.points {
+		if p.Number > 0 && p.Number+finality <= r.head.Number {
+			points = append(points, p)
+		}
+	}
+	slices.SortFunc(points, func(a, b Point) int { return cmp.Compare(a.Number, b.Number) })
+	cache[r.genesis] = slices.CompactFunc(points, func(a, b Point) bool { return a.Number == b.Number })
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(r.path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("write block time cache: %w", err)
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("write block time cache: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write block time cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write block time cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		return fmt.Errorf("write block time cache: %w", err)
+	}
+	r.added = false
+	return nil
+}
+
+func loadCache(path string) (cacheFile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read block time cache: %w", err)
+	}
+	var cache cacheFile
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("parse block time cache %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+// Lookup parses s with ParseTime and resolves it against the cache at path:
+// with after, to the first block at or after the time, otherwise to the
+// last block at or before it.
+func Lookup(ctx context.Context, client HeaderReader, path, s string, after bool) (Point, error) {
+	t, err := ParseTime(s)
+	if err != nil {
+		return Point{}, err
+	}
+	r, err := Open(ctx, client, path)
+	if err != nil {
+		return Point{}, err
+	}
+	var p Point
+	if after {
+		p, err = r.After(ctx, t)
+	} else {
+		p, err = r.Before(ctx, t)
+	}
+	if err != nil {
+		return Point{}, err
+	}
+	return p, r.Save()
+}
+
+// LookupRange resolves fromAt to the first block at or after it and toAt to
+// the last block at or before it, so the range holds exactly the blocks
+// between the two times. An empty string leaves its bound nil; with both
+// empty nothing is fetched.
+func LookupRange(ctx context.Context, client HeaderReader, path, fromAt, toAt string) (from, to *Point, err error) {
+	if fromAt == "" && toAt == "" {
+		return nil, nil, nil
+	}
+	var start, end time.Time
+	if fromAt != "" {
+		if start, err = ParseTime(fromAt); err != nil {
+			return nil, nil, err
+		}
+	}
+	if toAt != "" {
+		if end, err = ParseTime(toAt); err != nil {
+			return nil, nil, err
+		}
+	}
+	r, err := Open(ctx, client, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if fromAt != "" {
+		p, err := r.After(ctx, start)
+		if err != nil {
+			return nil, nil, err
+		}
+		from = &p
+	}
+	if toAt != "" {
+		p, err := r.Before(ctx, end)
+		if err != nil {
+			return nil, nil, err
+		}
+		to = &p
+	}
+	return from, to, r.Save()
+}
+
+// layouts are the forms ParseTime accepts besides unix seconds; those
+// without a zone are UTC.
+var layouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	time.DateOnly,
+}
+
+// ParseTime parses an RFC 3339 timestamp, a date or date and time (UTC),
+// or unix seconds.
+func ParseTime(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0).UTC(), nil
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want RFC 3339 like 2024-01-01T00:00:00Z, a date like 2024-01-01 or unix seconds)", s)
+}
+
+// unixCeil rounds t up to whole seconds, the resolution of block times.
+func unixCeil(t time.Time) uint64 {
+	s := t.Unix()
+	if t.Nanosecond() > 0 {
+		s++
+	}
+	return uint64(max(s, 0))
+}
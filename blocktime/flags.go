@@ -0,0 +1,52 @@
+package blocktime
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Number is the type of a block number flag.
+type Number interface{ ~int64 | ~uint64 }
+
+// SetBlock resolves an --at value to the last block at or before it, stores
+// the number in block and logs the choice. An empty at leaves block alone
+// and makes no request. cache is the point cache file ("" for none).
+func SetBlock[N Number](ctx context.Context, client HeaderReader, cache, at string, block *N) error {
+	if at == "" {
+		return nil
+	}
+	p, err := Lookup(ctx, client, cache, at, false)
+	if err != nil {
+		return err
+	}
+	log.Printf("--at %s: block %d (%s)", at, p.Number, p.Timestamp().Format(time.RFC3339))
+	*block = N(p.Number)
+	return nil
+}
+
+// SetRange resolves --from-at and --to-at values like LookupRange, stores
+// the numbers in from and to and logs the choices. Empty values leave their
+// bound alone.
+func SetRange[F, T Number](ctx context.Context, client HeaderReader, cache, fromAt, toAt string, from *F, to *T) error {
+	start, end, err := LookupRange(ctx, client, cache, fromAt, toAt)
+	if err != nil {
+		return err
+	}
+	if start != nil {
+		log.Printf("--from-at %s: block %d (%s)", fromAt, start.Number, start.Timestamp().Format(time.RFC3339))
+		*from = F(start.Number)
+	}
+	if end != nil {
+		log.Printf("--to-at %s: block %d (%s)", toAt, end.Number, end.Timestamp().Format(time.RFC3339))
+		*to = T(end.Number)
+	}
+	return nil
+}
+
+// SetFrom is SetRange for commands that only take a start block, such as
+// those that follow the head.
+func SetFrom[N Number](ctx context.Context, client HeaderReader, cache, fromAt string, from *N) error {
+	var to uint64
+	return SetRange(ctx, client, cache, fromAt, "", from, &to)
+}
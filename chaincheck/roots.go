@@ -0,0 +1,197 @@
+package chaincheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Tries named in RootError.Trie.
+const (
+	TrieTransactions = "transactions"
+	TrieReceipts     = "receipts"
+)
+
+// RootError reports a trie rebuilt from fetched data that does not match
+// the root committed to in the header.
+type RootError struct {
+	Block    uint64
+	Hash     common.Hash
+	Trie     string
+	Header   common.Hash // root in the header
+	Computed common.Hash // root of the fetched data
+}
+
+func (e *RootError) Error() string {
+	return fmt.Sprintf("block %d: %s root %s in the header, fetched data hashes to %s", e.Block, e.Trie, e.Header.Hex(), e.Computed.Hex())
+}
+
+// TxRoot rebuilds the transactions trie and returns its root.
+func TxRoot(txs types.Transactions) common.Hash {
+	return types.DeriveSha(txs, trie.NewStackTrie(nil))
+}
+
+// ReceiptRoot rebuilds the receipts trie and returns its root. Only the
+// consensus fields (status, cumulative gas, bloom and logs) are hashed.
+func ReceiptRoot(receipts types.Receipts) common.Hash {
+	return types.DeriveSha(receipts, trie.NewStackTrie(nil))
+}
+
+// CheckTransactions verifies the block's transactions against its TxHash.
+func CheckTransactions(block *types.Block) error {
+	if computed := TxRoot(block.Transactions()); computed != block.TxHash() {
+		return &RootError{Block: block.NumberU64(), Hash: block.Hash(), Trie: TrieTransactions, Header: block.TxHash(), Computed: computed}
+	}
+	return nil
+}
+
+// CheckReceipts verifies receipts against the header's ReceiptHash.
+func CheckReceipts(header *types.Header, receipts types.Receipts) error {
+	if computed := ReceiptRoot(receipts); computed != header.ReceiptHash {
+		return &RootError{Block: header.Number.Uint64(), Hash: header.Hash(), Trie: TrieReceipts, Header: header.ReceiptHash, Computed: computed}
+	}
+	return nil
+}
+
+// BodyReader is the part of ethclient.Client needed to fetch a block with
+// its receipts.
+type BodyReader interface {
+	BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+	BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error)
+}
+
+// FetchVerified fetches a block and its receipts and checks that the header
+// hashes to hash and that both tries match the header, so everything
+// returned is as trustworthy as hash itself.
+func FetchVerified(ctx context.Context, client BodyReader, hash common.Hash) (*types.Block, types.Receipts, error) {
+	block, err := client.BlockByHash(ctx, hash)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch block %s: %w", hash.Hex(), err)
+	}
+	if block.Hash() != hash {
+		return nil, nil, fmt.Errorf("block %s: header hashes to %s", hash.Hex(), block.Hash().Hex())
+	}
+	if err := CheckTransactions(block); err != nil {
+		return nil, nil, err
+	}
+	receipts, err := client.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(hash, false))
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch receipts of block %d: %w", block.NumberU64(), err)
+	}
+	if err := CheckReceipts(block.Header(), receipts); err != nil {
+		return nil, nil, err
+	}
+	return block, receipts, nil
+}
+
+// verifiedBlockCache bounds the blocks a LogVerifier keeps; scans deliver
+// logs in block order, so only the most recent blocks are ever hit.
+const verifiedBlockCache = 64
+
+// LogVerifier checks logs returned by eth_getLogs against receipts whose
+// root matches the header. It is safe for concurrent use.
+type LogVerifier struct {
+	client BodyReader
+
+	mu     sync.Mutex
+	blocks map[common.Hash]*verifiedBlock
+	stats  LogStats
+}
+
+// LogStats counts a LogVerifier's work.
+type LogStats struct {
+	Blocks int // blocks whose roots were verified
+	Logs   int // logs matched against verified receipts
+}
+
+type verifiedBlock struct {
+	block    *types.Block
+	receipts types.Receipts
+}
+
+// NewLogVerifier returns a LogVerifier fetching blocks from client.
+func NewLogVerifier(client BodyReader) *LogVerifier {
+	return &LogVerifier{client: client, blocks: make(map[common.Hash]*verifiedBlock)}
+}
+
+// Stats returns the verifier's counters.
+func (v *LogVerifier) Stats() LogStats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.stats
+}
+
+// Verify checks that l is in its block's verified receipts, at the position
+// its log index implies and in the transaction its index and hash name,
+// with the same address, topics and data.
+func (v *LogVerifier) Verify(ctx context.Context, l types.Log) error {
+	vb, err := v.block(ctx, l.BlockHash)
+	if err != nil {
+		return err
+	}
+	where := fmt.Sprintf("log %d of block %d", l.Index, l.BlockNumber)
+	txs := vb.block.Transactions()
+	if int(l.TxIndex) >= len(txs) || txs[l.TxIndex].Hash() != l.TxHash {
+		return fmt.Errorf("%s: block has no transaction %s at index %d", where, l.TxHash.Hex(), l.TxIndex)
+	}
+	// Log indexes count across the block, so skip the earlier receipts' logs.
+	pos := int(l.Index)
+	for _, r := range vb.receipts[:l.TxIndex] {
+		pos -= len(r.Logs)
+	}
+	logs := vb.receipts[l.TxIndex].Logs
+	if pos < 0 || pos >= len(logs) {
+		return fmt.Errorf("%s: transaction %s has no such log", where, l.TxHash.Hex())
+	}
+	want := logs[pos]
+	if want.Address != l.Address || !equalTopics(want.Topics, l.Topics) || !bytes.Equal(want.Data, l.Data) {
+		return fmt.Errorf("%s: does not match the verified receipt", where)
+	}
+	v.mu.Lock()
+	v.stats.Logs++
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *LogVerifier) block(ctx context.Context, hash common.Hash) (*verifiedBlock, error) {
+	v.mu.Lock()
+	vb, ok := v.blocks[hash]
+	v.mu.Unlock()
+	if ok {
+		return vb, nil
+	}
+	block, receipts, err := FetchVerified(ctx, v.client, hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return nil, fmt.Errorf("block %d: %d receipts for %d transactions", block.NumberU64(), len(receipts), len(block.Transactions()))
+	}
+	vb = &verifiedBlock{block: block, receipts: receipts}
+	v.mu.Lock()
+	if len(v.blocks) >= verifiedBlockCache {
+		clear(v.blocks)
+	}
+	v.blocks[hash] = vb
+	v.stats.Blocks++
+	v.mu.Unlock()
+	return vb, nil
+}
+
+func equalTopics(a, b []common.Hash) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
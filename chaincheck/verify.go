@@ -0,0 +1,277 @@
+// Package chaincheck verifies that an RPC provider serves a consistent
+// header chain: parent-hash linkage, header hashes recomputed from RLP, the
+// EIP-1559 base fee and EIP-4844 excess blob gas formulas, and the
+// post-merge difficulty and nonce. It also rebuilds the transactions and
+// receipts tries of fetched blocks to check bodies, receipts and logs
+// against their headers.
+package chaincheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip1559"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Checks reported in Issue.Check.
+const (
+	CheckFetch         = "fetch"           // the block could not be decoded
+	CheckHash          = "hash"            // keccak(rlp(header)) differs from the reported hash
+	CheckNumber        = "number"          // not parent number + 1
+	CheckParentHash    = "parent-hash"     // does not point at the previous block
+	CheckBaseFee       = "base-fee"        // EIP-1559 formula
+	CheckExcessBlobGas = "excess-blob-gas" // EIP-4844 formula
+	CheckDifficulty    = "difficulty"      // non-zero after the merge
+	CheckNonce         = "nonce"           // non-zero after the merge
+)
+
+// mainnetMergeBlock is the first proof-of-stake block on mainnet, which the
+// mainnet chain config only records as a total difficulty.
+const mainnetMergeBlock = 15537394
+
+// Chains are the chain configs known by name.
+var Chains = map[string]*params.ChainConfig{
+	"mainnet": params.MainnetChainConfig,
+	"sepolia": params.SepoliaChainConfig,
+	"holesky": params.HoleskyChainConfig,
+	"hoodi":   params.HoodiChainConfig,
+	"dev":     params.AllDevChainProtocolChanges,
+}
+
+// ChainConfig returns the known config with the given chain ID.
+func ChainConfig(chainID *big.Int) (*params.ChainConfig, error) {
+	for _, cfg := range Chains {
+		if cfg.ChainID.Cmp(chainID) == 0 {
+			return cfg, nil
+		}
+	}
+	return nil, fmt.Errorf("no known chain config for chain ID %s", chainID)
+}
+
+// Config tunes a verification. Chain is required; zero fields otherwise
+// take the defaults.
+type Config struct {
+	Chain *params.ChainConfig
+	Batch int // headers per batched eth_getBlockByNumber request (default 100)
+}
+
+// Issue is one failed check.
+type Issue struct {
+	Block  uint64      `json:"block"`
+	Hash   common.Hash `json:"hash"`
+	Check  string      `json:"check"`
+	Detail string      `json:"detail"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("block %d: %s: %s", i.Block, i.Check, i.Detail)
+}
+
+// Result is the outcome of Verify.
+type Result struct {
+	From    uint64  `json:"from"`
+	To      uint64  `json:"to"`
+	Checked int     `json:"checked"`
+	Issues  []Issue `json:"issues"`
+}
+
+// OK reports whether every check passed.
+func (r *Result) OK() bool { return len(r.Issues) == 0 }
+
+// Verify checks blocks from..to (inclusive). The parent of from is fetched
+// so the first block is checked against it too. fn, if not nil, is called
+// with each issue as it is found.
+func Verify(ctx context.Context, client *rpc.Client, from, to uint64, cfg Config, fn func(Issue)) (*Result, error) {
+	if cfg.Chain == nil {
+		return nil, fmt.Errorf("a chain config is required")
+	}
+	if to < from {
+		return nil, fmt.Errorf("empty range %d-%d", from, to)
+	}
+	if cfg.Batch <= 0 {
+		cfg.Batch = 100
+	}
+	res := &Result{From: from, To: to, Issues: []Issue{}}
+	report := func(i Issue) {
+		res.Issues = append(res.Issues, i)
+		if fn != nil {
+			fn(i)
+		}
+	}
+
+	var parent *header
+	start := from
+	if from > 0 {
+		start = from - 1
+	}
+	for lo := start; lo <= to; lo += uint64(cfg.Batch) {
+		hi := min(lo+uint64(cfg.Batch)-1, to)
+		raws, err := fetch(ctx, client, lo, hi)
+		if err != nil {
+			return nil, err
+		}
+		for i, raw := range raws {
+			number := lo + uint64(i)
+			h, issues := checkHeader(number, raw)
+			if number < from {
+				parent = h
+				continue
+			}
+			res.Checked++
+			for _, issue := range issues {
+				report(issue)
+			}
+			if h == nil {
+				parent = nil
+				continue
+			}
+			if parent != nil {
+				for _, issue := range checkAgainstParent(cfg.Chain, parent, h) {
+					report(issue)
+				}
+			}
+			for _, issue := range checkConsensus(cfg.Chain, h) {
+				report(issue)
+			}
+			parent = h
+		}
+	}
+	return res, nil
+}
+
+// fetch returns the raw JSON of blocks lo..hi without transactions.
+func fetch(ctx context.Context, client *rpc.Client, lo, hi uint64) ([]json.RawMessage, error) {
+	out := make([]json.RawMessage, hi-lo+1)
+	elems := make([]rpc.BatchElem, len(out))
+	for i := range elems {
+		elems[i] = rpc.BatchElem{
+			Method: "eth_getBlockByNumber",
+			Args:   []interface{}{hexutil.EncodeUint64(lo + uint64(i)), false},
+			Result: &out[i],
+		}
+	}
+	if err := client.BatchCallContext(ctx, elems); err != nil {
+		return nil, fmt.Errorf("fetch blocks %d-%d: %w", lo, hi, err)
+	}
+	for i, e := range elems {
+		if e.Error != nil {
+			return nil, fmt.Errorf("fetch block %d: %w", lo+uint64(i), e.Error)
+		}
+	}
+	return out, nil
+}
+
+// header is a decoded header with the hash the provider reported for it,
+// which is what its child must link to.
+type header struct {
+	*types.Header
+	hash common.Hash
+}
+
+// checkHeader decodes a block and recomputes its hash. It returns a nil
+// header if the block is missing or cannot be decoded.
+func checkHeader(number uint64, raw json.RawMessage) (*header, []Issue) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, []Issue{{Block: number, Check: CheckFetch, Detail: "block not found"}}
+	}
+	var reported struct {
+		Hash common.Hash `json:"hash"`
+	}
+	h := new(types.Header)
+	if err := json.Unmarshal(raw, h); err != nil {
+		return nil, []Issue{{Block: number, Check: CheckFetch, Detail: err.Error()}}
+	}
+	if err := json.Unmarshal(raw, &reported); err != nil {
+		return nil, []Issue{{Block: number, Check: CheckFetch, Detail: err.Error()}}
+	}
+	var issues []Issue
+	if got := h.Number.Uint64(); got != number {
+		issues = append(issues, Issue{Block: number, Hash: reported.Hash, Check: CheckNumber, Detail: fmt.Sprintf("asked for block %d, got %d", number, got)})
+	}
+	if computed := h.Hash(); computed != reported.Hash {
+		issues = append(issues, Issue{Block: number, Hash: reported.Hash, Check: CheckHash,
+			Detail: fmt.Sprintf("reported %s, RLP hashes to %s", reported.Hash.Hex(), computed.Hex())})
+	}
+	return &header{Header: h, hash: reported.Hash}, issues
+}
+
+func checkAgainstParent(chain *params.ChainConfig, parent, h *header) []Issue {
+	number, hash := h.Number.Uint64(), h.hash
+	issue := func(check, format string, args ...interface{}) Issue {
+		return Issue{Block: number, Hash: hash, Check: check, Detail: fmt.Sprintf(format, args...)}
+	}
+	var issues []Issue
+	if h.ParentHash != parent.hash {
+		issues = append(issues, issue(CheckParentHash, "parent hash %s, block %d is %s", h.ParentHash.Hex(), parent.Number, parent.hash.Hex()))
+	}
+	if number != parent.Number.Uint64()+1 {
+		issues = append(issues, issue(CheckNumber, "parent is block %d", parent.Number))
+	}
+
+	if chain.IsLondon(h.Number) {
+		expected := eip1559.CalcBaseFee(chain, parent.Header)
+		switch {
+		case h.BaseFee == nil:
+			issues = append(issues, issue(CheckBaseFee, "missing after London, expected %s", expected))
+		case h.BaseFee.Cmp(expected) != 0:
+			issues = append(issues, issue(CheckBaseFee, "%s, expected %s from parent gas used %d of target %d",
+				h.BaseFee, expected, parent.GasUsed, parent.GasLimit/chain.ElasticityMultiplier()))
+		}
+	}
+
+	if chain.IsCancun(h.Number, h.Time) {
+		switch {
+		case h.ExcessBlobGas == nil || h.BlobGasUsed == nil:
+			issues = append(issues, issue(CheckExcessBlobGas, "blob gas fields missing after Cancun"))
+		case !chain.IsCancun(parent.Number, parent.Time):
+			if *h.ExcessBlobGas != 0 {
+				issues = append(issues, issue(CheckExcessBlobGas, "%d in the Cancun fork block, expected 0", *h.ExcessBlobGas))
+			}
+		case parent.ExcessBlobGas == nil || parent.BlobGasUsed == nil:
+			// Reported against the parent itself.
+		default:
+			if expected := eip4844.CalcExcessBlobGas(chain, parent.Header, h.Time); *h.ExcessBlobGas != expected {
+				issues = append(issues, issue(CheckExcessBlobGas, "%d, expected %d from parent excess %d and blob gas used %d",
+					*h.ExcessBlobGas, expected, *parent.ExcessBlobGas, *parent.BlobGasUsed))
+			}
+		}
+	}
+	return issues
+}
+
+func checkConsensus(chain *params.ChainConfig, h *header) []Issue {
+	if !postMerge(chain, h.Header) {
+		return nil
+	}
+	var issues []Issue
+	if h.Difficulty == nil || h.Difficulty.Sign() != 0 {
+		issues = append(issues, Issue{Block: h.Number.Uint64(), Hash: h.hash, Check: CheckDifficulty, Detail: fmt.Sprintf("%v after the merge, expected 0", h.Difficulty)})
+	}
+	if h.Nonce != (types.BlockNonce{}) {
+		issues = append(issues, Issue{Block: h.Number.Uint64(), Hash: h.hash, Check: CheckNonce, Detail: fmt.Sprintf("%#x after the merge, expected 0", h.Nonce.Uint64())})
+	}
+	return issues
+}
+
+// postMerge reports whether h is a proof-of-stake block.
+func postMerge(chain *params.ChainConfig, h *types.Header) bool {
+	switch {
+	case chain.IsShanghai(h.Number, h.Time):
+		return true
+	case chain.TerminalTotalDifficulty != nil && chain.TerminalTotalDifficulty.Sign() == 0:
+		return true
+	case chain.MergeNetsplitBlock != nil:
+		return h.Number.Cmp(chain.MergeNetsplitBlock) >= 0
+	case chain.ChainID.Cmp(params.MainnetChainConfig.ChainID) == 0:
+		return h.Number.Uint64() >= mainnetMergeBlock
+	}
+	return false
+}
@@ -10,6 +10,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/tokenlist"
 )
 
 type preset struct {
@@ -30,9 +32,10 @@ var presets = map[string]preset{
 
 func main() {
 	rpcFlag := flag.String("rpc", "http://localhost:8545", "Ethereum RPC endpoint to inspect")
-	contractFlag := flag.String("contract", "", "address expected to be a contract (defaults depend on --preset)")
+	contractFlag := flag.String("contract", "", "address (or token symbol from --tokenlist) expected to be a contract (defaults depend on --preset)")
 	accountFlag := flag.String("account", "", "address expected to be an EOA (defaults depend on --preset)")
 	presetFlag := flag.String("preset", "mainnet", "address preset to use: mainnet or ganache")
+	tokenListFlag := flag.String("tokenlist", tokenlist.DefaultPath, "comma-separated Uniswap token list files used to resolve token symbols")
 	flag.Parse()
 
 	defaults, ok := presets[*presetFlag]
@@ -48,11 +51,6 @@ func main() {
 		*accountFlag = defaults.account
 	}
 
-	re := regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
-
-	fmt.Printf("is valid (%s): %v\n", *contractFlag, re.MatchString(*contractFlag))
-	fmt.Printf("is valid (%s): %v\n", *accountFlag, re.MatchString(*accountFlag))
-
 	client, err := ethclient.Dial(*rpcFlag)
 	if err != nil {
 		log.Fatalf("dial RPC: %v", err)
@@ -62,6 +60,23 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	re := regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	fmt.Printf("is valid (%s): %v\n", *contractFlag, re.MatchString(*contractFlag))
+	fmt.Printf("is valid (%s): %v\n", *accountFlag, re.MatchString(*accountFlag))
+
+	if !re.MatchString(*contractFlag) {
+		resolver, err := tokenlist.Open(ctx, client, *tokenListFlag)
+		if err != nil {
+			log.Fatalf("open token lists: %v", err)
+		}
+		resolved, err := resolver.Resolve(*contractFlag)
+		if err != nil {
+			log.Fatalf("resolve --contract: %v", err)
+		}
+		fmt.Printf("resolved %s -> %s\n", *contractFlag, resolved.Hex())
+		*contractFlag = resolved.Hex()
+	}
+
 	contractAddr := common.HexToAddress(*contractFlag)
 	isContract, err := hasCode(ctx, client, contractAddr)
 	if err != nil {
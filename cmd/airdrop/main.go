@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/merkle"
+	"github.com/obingo31/go-eth/token"
+	"github.com/obingo31/go-eth/tokenlist"
+	"github.com/obingo31/go-eth/transactor"
+)
+
+func main() {
+	modeFlag := flag.String("mode", "build", "operation to perform: build, verify, deploy or claim")
+	csvFlag := flag.String("csv", "", "CSV of address,amount rows (build mode)")
+	outFlag := flag.String("out", "-", "where to write the distribution JSON (build mode, - for stdout)")
+	proofsFlag := flag.String("proofs", "airdrop.json", "distribution JSON produced by build mode")
+	accountFlag := flag.String("account", "", "recipient to verify or claim for (verify mode checks every claim when empty)")
+	rpcFlag := flag.String("rpc", "http://127.0.0.1:8545", "Ethereum RPC endpoint (deploy and claim modes)")
+	privFlag := flag.String("priv", "4f3edf983ac636a65a842ce7c78d9aa706d3b113bce9c46f30d7d21715b23b1d", "hex private key (Ganache default account[0])")
+	tokenFlag := flag.String("token", "", "ERC-20 token paid out by the distributor: symbol from --tokenlist or address (deploy mode)")
+	tokenListFlag := flag.String("tokenlist", tokenlist.DefaultPath, "comma-separated Uniswap token list files used to resolve --token symbols")
+	fundFlag := flag.Bool("fund", true, "transfer tokenTotal from the deployer to the new distributor (deploy mode)")
+	distributorFlag := flag.String("distributor", "", "deployed MerkleDistributor address (claim mode)")
+	flag.Parse()
+
+	switch *modeFlag {
+	case "build":
+		build(*csvFlag, *outFlag)
+	case "verify":
+		verify(loadDistribution(*proofsFlag), *accountFlag)
+	case "deploy":
+		if *tokenFlag == "" {
+			log.Fatal("--token is required in deploy mode")
+		}
+		deploy(*rpcFlag, *privFlag, *tokenListFlag, *tokenFlag, loadDistribution(*proofsFlag), *fundFlag)
+	case "claim":
+		if !common.IsHexAddress(*distributorFlag) || !common.IsHexAddress(*accountFlag) {
+			log.Fatal("--distributor and --account must be valid hex addresses")
+		}
+		claim(*rpcFlag, *privFlag, common.HexToAddress(*distributorFlag), common.HexToAddress(*accountFlag), loadDistribution(*proofsFlag))
+	default:
+		log.Fatalf("unknown mode %q (expected build, verify, deploy or claim)", *modeFlag)
+	}
+}
+
+func build(csvPath, outPath string) {
+	if csvPath == "" {
+		log.Fatal("--csv is required in build mode")
+	}
+	f, err := os.Open(csvPath)
+	if err != nil {
+		log.Fatalf("open csv: %v", err)
+	}
+	defer f.Close()
+
+	entries, err := merkle.ReadCSV(f)
+	if err != nil {
+		log.Fatalf("read csv: %v", err)
+	}
+	tree, err := merkle.NewTree(entries)
+	if err != nil {
+		log.Fatalf("build tree: %v", err)
+	}
+	dist, err := tree.Distribution()
+	if err != nil {
+		log.Fatalf("build distribution: %v", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if outPath != "-" {
+		file, err := os.Create(outPath)
+		if err != nil {
+			log.Fatalf("create %s: %v", outPath, err)
+		}
+		defer file.Close()
+		out = file
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(dist); err != nil {
+		log.Fatalf("write distribution: %v", err)
+	}
+
+	log.Printf("root %s, %d recipients, total %s", dist.MerkleRoot.Hex(), len(dist.Claims), dist.TokenTotal)
+}
+
+func verify(dist *merkle.Distribution, account string) {
+	accounts := make([]common.Address, 0, len(dist.Claims))
+	if account != "" {
+		if !common.IsHexAddress(account) {
+			log.Fatalf("invalid --account %s", account)
+		}
+		accounts = append(accounts, common.HexToAddress(account))
+	} else {
+		for addr := range dist.Claims {
+			accounts = append(accounts, addr)
+		}
+		sort.Slice(accounts, func(i, j int) bool { return accounts[i].Cmp(accounts[j]) < 0 })
+	}
+
+	failed := 0
+	for _, addr := range accounts {
+		amount, err := dist.VerifyClaim(addr)
+		if err != nil {
+			fmt.Printf("FAIL %s: %v\n", addr.Hex(), err)
+			failed++
+			continue
+		}
+		fmt.Printf("ok   %s %s\n", addr.Hex(), amount)
+	}
+	if failed > 0 {
+		log.Fatalf("%d of %d proofs failed against root %s", failed, len(accounts), dist.MerkleRoot.Hex())
+	}
+	fmt.Printf("%d proofs valid against root %s\n", len(accounts), dist.MerkleRoot.Hex())
+}
+
+func deploy(rpcURL, privHex, tokenLists, tokenRef string, dist *merkle.Distribution, fund bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	client, auth := dial(ctx, rpcURL, privHex)
+	defer client.Close()
+
+	resolver, err := tokenlist.OpenFor(ctx, client, tokenLists, tokenRef)
+	if err != nil {
+		log.Fatalf("open token lists: %v", err)
+	}
+	tokenAddr, err := resolver.Resolve(tokenRef)
+	if err != nil {
+		log.Fatalf("resolve token: %v", err)
+	}
+
+	address, tx, _, err := merkle.DeployDistributor(auth, client, tokenAddr, dist.MerkleRoot)
+	if err != nil {
+		log.Fatalf("deploy distributor: %v", err)
+	}
+	fmt.Printf("Deployer    : %s\n", auth.From.Hex())
+	fmt.Printf("Distributor : %s\n", address.Hex())
+	fmt.Printf("Tx Hash     : %s\n", tx.Hash().Hex())
+	fmt.Printf("Merkle root : %s\n", dist.MerkleRoot.Hex())
+	waitMined(ctx, client, tx)
+
+	if !fund {
+		return
+	}
+	total, ok := new(big.Int).SetString(dist.TokenTotal, 10)
+	if !ok {
+		log.Fatalf("invalid tokenTotal %q", dist.TokenTotal)
+	}
+	instance, err := token.NewToken(tokenAddr, client)
+	if err != nil {
+		log.Fatalf("instantiate token binding: %v", err)
+	}
+	transactor.Next(auth)
+	fundTx, err := instance.Transfer(auth, address, total)
+	if err != nil {
+		log.Fatalf("fund distributor: %v", err)
+	}
+	fmt.Printf("Funding tx  : %s (%s tokens)\n", fundTx.Hash().Hex(), total)
+	waitMined(ctx, client, fundTx)
+}
+
+func claim(rpcURL, privHex string, distributorAddr, account common.Address, dist *merkle.Distribution) {
+	amount, err := dist.VerifyClaim(account)
+	if err != nil {
+		log.Fatalf("verify claim: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	client, auth := dial(ctx, rpcURL, privHex)
+	defer client.Close()
+
+	distributor, err := merkle.NewDistributor(distributorAddr, client)
+	if err != nil {
+		log.Fatalf("bind distributor: %v", err)
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+	root, err := distributor.MerkleRoot(callOpts)
+	if err != nil {
+		log.Fatalf("read merkle root: %v", err)
+	}
+	if common.Hash(root) != dist.MerkleRoot {
+		log.Fatalf("distributor root %s does not match %s", common.Hash(root).Hex(), dist.MerkleRoot.Hex())
+	}
+	claimed, err := distributor.IsClaimed(callOpts, account)
+	if err != nil {
+		log.Fatalf("read claim status: %v", err)
+	}
+	if claimed {
+		log.Fatalf("%s has already claimed", account.Hex())
+	}
+
+	proof := make([][32]byte, len(dist.Claims[account].Proof))
+	for i, p := range dist.Claims[account].Proof {
+		proof[i] = p
+	}
+	tx, err := distributor.Claim(auth, account, amount, proof)
+	if err != nil {
+		log.Fatalf("claim: %v", err)
+	}
+	fmt.Printf("claim tx sent: %s (%s tokens to %s)\n", tx.Hash().Hex(), amount, account.Hex())
+	waitMined(ctx, client, tx)
+}
+
+func dial(ctx context.Context, rpcURL, privHex string) (*ethclient.Client, *bind.TransactOpts) {
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	privateKey, err := transactor.ParseKey(privHex)
+	if err != nil {
+		log.Fatalf("parse private key: %v", err)
+	}
+	auth, err := transactor.New(ctx, client, privateKey, 0)
+	if err != nil {
+		log.Fatalf("prepare transactor: %v", err)
+	}
+	return client, auth
+}
+
+func waitMined(ctx context.Context, client *ethclient.Client, tx *types.Transaction) {
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		log.Fatalf("wait for %s: %v", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		log.Fatalf("tx %s reverted in block %d", tx.Hash().Hex(), receipt.BlockNumber)
+	}
+}
+
+func loadDistribution(path string) *merkle.Distribution {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("open distribution: %v", err)
+	}
+	defer f.Close()
+
+	dist, err := merkle.ReadDistribution(f)
+	if err != nil {
+		log.Fatalf("read distribution %s: %v", path, err)
+	}
+	return dist
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/blockstats"
+	"github.com/obingo31/go-eth/blocktime"
+)
+
+func main() {
+	rpcFlag := flag.String("rpc", "http://localhost:8545", "Ethereum RPC endpoint")
+	fromFlag := flag.Int64("from", -1, "first block (-1 for --last blocks before --to)")
+	toFlag := flag.Int64("to", -1, "last block (-1 for latest)")
+	fromAtFlag := flag.String("from-at", "", "start at the first block at or after this time instead of --from (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	toAtFlag := flag.String("to-at", "", "end at the last block at or before this time instead of --to")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	lastFlag := flag.Uint64("last", 100, "number of blocks when --from is not set")
+	concurrencyFlag := flag.Int("concurrency", 8, "blocks fetched in parallel")
+	topFlag := flag.Int("top", 10, "senders and recipients listed")
+	formatFlag := flag.String("format", "table", "output format: table or csv")
+	seriesFlag := flag.Bool("series", false, "print one row per block instead of only the summary")
+	flag.Parse()
+
+	if *formatFlag != "table" && *formatFlag != "csv" {
+		log.Fatalf("unknown format %q (want table or csv)", *formatFlag)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := ethclient.DialContext(ctx, *rpcFlag)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	if err := blocktime.SetRange(ctx, client, *blockTimeCacheFlag, *fromAtFlag, *toAtFlag, fromFlag, toFlag); err != nil {
+		log.Fatalf("resolve --from-at/--to-at: %v", err)
+	}
+
+	to := uint64(*toFlag)
+	if *toFlag < 0 {
+		if to, err = client.BlockNumber(ctx); err != nil {
+			log.Fatalf("fetch block number: %v", err)
+		}
+	}
+	from := uint64(*fromFlag)
+	if *fromFlag < 0 {
+		from = 0
+		if to+1 > *lastFlag {
+			from = to + 1 - *lastFlag
+		}
+	}
+
+	report, err := blockstats.Collect(ctx, client, from, to, blockstats.Config{
+		Concurrency: *concurrencyFlag,
+		Top:         *topFlag,
+	})
+	if err != nil {
+		log.Fatalf("collect blocks %d-%d: %v", from, to, err)
+	}
+
+	switch {
+	case *formatFlag == "csv" && *seriesFlag:
+		writeSeriesCSV(report.Blocks)
+	case *formatFlag == "csv":
+		writeSummaryCSV(&report.Summary)
+	default:
+		printSummary(&report.Summary)
+		if *seriesFlag {
+			fmt.Println()
+			printSeries(report.Blocks)
+		}
+	}
+}
+
+func printSummary(s *blockstats.Summary) {
+	fmt.Printf("Blocks %d-%d: %d blocks, %d empty, %d txs\n\n", s.From, s.To, s.Blocks, s.Empty, s.Txs)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Gas used\t%d (%.1f%% of target)\n", s.GasUsed, 100*s.GasRatio())
+	fmt.Fprintf(w, "Blocks above / below target\t%d / %d\n", s.AboveTarget, s.BelowTarget)
+	fmt.Fprintf(w, "Base fee first / last\t%s / %s wei\n", wei(s.BaseFeeFirst), wei(s.BaseFeeLast))
+	fmt.Fprintf(w, "Base fee min / max\t%s / %s wei\n", wei(s.BaseFeeMin), wei(s.BaseFeeMax))
+	fmt.Fprintf(w, "Largest rise / drop\t%s / %s wei\n", wei(s.MaxIncrease), wei(s.MaxDecrease))
+	fmt.Fprintf(w, "Blob gas used\t%d (%d blobs)\n", s.BlobGasUsed, s.Blobs)
+	fmt.Fprintf(w, "Tx types\t%s\n", typeMix(s.Types))
+	fmt.Fprintf(w, "Contract creations\t%d\n", s.Creations)
+	fmt.Fprintf(w, "Distinct senders / recipients\t%d / %d\n", s.Senders, s.Recipients)
+	w.Flush()
+
+	printCounts("Top senders", s.TopSenders)
+	printCounts("Top recipients", s.TopRecipients)
+}
+
+func printCounts(title string, counts []blockstats.Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", title)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, c := range counts {
+		fmt.Fprintf(w, "  %s\t%d\n", c.Address.Hex(), c.Txs)
+	}
+	w.Flush()
+}
+
+func printSeries(blocks []*blockstats.Block) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "BLOCK\tTXS\tGAS USED\tTARGET %\tBASE FEE\tDELTA\tBLOB GAS\tBLOBS\tCREATIONS\tTYPES\t")
+	for _, b := range blocks {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%.1f\t%s\t%s\t%d\t%d\t%d\t%s\t\n",
+			b.Number, b.Txs, b.GasUsed, 100*b.GasRatio(), wei(b.BaseFee), delta(b.BaseFeeDelta),
+			b.BlobGasUsed, b.Blobs, b.Creations, typeMix(b.Types))
+	}
+	w.Flush()
+}
+
+// seriesTypes are the transaction types given their own CSV column.
+var seriesTypes = []uint8{0, 1, 2, 3, 4}
+
+func writeSeriesCSV(blocks []*blockstats.Block) {
+	w := csv.NewWriter(os.Stdout)
+	header := []string{"block", "hash", "time", "txs", "gas_used", "gas_limit", "gas_target", "gas_ratio",
+		"base_fee", "base_fee_delta", "blob_gas_used", "excess_blob_gas", "blobs", "creations", "empty"}
+	for _, t := range seriesTypes {
+		header = append(header, "txs_"+strings.ReplaceAll(blockstats.TypeName(t), "-", "_"))
+	}
+	w.Write(header)
+	for _, b := range blocks {
+		row := []string{
+			strconv.FormatUint(b.Number, 10),
+			b.Hash.Hex(),
+			strconv.FormatUint(b.Time, 10),
+			strconv.Itoa(b.Txs),
+			strconv.FormatUint(b.GasUsed, 10),
+			strconv.FormatUint(b.GasLimit, 10),
+			strconv.FormatUint(b.GasTarget, 10),
+			strconv.FormatFloat(b.GasRatio(), 'f', 4, 64),
+			optional(b.BaseFee),
+			optional(b.BaseFeeDelta),
+			strconv.FormatUint(b.BlobGasUsed, 10),
+			strconv.FormatUint(b.ExcessBlobGas, 10),
+			strconv.Itoa(b.Blobs),
+			strconv.Itoa(b.Creations),
+			strconv.FormatBool(b.Empty()),
+		}
+		for _, t := range seriesTypes {
+			row = append(row, strconv.Itoa(b.Types[t]))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatalf("write CSV: %v", err)
+	}
+}
+
+func writeSummaryCSV(s *blockstats.Summary) {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"metric", "value"})
+	row := func(metric string, value interface{}) {
+		w.Write([]string{metric, fmt.Sprint(value)})
+	}
+	row("from", s.From)
+	row("to", s.To)
+	row("blocks", s.Blocks)
+	row("empty_blocks", s.Empty)
+	row("txs", s.Txs)
+	row("gas_used", s.GasUsed)
+	row("gas_target", s.GasTarget)
+	row("gas_ratio", strconv.FormatFloat(s.GasRatio(), 'f', 4, 64))
+	row("blocks_above_target", s.AboveTarget)
+	row("blocks_below_target", s.BelowTarget)
+	row("base_fee_first", optional(s.BaseFeeFirst))
+	row("base_fee_last", optional(s.BaseFeeLast))
+	row("base_fee_min", optional(s.BaseFeeMin))
+	row("base_fee_max", optional(s.BaseFeeMax))
+	row("base_fee_max_increase", optional(s.MaxIncrease))
+	row("base_fee_max_decrease", optional(s.MaxDecrease))
+	row("blob_gas_used", s.BlobGasUsed)
+	row("blobs", s.Blobs)
+	for _, t := range sortedTypes(s.Types) {
+		row("txs_"+strings.ReplaceAll(blockstats.TypeName(t), "-", "_"), s.Types[t])
+	}
+	row("creations", s.Creations)
+	row("distinct_senders", s.Senders)
+	row("distinct_recipients", s.Recipients)
+	for i, c := range s.TopSenders {
+		row(fmt.Sprintf("top_sender_%d", i+1), fmt.Sprintf("%s:%d", c.Address.Hex(), c.Txs))
+	}
+	for i, c := range s.TopRecipients {
+		row(fmt.Sprintf("top_recipient_%d", i+1), fmt.Sprintf("%s:%d", c.Address.Hex(), c.Txs))
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatalf("write CSV: %v", err)
+	}
+}
+
+func sortedTypes(m map[uint8]int) []uint8 {
+	out := make([]uint8, 0, len(m))
+	for t := range m {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+func typeMix(m map[uint8]int) string {
+	if len(m) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(m))
+	for _, t := range sortedTypes(m) {
+		parts = append(parts, fmt.Sprintf("%s=%d", blockstats.TypeName(t), m[t]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func wei(v *big.Int) string {
+	if v == nil {
+		return "-"
+	}
+	return v.String()
+}
+
+func delta(v *big.Int) string {
+	if v == nil {
+		return "-"
+	}
+	if v.Sign() > 0 {
+		return "+" + v.String()
+	}
+	return v.String()
+}
+
+func optional(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
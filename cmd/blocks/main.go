@@ -6,14 +6,25 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"net/http"
+	"os"
 	"time"
 
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/blobs"
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/chaincheck"
 )
 
 func main() {
 	rpcFlag := flag.String("rpc", "http://localhost:8545", "Ethereum RPC endpoint")
 	blockFlag := flag.Int64("block", 5671744, "block number to inspect (-1 for latest)")
+	atFlag := flag.String("at", "", "inspect the last block at or before this time instead of --block (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	sidecarFlag := flag.String("sidecar", "", "blob sidecar file (blobsBundle or beacon blob_sidecars JSON) to verify against the block's versioned hashes")
+	beaconFlag := flag.String("beacon", "", "beacon node URL to fetch the block's blob sidecars from and verify them")
 	flag.Parse()
 
 	client, err := ethclient.Dial(*rpcFlag)
@@ -22,7 +33,7 @@ func main() {
 	}
 	defer client.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	header, err := client.HeaderByNumber(ctx, nil)
@@ -31,6 +42,10 @@ func main() {
 	}
 	fmt.Printf("Latest block: %s\n", header.Number.String())
 
+	if err := blocktime.SetBlock(ctx, client, *blockTimeCacheFlag, *atFlag, blockFlag); err != nil {
+		log.Fatalf("resolve --at: %v", err)
+	}
+
 	var blockNumber *big.Int
 	if *blockFlag >= 0 {
 		blockNumber = big.NewInt(*blockFlag)
@@ -52,4 +67,72 @@ func main() {
 		log.Fatalf("transaction count: %v", err)
 	}
 	fmt.Printf("TransactionCount API: %d\n", count)
+
+	printWithdrawals(block)
+	printBlobs(ctx, client, block, *sidecarFlag, *beaconFlag)
+}
+
+// printWithdrawals lists the beacon chain withdrawals credited by the block.
+func printWithdrawals(block *types.Block) {
+	if block.Withdrawals() == nil {
+		return
+	}
+	total := new(big.Int)
+	for _, w := range block.Withdrawals() {
+		total.Add(total, new(big.Int).SetUint64(w.Amount))
+	}
+	fmt.Printf("Withdrawals: %d (%s gwei)\n", len(block.Withdrawals()), total)
+	for _, w := range block.Withdrawals() {
+		fmt.Printf("  #%d validator %d -> %s: %d gwei\n", w.Index, w.Validator, w.Address.Hex(), w.Amount)
+	}
+}
+
+// printBlobs shows the block's blob gas and blob transactions, and verifies
+// a sidecar from a file or a beacon node when one is given.
+func printBlobs(ctx context.Context, client *ethclient.Client, block *types.Block, sidecarPath, beaconURL string) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatalf("fetch chain ID: %v", err)
+	}
+	cfg, err := chaincheck.ChainConfig(chainID)
+	if err != nil {
+		log.Printf("warn: %v; the blob base fee is not computed", err)
+	}
+	info, ok := blobs.HeaderInfo(cfg, block.Header())
+	if !ok {
+		fmt.Println("Blobs: block predates EIP-4844")
+		return
+	}
+	fmt.Println("Blobs")
+	fmt.Printf("  Blob gas used  : %d (%d blobs)\n", info.BlobGasUsed, info.Blobs)
+	fmt.Printf("  Excess blob gas: %d\n", info.ExcessBlobGas)
+	if info.BlobBaseFee != nil {
+		fmt.Printf("  Blob base fee  : %s wei\n", info.BlobBaseFee)
+	}
+	txs := blobs.Transactions(block)
+	for _, tx := range txs {
+		fmt.Printf("  Tx %d %s (max blob fee %s wei)\n", tx.Index, tx.Hash.Hex(), tx.BlobGasFeeCap)
+		for _, h := range tx.BlobHashes {
+			fmt.Printf("    %s\n", h.Hex())
+		}
+	}
+
+	var sidecar []blobs.Blob
+	switch {
+	case sidecarPath != "":
+		if sidecar, err = blobs.Load(sidecarPath); err != nil {
+			log.Fatalf("load sidecar: %v", err)
+		}
+	case beaconURL != "":
+		if sidecar, err = blobs.FetchBlock(ctx, client, http.DefaultClient, beaconURL, block.NumberU64()); err != nil {
+			log.Fatalf("fetch sidecars: %v", err)
+		}
+	default:
+		return
+	}
+	report := blobs.Check(sidecar, blobs.Hashes(txs), false)
+	report.Print(os.Stdout)
+	if err := report.Err(); err != nil {
+		log.Fatalf("sidecar does not match block %d: %v", block.NumberU64(), err)
+	}
 }
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/chaincheck"
+)
+
+func main() {
+	rpcFlag := flag.String("rpc", "http://localhost:8545", "Ethereum RPC endpoint to verify")
+	fromFlag := flag.Int64("from", -1, "first block (-1 for --last blocks before --to)")
+	toFlag := flag.Int64("to", -1, "last block (-1 for latest)")
+	fromAtFlag := flag.String("from-at", "", "start at the first block at or after this time instead of --from (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	toAtFlag := flag.String("to-at", "", "end at the last block at or before this time instead of --to")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	lastFlag := flag.Uint64("last", 1000, "number of blocks when --from is not set")
+	chainFlag := flag.String("chain", "", "chain config: mainnet, sepolia, holesky, hoodi or dev (default: chosen by chain ID)")
+	batchFlag := flag.Int("batch", 100, "headers per batched request")
+	jsonFlag := flag.Bool("json", false, "print the result as JSON")
+	flag.Parse()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := ethclient.DialContext(ctx, *rpcFlag)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	var chain *params.ChainConfig
+	if *chainFlag != "" {
+		var ok bool
+		if chain, ok = chaincheck.Chains[*chainFlag]; !ok {
+			log.Fatalf("unknown chain %q", *chainFlag)
+		}
+	} else {
+		chainID, err := client.ChainID(ctx)
+		if err != nil {
+			log.Fatalf("fetch chain ID: %v", err)
+		}
+		if chain, err = chaincheck.ChainConfig(chainID); err != nil {
+			log.Fatalf("%v (pass --chain)", err)
+		}
+	}
+
+	if err := blocktime.SetRange(ctx, client, *blockTimeCacheFlag, *fromAtFlag, *toAtFlag, fromFlag, toFlag); err != nil {
+		log.Fatalf("resolve --from-at/--to-at: %v", err)
+	}
+
+	to := uint64(*toFlag)
+	if *toFlag < 0 {
+		if to, err = client.BlockNumber(ctx); err != nil {
+			log.Fatalf("fetch block number: %v", err)
+		}
+	}
+	from := uint64(*fromFlag)
+	if *fromFlag < 0 {
+		from = 0
+		if to+1 > *lastFlag {
+			from = to + 1 - *lastFlag
+		}
+	}
+
+	var print func(chaincheck.Issue)
+	if !*jsonFlag {
+		print = func(i chaincheck.Issue) { fmt.Println(i) }
+	}
+	res, err := chaincheck.Verify(ctx, client.Client(), from, to, chaincheck.Config{Chain: chain, Batch: *batchFlag}, print)
+	if err != nil {
+		log.Fatalf("verify blocks %d-%d: %v", from, to, err)
+	}
+
+	if *jsonFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(res); err != nil {
+			log.Fatalf("encode JSON: %v", err)
+		}
+	}
+	log.Printf("checked %d headers (%d-%d) on chain %s: %d issues", res.Checked, from, to, chain.ChainID, len(res.Issues))
+	if !res.OK() {
+		os.Exit(1)
+	}
+}
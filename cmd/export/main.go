@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/export"
+)
+
+func main() {
+	rpcFlag := flag.String("rpc", "http://localhost:8545", "Ethereum RPC endpoint")
+	fromFlag := flag.Uint64("from", 0, "first block")
+	toFlag := flag.Int64("to", -1, "last block (-1 for latest minus --confirmations)")
+	fromAtFlag := flag.String("from-at", "", "start at the first block at or after this time instead of --from (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	toAtFlag := flag.String("to-at", "", "end at the last block at or before this time instead of --to")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	confirmationsFlag := flag.Uint64("confirmations", 0, "blocks left unexported behind the head when --to is -1")
+	entitiesFlag := flag.String("entities", strings.Join(export.Entities, ","), "comma-separated entities: "+strings.Join(export.Entities, ", "))
+	formatFlag := flag.String("format", export.FormatCSV, "file format: csv, ndjson or parquet")
+	outFlag := flag.String("out", "export", "output directory (one subdirectory per entity)")
+	partitionFlag := flag.Uint64("partition", 1000, "blocks per file")
+	concurrencyFlag := flag.Int("concurrency", 8, "blocks fetched in parallel")
+	checkpointFlag := flag.String("checkpoint", "", "checkpoint file (default <out>/checkpoint.json)")
+	schemaFlag := flag.Bool("schema", false, "print the Parquet schema of each entity and exit")
+	flag.Parse()
+
+	entities := splitList(*entitiesFlag)
+	if *schemaFlag {
+		for _, e := range entities {
+			schema, err := export.Schema(e)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("%s: %s\n\n", e, schema)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := ethclient.DialContext(ctx, *rpcFlag)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	if err := blocktime.SetRange(ctx, client, *blockTimeCacheFlag, *fromAtFlag, *toAtFlag, fromFlag, toFlag); err != nil {
+		log.Fatalf("resolve --from-at/--to-at: %v", err)
+	}
+
+	to := uint64(*toFlag)
+	if *toFlag < 0 {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			log.Fatalf("fetch block number: %v", err)
+		}
+		if head < *confirmationsFlag {
+			log.Fatalf("head %d has fewer than %d confirmations", head, *confirmationsFlag)
+		}
+		to = head - *confirmationsFlag
+	}
+
+	totals := make(map[string]int)
+	err = export.Run(ctx, client, *fromFlag, to, export.Config{
+		Dir:         *outFlag,
+		Entities:    entities,
+		Format:      *formatFlag,
+		Partition:   *partitionFlag,
+		Concurrency: *concurrencyFlag,
+		Checkpoint:  *checkpointFlag,
+		Progress: func(p export.Partition) {
+			for e, n := range p.Rows {
+				totals[e] += n
+			}
+			log.Printf("blocks %d-%d: %s", p.From, p.To, rowCounts(p.Rows))
+		},
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			log.Fatalf("interrupted: %v; completed partitions are kept, rerun to resume", err)
+		}
+		log.Fatalf("export: %v", err)
+	}
+	log.Printf("exported up to block %d to %s: %s", to, *outFlag, rowCounts(totals))
+}
+
+func rowCounts(rows map[string]int) string {
+	entities := make([]string, 0, len(rows))
+	for e := range rows {
+		entities = append(entities, e)
+	}
+	sort.Strings(entities)
+	parts := make([]string, len(entities))
+	for i, e := range entities {
+		parts[i] = fmt.Sprintf("%d %s", rows[e], e)
+	}
+	if len(parts) == 0 {
+		return "nothing new"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/obingo31/go-eth/fees"
+	"github.com/obingo31/go-eth/subscription"
+)
+
+func main() {
+	rpcFlag := flag.String("rpc", "http://127.0.0.1:8545", "Ethereum RPC endpoint (WebSocket recommended with --watch)")
+	blocksFlag := flag.Uint64("blocks", 20, "blocks of fee history to analyse")
+	percentilesFlag := flag.String("percentiles", "10,25,50,75,90", "comma-separated priority fee percentiles")
+	aheadFlag := flag.Int("ahead", 6, "blocks the suggested fee cap must cover if every one of them is full")
+	percentileFlag := flag.Float64("percentile", 50, "percentile the suggested tip is taken from (one of --percentiles)")
+	seriesFlag := flag.Bool("series", false, "print one row per block")
+	jsonFlag := flag.Bool("json", false, "print the report as JSON (NDJSON with --watch)")
+	watchFlag := flag.Bool("watch", false, "refresh the report on every new head")
+	modeFlag := flag.String("mode", "auto", "subscription mechanism with --watch: auto, ws, filter or poll")
+	pollFlag := flag.Duration("poll", 2*time.Second, "polling interval when the endpoint cannot push new heads")
+	flag.Parse()
+
+	percentiles, err := parsePercentiles(*percentilesFlag)
+	if err != nil {
+		log.Fatalf("parse --percentiles: %v", err)
+	}
+	if !slices.Contains(percentiles, *percentileFlag) {
+		log.Fatalf("--percentile %g is not one of --percentiles", *percentileFlag)
+	}
+	mode, err := subscription.ParseMode(*modeFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := subscription.Dial(ctx, *rpcFlag, subscription.Options{Mode: mode, Interval: *pollFlag})
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	// report prints the window ending at head (nil for latest). Errors are
+	// returned so --watch can skip a head instead of exiting.
+	report := func(head *big.Int) error {
+		history, err := fees.Fetch(ctx, client, *blocksFlag, head, percentiles)
+		if err != nil {
+			return fmt.Errorf("fetch fee history: %w", err)
+		}
+		// Empty windows have no tips to learn from; fall back to what the
+		// node would suggest.
+		var fallback *big.Int
+		if history.Tips() == nil {
+			if fallback, err = client.SuggestGasTipCap(ctx); err != nil {
+				return fmt.Errorf("suggest tip: %w", err)
+			}
+		}
+		suggestion, err := history.Suggest(*aheadFlag, *percentileFlag, fallback)
+		if err != nil {
+			return fmt.Errorf("suggest fees: %w", err)
+		}
+		if *jsonFlag {
+			printJSON(history, suggestion, *seriesFlag)
+			return nil
+		}
+		printReport(history, suggestion, *seriesFlag)
+		return nil
+	}
+
+	if !*watchFlag {
+		if err := report(nil); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	headers := make(chan *types.Header)
+	sub, err := client.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		log.Fatalf("subscribe new heads: %v", err)
+	}
+	defer sub.Unsubscribe()
+	log.Printf("refreshing fees on every head via %s", client.Mode())
+	if err := report(nil); err != nil {
+		log.Printf("warn: %v; waiting for the next head", err)
+	}
+	for {
+		select {
+		case err := <-sub.Err():
+			log.Fatalf("subscription error: %v", err)
+		case <-ctx.Done():
+			return
+		case header := <-headers:
+			if !*jsonFlag {
+				fmt.Println()
+			}
+			if err := report(header.Number); err != nil {
+				log.Printf("warn: block %d: %v; waiting for the next head", header.Number, err)
+			}
+		}
+	}
+}
+
+func printReport(h *fees.History, s fees.Suggestion, series bool) {
+	first, last := h.Blocks[0].Number, h.Blocks[len(h.Blocks)-1].Number
+	t := h.Trend()
+	fmt.Printf("Blocks %d-%d (%d blocks)\n\n", first, last, len(h.Blocks))
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Base fee first / last\t%s / %s (%+.1f%%)\n", gwei(t.First), gwei(t.Last), 100*t.Change)
+	fmt.Fprintf(w, "Base fee min / mean / max\t%s / %s / %s\n", gwei(t.Min), gwei(t.Mean), gwei(t.Max))
+	fmt.Fprintf(w, "Blocks rising / falling\t%d / %d\n", t.Rising, t.Falling)
+	fmt.Fprintf(w, "Mean gas used ratio\t%.1f%%\n", 100*t.MeanGasUsedRatio)
+	fmt.Fprintf(w, "Next base fee\t%s\n", gwei(h.NextBaseFee))
+	fmt.Fprintf(w, "Max base fee in %d blocks\t%s\n", s.Blocks, gwei(fees.MaxBaseFee(h.NextBaseFee, s.Blocks)))
+	w.Flush()
+
+	if tips := h.Tips(); tips != nil {
+		fmt.Println("\nPriority fees (across blocks with transactions):")
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(w, "PERCENTILE\tMIN\tMEDIAN\tMAX\t")
+		for _, tip := range tips {
+			fmt.Fprintf(w, "%g\t%s\t%s\t%s\t\n", tip.Percentile, gwei(tip.Min), gwei(tip.Median), gwei(tip.Max))
+		}
+		w.Flush()
+	} else {
+		fmt.Println("\nNo transactions in the window; the tip comes from eth_maxPriorityFeePerGas.")
+	}
+
+	if series {
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprint(w, "BLOCK\tBASE FEE\tGAS USED %\t")
+		for _, p := range h.Percentiles {
+			fmt.Fprintf(w, "P%g\t", p)
+		}
+		fmt.Fprintln(w)
+		for _, b := range h.Blocks {
+			fmt.Fprintf(w, "%d\t%s\t%.1f\t", b.Number, gwei(b.BaseFee), 100*b.GasUsedRatio)
+			for _, r := range b.Rewards {
+				fmt.Fprintf(w, "%s\t", gwei(r))
+			}
+			fmt.Fprintln(w)
+		}
+		w.Flush()
+	}
+
+	fmt.Printf("\nSuggested (tip at P%g, fee cap valid for %d full blocks):\n", s.Percentile, s.Blocks)
+	fmt.Printf("  maxPriorityFeePerGas: %s\n", weiAndGwei(s.GasTipCap))
+	fmt.Printf("  maxFeePerGas        : %s\n", weiAndGwei(s.GasFeeCap))
+}
+
+func printJSON(h *fees.History, s fees.Suggestion, series bool) {
+	out := struct {
+		From        uint64          `json:"from"`
+		To          uint64          `json:"to"`
+		Trend       fees.Trend      `json:"trend"`
+		Tips        []fees.Tip      `json:"tips"`
+		NextBaseFee *big.Int        `json:"nextBaseFee"`
+		MaxBaseFee  *big.Int        `json:"maxBaseFee"`
+		Suggestion  fees.Suggestion `json:"suggestion"`
+		Blocks      []fees.Block    `json:"blocks,omitempty"`
+	}{
+		From:        h.Blocks[0].Number,
+		To:          h.Blocks[len(h.Blocks)-1].Number,
+		Trend:       h.Trend(),
+		Tips:        h.Tips(),
+		NextBaseFee: h.NextBaseFee,
+		MaxBaseFee:  fees.MaxBaseFee(h.NextBaseFee, s.Blocks),
+		Suggestion:  s,
+	}
+	if series {
+		out.Blocks = h.Blocks
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		log.Fatalf("encode report: %v", err)
+	}
+}
+
+func parsePercentiles(s string) ([]float64, error) {
+	var out []float64
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		p, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return nil, err
+		}
+		if p < 0 || p > 100 || (len(out) > 0 && p <= out[len(out)-1]) {
+			return nil, fmt.Errorf("percentiles must increase within 0-100, got %s", s)
+		}
+		out = append(out, p)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no percentiles given")
+	}
+	return out, nil
+}
+
+// gwei formats wei as gwei, or as wei below a microgwei (as on
+// quiet dev chains).
+func gwei(v *big.Int) string {
+	if v == nil {
+		return "-"
+	}
+	if v.Cmp(big.NewInt(1000)) < 0 {
+		return v.String() + " wei"
+	}
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(v), big.NewFloat(1e9)).Float64()
+	return strconv.FormatFloat(f, 'f', -1, 64) + " gwei"
+}
+
+// weiAndGwei prints the exact wei amount to copy into a transaction, with
+// its gwei value when gwei would print it.
+func weiAndGwei(v *big.Int) string {
+	if g := gwei(v); strings.HasSuffix(g, " gwei") {
+		return fmt.Sprintf("%s wei (%s)", v, g)
+	}
+	return v.String() + " wei"
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/indexer"
+	"github.com/obingo31/go-eth/logdecode"
+	"github.com/obingo31/go-eth/logscan"
+	"github.com/obingo31/go-eth/logstream"
+	"github.com/obingo31/go-eth/tokenlist"
+)
+
+// reorgWindow is how far behind the head backfilling stops; the rest is
+// left to the reorg-aware stream.
+const reorgWindow = 64
+
+func main() {
+	rpcFlag := flag.String("rpc", "ws://127.0.0.1:8546", "Ethereum RPC endpoint (WebSocket for head notifications, HTTP falls back to polling)")
+	dbFlag := flag.String("db", indexer.DefaultPath, "index database directory")
+	contractsFlag := flag.String("contracts", "DEMO", "comma-separated contract addresses or token symbols to index")
+	tokenListFlag := flag.String("tokenlist", tokenlist.DefaultPath, "comma-separated Uniswap token list files used to resolve --contracts symbols")
+	eventsFlag := flag.String("events", "Transfer,Approval,ItemSet", "comma-separated event names to index (empty for every decodable event)")
+	abiFlag := flag.String("abi", logdecode.DefaultPatterns, "comma-separated ABI files, globs or directories used to decode events")
+	fromFlag := flag.Uint64("from", 0, "first block to index when the database is new")
+	fromAtFlag := flag.String("from-at", "", "start at the first block at or after this time instead of --from (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	confirmationsFlag := flag.Uint64("confirmations", 0, "blocks to wait before indexing a block (reorgs are rolled back either way)")
+	httpFlag := flag.String("http", "127.0.0.1:8600", "address serving /events and /status for the query command (empty to disable)")
+	flag.Parse()
+
+	decoder, err := logdecode.NewDefault(*abiFlag)
+	if err != nil {
+		log.Fatalf("load ABIs: %v", err)
+	}
+	events := make(map[string]bool)
+	for _, name := range strings.Split(*eventsFlag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			events[name] = true
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := ethclient.DialContext(ctx, *rpcFlag)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	if err := blocktime.SetFrom(ctx, client, *blockTimeCacheFlag, *fromAtFlag, fromFlag); err != nil {
+		log.Fatalf("resolve --from-at: %v", err)
+	}
+
+	resolver, err := tokenlist.OpenFor(ctx, client, *tokenListFlag, strings.Split(*contractsFlag, ",")...)
+	if err != nil {
+		log.Fatalf("open token lists: %v", err)
+	}
+	var query ethereum.FilterQuery
+	for _, ref := range strings.Split(*contractsFlag, ",") {
+		if ref = strings.TrimSpace(ref); ref == "" {
+			continue
+		}
+		addr, err := resolver.Resolve(ref)
+		if err != nil {
+			log.Fatalf("resolve --contracts: %v", err)
+		}
+		query.Addresses = append(query.Addresses, addr)
+	}
+	if len(query.Addresses) == 0 {
+		log.Fatal("--contracts is required")
+	}
+
+	ix, err := indexer.Open(*dbFlag, false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer ix.Close()
+
+	decode := func(logs []types.Log) []logdecode.Record {
+		var recs []logdecode.Record
+		for _, l := range logs {
+			rec := decoder.Decode(l)
+			if rec.Decoded() && (len(events) == 0 || events[rec.Event]) {
+				recs = append(recs, rec)
+			}
+		}
+		return recs
+	}
+
+	if *httpFlag != "" {
+		server := &http.Server{Addr: *httpFlag, Handler: ix.Handler()}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Fatalf("serve index: %v", err)
+			}
+		}()
+		defer server.Close()
+		log.Printf("serving queries on http://%s/events", *httpFlag)
+	}
+
+	if err := backfill(ctx, client, ix, query, *fromFlag, *confirmationsFlag, decode); err != nil {
+		log.Fatalf("backfill: %v", err)
+	}
+
+	stream, err := logstream.New(client, query, logstream.Config{
+		Confirmations: *confirmationsFlag,
+		From:          int64(*fromFlag),
+		Cursor:        ix,
+		OnError:       func(err error) { log.Printf("warn: %v", err) },
+	})
+	if err != nil {
+		log.Fatalf("open stream: %v", err)
+	}
+	log.Printf("following head for %d contracts", len(query.Addresses))
+	err = stream.Run(ctx, func(b logstream.Block) error {
+		recs := decode(b.Logs)
+		n, err := ix.Apply(b, recs)
+		if err != nil {
+			return err
+		}
+		if b.Removed && n > 0 {
+			log.Printf("reorg: removed %d events of block %d (%s)", n, b.Number, b.Hash.Hex())
+		} else if n > 0 {
+			log.Printf("block %d: indexed %d events", b.Number, n)
+		}
+		return nil
+	})
+	stats := stream.Stats()
+	log.Printf("stopped at block %d: %d reorgs, %d blocks retracted", stats.Next, stats.Reorgs, stats.Retracted)
+	if err != nil && ctx.Err() == nil {
+		log.Fatalf("index: %v", err)
+	}
+}
+
+// backfill indexes history with chunked eth_getLogs up to reorgWindow blocks
+// behind the head, which is much faster than following it block by block.
+func backfill(ctx context.Context, client *ethclient.Client, ix *indexer.Index, query ethereum.FilterQuery, from, confirmations uint64, decode func([]types.Log) []logdecode.Record) error {
+	cursor, err := ix.Load()
+	if err != nil {
+		return err
+	}
+	if cursor != nil {
+		from = cursor.Next
+	}
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	lag := max(confirmations, reorgWindow)
+	if head < lag || from > head-lag {
+		return nil
+	}
+	safe := head - lag
+
+	log.Printf("backfilling blocks %d-%d", from, safe)
+	total := 0
+	scanner := logscan.New(client, logscan.DefaultConfig())
+	err = scanner.Scan(ctx, query, from, safe, func(_, to uint64, logs []types.Log) error {
+		recs := decode(logs)
+		if _, err := ix.Apply(logstream.Block{}, recs); err != nil {
+			return err
+		}
+		total += len(recs)
+		// Blocks this far back are final, so no reorg window is kept.
+		return ix.Save(logstream.Cursor{Next: to + 1})
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("backfilled %d events up to block %d", total, safe)
+	return nil
+}
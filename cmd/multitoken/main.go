@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/token"
+	"github.com/obingo31/go-eth/transactor"
+)
+
+func main() {
+	modeFlag := flag.String("mode", "balance", "operation to perform: balance, transfer, batch-transfer, approve, deploy or mint")
+	rpcFlag := flag.String("rpc", "http://127.0.0.1:8545", "Ethereum RPC endpoint")
+	contractFlag := flag.String("contract", "", "ERC-1155 contract address")
+	accountsFlag := flag.String("accounts", "", "comma-separated holders (balance mode)")
+	idsFlag := flag.String("ids", "", "comma-separated token IDs")
+	valuesFlag := flag.String("values", "", "comma-separated amounts, one per --ids entry (transfer and mint modes)")
+	toFlag := flag.String("to", "", "recipient (transfer, batch-transfer and mint modes)")
+	dataFlag := flag.String("data", "0x", "hex data forwarded to the receiver hook")
+	operatorFlag := flag.String("operator", "", "operator address (approve mode)")
+	approvedFlag := flag.Bool("approved", true, "grant (true) or revoke (false) operator approval")
+	uriFlag := flag.String("uri", "ipfs://demo/{id}.json", "metadata URI template (deploy mode)")
+	privFlag := flag.String("priv", "4f3edf983ac636a65a842ce7c78d9aa706d3b113bce9c46f30d7d21715b23b1d", "hex private key for sending transactions (Ganache default account[0])")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, *rpcFlag)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	if *modeFlag == "deploy" {
+		auth := newTransactor(ctx, client, *privFlag)
+		address, tx, _, err := token.DeployERC1155(auth, client, *uriFlag)
+		if err != nil {
+			log.Fatalf("deploy ERC-1155: %v", err)
+		}
+		fmt.Printf("Deployer : %s\n", auth.From.Hex())
+		fmt.Printf("Contract : %s\n", address.Hex())
+		fmt.Printf("Tx Hash  : %s\n", tx.Hash().Hex())
+		waitMined(ctx, client, tx)
+		return
+	}
+
+	contractAddr := parseAddress(*contractFlag, "--contract")
+	instance, err := token.NewERC1155(contractAddr, client)
+	if err != nil {
+		log.Fatalf("instantiate ERC-1155 binding: %v", err)
+	}
+
+	switch *modeFlag {
+	case "balance":
+		accounts := parseAddresses(*accountsFlag)
+		ids := parseAmounts(*idsFlag, "--ids")
+		printBalances(ctx, instance, accounts, ids)
+	case "transfer":
+		ids := parseAmounts(*idsFlag, "--ids")
+		values := parseAmounts(*valuesFlag, "--values")
+		if len(ids) != 1 || len(values) != 1 {
+			log.Fatal("transfer mode takes exactly one --ids and one --values entry (use batch-transfer for more)")
+		}
+		to := parseAddress(*toFlag, "--to")
+		auth := newTransactor(ctx, client, *privFlag)
+		tx, err := instance.SafeTransferFrom(auth, auth.From, to, ids[0], values[0], parseData(*dataFlag))
+		if err != nil {
+			log.Fatalf("safeTransferFrom: %v", err)
+		}
+		fmt.Printf("tx sent: %s (%s of id %s -> %s)\n", tx.Hash().Hex(), values[0], ids[0], to.Hex())
+		waitMined(ctx, client, tx)
+	case "batch-transfer":
+		ids, values := parseBatch(*idsFlag, *valuesFlag)
+		to := parseAddress(*toFlag, "--to")
+		auth := newTransactor(ctx, client, *privFlag)
+		tx, err := instance.SafeBatchTransferFrom(auth, auth.From, to, ids, values, parseData(*dataFlag))
+		if err != nil {
+			log.Fatalf("safeBatchTransferFrom: %v", err)
+		}
+		fmt.Printf("tx sent: %s (%d ids -> %s)\n", tx.Hash().Hex(), len(ids), to.Hex())
+		waitMined(ctx, client, tx)
+	case "approve":
+		operator := parseAddress(*operatorFlag, "--operator")
+		auth := newTransactor(ctx, client, *privFlag)
+		tx, err := instance.SetApprovalForAll(auth, operator, *approvedFlag)
+		if err != nil {
+			log.Fatalf("setApprovalForAll: %v", err)
+		}
+		fmt.Printf("tx sent: %s (operator %s approved=%v)\n", tx.Hash().Hex(), operator.Hex(), *approvedFlag)
+		waitMined(ctx, client, tx)
+	case "mint":
+		ids, values := parseBatch(*idsFlag, *valuesFlag)
+		to := parseAddress(*toFlag, "--to")
+		auth := newTransactor(ctx, client, *privFlag)
+		var tx *types.Transaction
+		if len(ids) == 1 {
+			tx, err = instance.Mint(auth, to, ids[0], values[0])
+		} else {
+			tx, err = instance.MintBatch(auth, to, ids, values)
+		}
+		if err != nil {
+			log.Fatalf("mint: %v", err)
+		}
+		fmt.Printf("tx sent: %s (minted %d ids to %s)\n", tx.Hash().Hex(), len(ids), to.Hex())
+		waitMined(ctx, client, tx)
+	default:
+		log.Fatalf("unknown mode %q (expected balance, transfer, batch-transfer, approve, deploy or mint)", *modeFlag)
+	}
+}
+
+// printBalances queries every account/id pair with a single balanceOfBatch
+// call and prints one row per pair.
+func printBalances(ctx context.Context, instance *token.ERC1155, accounts []common.Address, ids []*big.Int) {
+	batchAccounts := make([]common.Address, 0, len(accounts)*len(ids))
+	batchIDs := make([]*big.Int, 0, len(accounts)*len(ids))
+	for _, account := range accounts {
+		for _, id := range ids {
+			batchAccounts = append(batchAccounts, account)
+			batchIDs = append(batchIDs, id)
+		}
+	}
+
+	balances, err := instance.BalanceOfBatch(&bind.CallOpts{Context: ctx}, batchAccounts, batchIDs)
+	if err != nil {
+		log.Fatalf("balanceOfBatch: %v", err)
+	}
+	if len(balances) != len(batchIDs) {
+		log.Fatalf("balanceOfBatch returned %d balances for %d pairs", len(balances), len(batchIDs))
+	}
+	for i, balance := range balances {
+		fmt.Printf("%s id=%s balance=%s\n", batchAccounts[i].Hex(), batchIDs[i], balance)
+	}
+}
+
+func newTransactor(ctx context.Context, client *ethclient.Client, privHex string) *bind.TransactOpts {
+	privateKey, err := transactor.ParseKey(privHex)
+	if err != nil {
+		log.Fatalf("parse private key: %v", err)
+	}
+	auth, err := transactor.New(ctx, client, privateKey, 0)
+	if err != nil {
+		log.Fatalf("prepare transactor: %v", err)
+	}
+	return auth
+}
+
+func waitMined(ctx context.Context, client *ethclient.Client, tx *types.Transaction) {
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		log.Fatalf("wait for %s: %v", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		log.Fatalf("tx %s reverted in block %d", tx.Hash().Hex(), receipt.BlockNumber)
+	}
+}
+
+func parseAddress(value, name string) common.Address {
+	if !common.IsHexAddress(value) {
+		log.Fatalf("%s must be a valid hex address", name)
+	}
+	return common.HexToAddress(value)
+}
+
+func parseAddresses(list string) []common.Address {
+	var out []common.Address
+	for _, item := range splitList(list) {
+		out = append(out, parseAddress(item, "--accounts entry"))
+	}
+	if len(out) == 0 {
+		log.Fatal("--accounts is required")
+	}
+	return out
+}
+
+func parseAmounts(list, name string) []*big.Int {
+	var out []*big.Int
+	for _, item := range splitList(list) {
+		v, ok := new(big.Int).SetString(item, 10)
+		if !ok || v.Sign() < 0 {
+			log.Fatalf("invalid %s entry %q", name, item)
+		}
+		out = append(out, v)
+	}
+	if len(out) == 0 {
+		log.Fatalf("%s is required", name)
+	}
+	return out
+}
+
+func parseBatch(idList, valueList string) ([]*big.Int, []*big.Int) {
+	ids := parseAmounts(idList, "--ids")
+	values := parseAmounts(valueList, "--values")
+	if len(ids) != len(values) {
+		log.Fatalf("--ids has %d entries but --values has %d", len(ids), len(values))
+	}
+	return ids, values
+}
+
+func parseData(value string) []byte {
+	data, err := hexutil.Decode(value)
+	if err != nil {
+		log.Fatalf("invalid --data: %v", err)
+	}
+	return data
+}
+
+func splitList(list string) []string {
+	var out []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
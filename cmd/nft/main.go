@@ -0,0 +1,279 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/token"
+	"github.com/obingo31/go-eth/transactor"
+)
+
+// ERC-165 interface ID of ERC721Enumerable.
+var enumerableInterfaceID = [4]byte{0x78, 0x0e, 0x9d, 0x63}
+
+func main() {
+	modeFlag := flag.String("mode", "info", "operation to perform: info, owner, balance, tokens, transfer, deploy or mint")
+	rpcFlag := flag.String("rpc", "http://127.0.0.1:8545", "Ethereum RPC endpoint")
+	contractFlag := flag.String("contract", "", "ERC-721 contract address")
+	accountFlag := flag.String("account", "", "account for balance/tokens, or recipient for transfer/mint")
+	idFlag := flag.String("id", "", "token ID (decimal)")
+	privFlag := flag.String("priv", "4f3edf983ac636a65a842ce7c78d9aa706d3b113bce9c46f30d7d21715b23b1d", "hex private key for transfer/deploy/mint (Ganache default account[0])")
+	fromBlockFlag := flag.Uint64("from-block", 0, "first block to replay Transfer events from when the contract is not enumerable")
+	replayFlag := flag.Bool("replay", false, "always enumerate tokens by replaying Transfer events")
+	nameFlag := flag.String("name", "Demo NFT", "collection name (deploy mode)")
+	symbolFlag := flag.String("symbol", "DNFT", "collection symbol (deploy mode)")
+	baseURIFlag := flag.String("base-uri", "ipfs://demo/", "token URI prefix (deploy mode)")
+	flag.Parse()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, *rpcFlag)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	if *modeFlag == "deploy" {
+		auth := newTransactor(ctx, client, *privFlag)
+		address, tx, _, err := token.DeployERC721(auth, client, *nameFlag, *symbolFlag, *baseURIFlag)
+		if err != nil {
+			log.Fatalf("deploy NFT: %v", err)
+		}
+		fmt.Printf("Deployer : %s\n", auth.From.Hex())
+		fmt.Printf("Contract : %s\n", address.Hex())
+		fmt.Printf("Tx Hash  : %s\n", tx.Hash().Hex())
+		waitMined(ctx, client, tx)
+		return
+	}
+
+	if !common.IsHexAddress(*contractFlag) {
+		log.Fatal("--contract must be a valid hex address")
+	}
+	contractAddr := common.HexToAddress(*contractFlag)
+	nft, err := token.NewERC721(contractAddr, client)
+	if err != nil {
+		log.Fatalf("instantiate ERC-721 binding: %v", err)
+	}
+	callOpts := &bind.CallOpts{Context: ctx}
+
+	switch *modeFlag {
+	case "info":
+		name, err := nft.Name(callOpts)
+		if err != nil {
+			log.Fatalf("fetch name: %v", err)
+		}
+		symbol, err := nft.Symbol(callOpts)
+		if err != nil {
+			log.Fatalf("fetch symbol: %v", err)
+		}
+		fmt.Printf("name: %s\n", name)
+		fmt.Printf("symbol: %s\n", symbol)
+		if isEnumerable(callOpts, nft) {
+			supply, err := nft.TotalSupply(callOpts)
+			if err != nil {
+				log.Fatalf("fetch total supply: %v", err)
+			}
+			fmt.Printf("total supply: %s\n", supply)
+		} else {
+			fmt.Println("total supply: n/a (not ERC721Enumerable)")
+		}
+	case "owner":
+		tokenID := parseID(*idFlag)
+		owner, err := nft.OwnerOf(callOpts, tokenID)
+		if err != nil {
+			log.Fatalf("ownerOf(%s): %v", tokenID, err)
+		}
+		approved, err := nft.GetApproved(callOpts, tokenID)
+		if err != nil {
+			log.Fatalf("getApproved(%s): %v", tokenID, err)
+		}
+		fmt.Printf("token %s\n", tokenID)
+		fmt.Printf("  owner   : %s\n", owner.Hex())
+		fmt.Printf("  approved: %s\n", approved.Hex())
+		if uri, err := nft.TokenURI(callOpts, tokenID); err == nil {
+			fmt.Printf("  uri     : %s\n", uri)
+		} else {
+			fmt.Printf("  uri     : n/a (%v)\n", err)
+		}
+	case "balance":
+		account := parseAddress(*accountFlag, "--account")
+		balance, err := nft.BalanceOf(callOpts, account)
+		if err != nil {
+			log.Fatalf("balanceOf(%s): %v", account.Hex(), err)
+		}
+		fmt.Printf("%s holds %s tokens\n", account.Hex(), balance)
+	case "tokens":
+		account := parseAddress(*accountFlag, "--account")
+		var (
+			ids    []*big.Int
+			source string
+		)
+		if !*replayFlag && isEnumerable(callOpts, nft) {
+			ids, err = enumerateOwned(callOpts, nft, account)
+			source = "ERC721Enumerable"
+		} else {
+			ids, err = replayOwned(ctx, nft, account, *fromBlockFlag)
+			source = fmt.Sprintf("Transfer replay from block %d", *fromBlockFlag)
+		}
+		if err != nil {
+			log.Fatalf("enumerate tokens: %v", err)
+		}
+		fmt.Printf("%s owns %d tokens (via %s)\n", account.Hex(), len(ids), source)
+		for _, id := range ids {
+			fmt.Printf("  %s\n", id)
+		}
+	case "transfer":
+		to := parseAddress(*accountFlag, "--account")
+		tokenID := parseID(*idFlag)
+		auth := newTransactor(ctx, client, *privFlag)
+		tx, err := nft.SafeTransferFrom(auth, auth.From, to, tokenID)
+		if err != nil {
+			log.Fatalf("safeTransferFrom: %v", err)
+		}
+		fmt.Printf("tx sent: %s (token %s -> %s)\n", tx.Hash().Hex(), tokenID, to.Hex())
+		waitMined(ctx, client, tx)
+	case "mint":
+		to := parseAddress(*accountFlag, "--account")
+		tokenID := parseID(*idFlag)
+		auth := newTransactor(ctx, client, *privFlag)
+		tx, err := nft.Mint(auth, to, tokenID)
+		if err != nil {
+			log.Fatalf("mint: %v", err)
+		}
+		fmt.Printf("tx sent: %s (minted %s to %s)\n", tx.Hash().Hex(), tokenID, to.Hex())
+		waitMined(ctx, client, tx)
+	default:
+		log.Fatalf("unknown mode %q (expected info, owner, balance, tokens, transfer, deploy or mint)", *modeFlag)
+	}
+}
+
+// isEnumerable reports whether the contract advertises ERC721Enumerable via
+// ERC-165. Contracts without supportsInterface are treated as not enumerable.
+func isEnumerable(opts *bind.CallOpts, nft *token.ERC721) bool {
+	ok, err := nft.SupportsInterface(opts, enumerableInterfaceID)
+	return err == nil && ok
+}
+
+func enumerateOwned(opts *bind.CallOpts, nft *token.ERC721, account common.Address) ([]*big.Int, error) {
+	balance, err := nft.BalanceOf(opts, account)
+	if err != nil {
+		return nil, fmt.Errorf("balanceOf: %w", err)
+	}
+	ids := make([]*big.Int, 0, balance.Uint64())
+	for i := uint64(0); i < balance.Uint64(); i++ {
+		id, err := nft.TokenOfOwnerByIndex(opts, account, new(big.Int).SetUint64(i))
+		if err != nil {
+			return nil, fmt.Errorf("tokenOfOwnerByIndex(%d): %w", i, err)
+		}
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+	return ids, nil
+}
+
+// replayOwned rebuilds the set of tokens held by account from every Transfer
+// into and out of it, applied in chain order.
+func replayOwned(ctx context.Context, nft *token.ERC721, account common.Address, fromBlock uint64) ([]*big.Int, error) {
+	type move struct {
+		block, index uint
+		id           *big.Int
+		incoming     bool
+	}
+	var moves []move
+
+	collect := func(from, to []common.Address, incoming bool) error {
+		it, err := nft.FilterTransfer(&bind.FilterOpts{Start: fromBlock, Context: ctx}, from, to, nil)
+		if err != nil {
+			return err
+		}
+		defer it.Close()
+		for it.Next() {
+			ev := it.Event
+			if ev.Raw.Removed || ev.From == ev.To {
+				continue
+			}
+			moves = append(moves, move{block: uint(ev.Raw.BlockNumber), index: ev.Raw.Index, id: ev.TokenId, incoming: incoming})
+		}
+		return it.Error()
+	}
+	if err := collect(nil, []common.Address{account}, true); err != nil {
+		return nil, fmt.Errorf("filter incoming transfers: %w", err)
+	}
+	if err := collect([]common.Address{account}, nil, false); err != nil {
+		return nil, fmt.Errorf("filter outgoing transfers: %w", err)
+	}
+
+	sort.Slice(moves, func(i, j int) bool {
+		if moves[i].block != moves[j].block {
+			return moves[i].block < moves[j].block
+		}
+		return moves[i].index < moves[j].index
+	})
+	owned := make(map[string]*big.Int)
+	for _, m := range moves {
+		if m.incoming {
+			owned[m.id.String()] = m.id
+		} else {
+			delete(owned, m.id.String())
+		}
+	}
+
+	ids := make([]*big.Int, 0, len(owned))
+	for _, id := range owned {
+		ids = append(ids, id)
+	}
+	sortIDs(ids)
+	return ids, nil
+}
+
+func sortIDs(ids []*big.Int) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Cmp(ids[j]) < 0 })
+}
+
+func newTransactor(ctx context.Context, client *ethclient.Client, privHex string) *bind.TransactOpts {
+	privateKey, err := transactor.ParseKey(privHex)
+	if err != nil {
+		log.Fatalf("parse private key: %v", err)
+	}
+	auth, err := transactor.New(ctx, client, privateKey, 0)
+	if err != nil {
+		log.Fatalf("prepare transactor: %v", err)
+	}
+	return auth
+}
+
+func waitMined(ctx context.Context, client *ethclient.Client, tx *types.Transaction) {
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		log.Fatalf("wait for %s: %v", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		log.Fatalf("tx %s reverted in block %d", tx.Hash().Hex(), receipt.BlockNumber)
+	}
+}
+
+func parseAddress(value, name string) common.Address {
+	if !common.IsHexAddress(value) {
+		log.Fatalf("%s must be a valid hex address", name)
+	}
+	return common.HexToAddress(value)
+}
+
+func parseID(value string) *big.Int {
+	id, ok := new(big.Int).SetString(value, 10)
+	if !ok || id.Sign() < 0 {
+		log.Fatalf("invalid --id %q", value)
+	}
+	return id
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/token"
+	"github.com/obingo31/go-eth/tokenlist"
+)
+
+// tokenInfo is the metadata needed to label and scale one matrix column.
+type tokenInfo struct {
+	address  common.Address
+	symbol   string
+	decimals uint8
+	err      error
+}
+
+// cell is one account/token balance; err is set when the call reverted.
+type cell struct {
+	value *big.Int
+	err   error
+}
+
+func main() {
+	rpcFlag := flag.String("rpc", "http://127.0.0.1:8545", "Ethereum RPC endpoint")
+	accountsFlag := flag.String("accounts", "", "comma-separated account addresses")
+	accountsFileFlag := flag.String("accounts-file", "", "file with one account address per line")
+	tokensFlag := flag.String("tokens", "", "comma-separated ERC-20 symbols (from --tokenlist) or contract addresses")
+	tokensFileFlag := flag.String("tokens-file", "", "file with one ERC-20 symbol or contract address per line")
+	tokenListFlag := flag.String("tokenlist", tokenlist.DefaultPath, "comma-separated Uniswap token list files used to resolve token symbols")
+	blockFlag := flag.Int64("block", -1, "block number to read balances at (-1 for latest)")
+	atFlag := flag.String("at", "", "read balances at the last block at or before this time instead of --block (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	batchFlag := flag.Int("batch-size", 100, "maximum JSON-RPC calls per batch request")
+	formatFlag := flag.String("format", "table", "output format: table or csv")
+	flag.Parse()
+
+	accounts := uniqueAddresses(collectRefs(*accountsFlag, *accountsFileFlag, "account"), func(ref string) (common.Address, error) {
+		if !common.IsHexAddress(ref) {
+			return common.Address{}, fmt.Errorf("invalid account address %q", ref)
+		}
+		return common.HexToAddress(ref), nil
+	})
+	if len(accounts) == 0 {
+		log.Fatal("at least one account is required (--accounts or --accounts-file)")
+	}
+	tokenRefs := collectRefs(*tokensFlag, *tokensFileFlag, "token")
+	if *batchFlag < 1 {
+		log.Fatal("--batch-size must be >= 1")
+	}
+	if *formatFlag != "table" && *formatFlag != "csv" {
+		log.Fatalf("unknown format %q (expected table or csv)", *formatFlag)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client, err := rpc.DialContext(ctx, *rpcFlag)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	resolver, err := tokenlist.OpenFor(ctx, ethclient.NewClient(client), *tokenListFlag, tokenRefs...)
+	if err != nil {
+		log.Fatalf("open token lists: %v", err)
+	}
+	tokens := uniqueAddresses(tokenRefs, resolver.Resolve)
+
+	erc20, err := abi.JSON(strings.NewReader(token.TokenABI))
+	if err != nil {
+		log.Fatalf("parse token ABI: %v", err)
+	}
+
+	if err := blocktime.SetBlock(ctx, ethclient.NewClient(client), *blockTimeCacheFlag, *atFlag, blockFlag); err != nil {
+		log.Fatalf("resolve --at: %v", err)
+	}
+
+	// Pin every call to one block so the matrix is a consistent snapshot.
+	roundTrips := 0
+	blockNumber := big.NewInt(*blockFlag)
+	if *blockFlag < 0 {
+		var head hexutil.Big
+		if err := client.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+			log.Fatalf("fetch block number: %v", err)
+		}
+		roundTrips++
+		blockNumber = head.ToInt()
+	}
+	blockArg := hexutil.EncodeBig(blockNumber)
+
+	var (
+		calls    []rpc.BatchElem
+		ethBal   = make([]hexutil.Big, len(accounts))
+		symbols  = make([]hexutil.Bytes, len(tokens))
+		decimals = make([]hexutil.Bytes, len(tokens))
+		balances = make([][]hexutil.Bytes, len(accounts))
+	)
+	ethCall := func(to common.Address, data []byte, result *hexutil.Bytes) rpc.BatchElem {
+		msg := map[string]interface{}{"to": to, "data": hexutil.Bytes(data)}
+		return rpc.BatchElem{Method: "eth_call", Args: []interface{}{msg, blockArg}, Result: result}
+	}
+	for i, account := range accounts {
+		calls = append(calls, rpc.BatchElem{Method: "eth_getBalance", Args: []interface{}{account, blockArg}, Result: &ethBal[i]})
+	}
+	symbolData, _ := erc20.Pack("symbol")
+	decimalsData, _ := erc20.Pack("decimals")
+	for j, tokenAddr := range tokens {
+		calls = append(calls, ethCall(tokenAddr, symbolData, &symbols[j]), ethCall(tokenAddr, decimalsData, &decimals[j]))
+	}
+	for i, account := range accounts {
+		balances[i] = make([]hexutil.Bytes, len(tokens))
+		data, err := erc20.Pack("balanceOf", account)
+		if err != nil {
+			log.Fatalf("encode balanceOf: %v", err)
+		}
+		for j, tokenAddr := range tokens {
+			calls = append(calls, ethCall(tokenAddr, data, &balances[i][j]))
+		}
+	}
+
+	for start := 0; start < len(calls); start += *batchFlag {
+		end := min(start+*batchFlag, len(calls))
+		if err := client.BatchCallContext(ctx, calls[start:end]); err != nil {
+			log.Fatalf("batch call: %v", err)
+		}
+		roundTrips++
+	}
+	for i := range accounts {
+		if err := calls[i].Error; err != nil {
+			log.Fatalf("balance of %s: %v", accounts[i].Hex(), err)
+		}
+	}
+
+	infos := make([]tokenInfo, len(tokens))
+	for j, tokenAddr := range tokens {
+		base := len(accounts) + 2*j
+		infos[j] = tokenInfo{address: tokenAddr, symbol: tokenAddr.Hex()}
+		if err := firstError(calls[base].Error, calls[base+1].Error); err != nil {
+			infos[j].err = err
+			continue
+		}
+		infos[j].symbol = decodeSymbol(erc20, symbols[j], tokenAddr)
+		var dec uint8
+		if err := unpackInto(erc20, "decimals", decimals[j], &dec); err != nil {
+			infos[j].err = fmt.Errorf("decode decimals: %w", err)
+			continue
+		}
+		infos[j].decimals = dec
+	}
+	labelDuplicates(infos)
+
+	matrix := make([][]cell, len(accounts))
+	for i := range accounts {
+		matrix[i] = make([]cell, len(tokens))
+		for j := range tokens {
+			elem := calls[len(accounts)+2*len(tokens)+i*len(tokens)+j]
+			if err := firstError(infos[j].err, elem.Error); err != nil {
+				matrix[i][j].err = err
+				continue
+			}
+			var value *big.Int
+			if err := unpackInto(erc20, "balanceOf", balances[i][j], &value); err != nil {
+				matrix[i][j].err = err
+				continue
+			}
+			matrix[i][j].value = value
+		}
+	}
+
+	if *formatFlag == "csv" {
+		writeCSV(accounts, ethBal, infos, matrix)
+	} else {
+		writeTable(accounts, ethBal, infos, matrix)
+	}
+	log.Printf("block %s: %d accounts x %d tokens in %d round trips (%d calls)", blockNumber, len(accounts), len(tokens), roundTrips, len(calls))
+}
+
+func writeTable(accounts []common.Address, ethBal []hexutil.Big, infos []tokenInfo, matrix [][]cell) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := []string{"ACCOUNT", "ETH"}
+	for _, info := range infos {
+		header = append(header, info.symbol)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t")+"\t")
+	for i, account := range accounts {
+		row := []string{account.Hex(), token.FormatUnits(ethBal[i].ToInt(), 18)}
+		for j := range infos {
+			row = append(row, formatCell(matrix[i][j], infos[j].decimals))
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t")+"\t")
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatalf("write table: %v", err)
+	}
+	for _, info := range infos {
+		if info.err != nil {
+			log.Printf("warn: token %s: %v", info.address.Hex(), info.err)
+		}
+	}
+}
+
+func writeCSV(accounts []common.Address, ethBal []hexutil.Big, infos []tokenInfo, matrix [][]cell) {
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write([]string{"account", "asset", "contract", "decimals", "raw", "balance", "error"})
+	for i, account := range accounts {
+		_ = w.Write([]string{account.Hex(), "ETH", "", "18", ethBal[i].ToInt().String(), token.FormatUnits(ethBal[i].ToInt(), 18), ""})
+		for j, info := range infos {
+			c := matrix[i][j]
+			if c.err != nil {
+				_ = w.Write([]string{account.Hex(), info.symbol, info.address.Hex(), "", "", "", c.err.Error()})
+				continue
+			}
+			_ = w.Write([]string{account.Hex(), info.symbol, info.address.Hex(), fmt.Sprint(info.decimals), c.value.String(), token.FormatUnits(c.value, info.decimals), ""})
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatalf("write csv: %v", err)
+	}
+}
+
+func formatCell(c cell, decimals uint8) string {
+	if c.err != nil {
+		return "error"
+	}
+	return token.FormatUnits(c.value, decimals)
+}
+
+// decodeSymbol handles both string symbols and the bytes32 symbols used by
+// early tokens such as MKR, falling back to the contract address.
+func decodeSymbol(erc20 abi.ABI, data []byte, addr common.Address) string {
+	var symbol string
+	if err := unpackInto(erc20, "symbol", data, &symbol); err == nil && symbol != "" {
+		return symbol
+	}
+	if len(data) == 32 {
+		if s := strings.TrimRight(string(data), "\x00"); s != "" {
+			return s
+		}
+	}
+	return addr.Hex()
+}
+
+// labelDuplicates appends a short address to symbols that appear more than
+// once so matrix columns stay unambiguous.
+func labelDuplicates(infos []tokenInfo) {
+	count := make(map[string]int)
+	for _, info := range infos {
+		count[info.symbol]++
+	}
+	for j := range infos {
+		if count[infos[j].symbol] > 1 {
+			infos[j].symbol = fmt.Sprintf("%s(%s)", infos[j].symbol, infos[j].address.Hex()[:8])
+		}
+	}
+}
+
+func unpackInto(erc20 abi.ABI, method string, data []byte, out interface{}) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%s returned no data", method)
+	}
+	values, err := erc20.Unpack(method, data)
+	if err != nil {
+		return err
+	}
+	return erc20.Methods[method].Outputs.Copy(out, values)
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectRefs gathers comma-separated values and lines from file, skipping
+// blanks and "#" comments.
+func collectRefs(list, file, kind string) []string {
+	items := strings.Split(list, ",")
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			log.Fatalf("open %s file: %v", kind, err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			items = append(items, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			log.Fatalf("read %s file: %v", kind, err)
+		}
+	}
+
+	var out []string
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" || strings.HasPrefix(item, "#") {
+			continue
+		}
+		out = append(out, item)
+	}
+	return out
+}
+
+func uniqueAddresses(refs []string, resolve func(string) (common.Address, error)) []common.Address {
+	var out []common.Address
+	seen := make(map[common.Address]bool)
+	for _, ref := range refs {
+		addr, err := resolve(ref)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !seen[addr] {
+			seen[addr] = true
+			out = append(out, addr)
+		}
+	}
+	return out
+}
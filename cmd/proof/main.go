@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/logscan"
+	"github.com/obingo31/go-eth/proof"
+	"github.com/obingo31/go-eth/storestate"
+	"github.com/obingo31/go-eth/tokenlist"
+)
+
+func main() {
+	modeFlag := flag.String("mode", "account", "operation to perform: account, store, token or verify")
+	rpcFlag := flag.String("rpc", "http://127.0.0.1:8545", "Ethereum RPC endpoint (an archive node for past blocks)")
+	addrFlag := flag.String("addr", "", "account (account mode), Store contract (store mode) or token address/symbol (token mode)")
+	blockFlag := flag.Int64("block", -1, "block to prove against (-1 for latest)")
+	atFlag := flag.String("at", "", "prove against the last block at or before this time instead of --block (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	keysFlag := flag.String("keys", "", "comma-separated Store keys as text or 0x bytes32 (store mode; empty proves every key written)")
+	fromFlag := flag.Uint64("from", 0, "first block replayed to find Store keys when --keys is empty")
+	holdersFlag := flag.String("holders", "", "comma-separated token holders (token mode)")
+	slotFlag := flag.Int64("balance-slot", -1, "storage slot of the token's balances mapping (-1 to detect it)")
+	tokenListFlag := flag.String("tokenlist", tokenlist.DefaultPath, "comma-separated Uniswap token list files used to resolve token symbols")
+	outFlag := flag.String("out", "", "write the proof bundle to this file")
+	bundleFlag := flag.String("bundle", "proof.json", "bundle to check offline (verify mode)")
+	flag.Parse()
+
+	if *modeFlag == "verify" {
+		verify(*bundleFlag)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, *rpcFlag)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	if err := blocktime.SetBlock(ctx, client, *blockTimeCacheFlag, *atFlag, blockFlag); err != nil {
+		log.Fatalf("resolve --at: %v", err)
+	}
+
+	// Pin the block first so key discovery, slot detection and the proof
+	// all see the same state.
+	header, err := client.HeaderByNumber(ctx, blockNumber(*blockFlag))
+	if err != nil {
+		log.Fatalf("fetch header: %v", err)
+	}
+	block := header.Number
+
+	var (
+		address common.Address
+		slots   []proof.Slot
+	)
+	switch *modeFlag {
+	case "account":
+		address = parseAddress(*addrFlag, "--addr")
+	case "store":
+		address = parseAddress(*addrFlag, "--addr")
+		keys := storeKeys(ctx, client, address, *keysFlag, *fromFlag, block.Uint64())
+		for _, k := range keys {
+			slots = append(slots, proof.StoreItem(k))
+		}
+	case "token":
+		resolver, err := tokenlist.OpenFor(ctx, client, *tokenListFlag, *addrFlag)
+		if err != nil {
+			log.Fatalf("open token lists: %v", err)
+		}
+		if address, err = resolver.Resolve(*addrFlag); err != nil {
+			log.Fatalf("resolve --addr: %v", err)
+		}
+		holders := splitList(*holdersFlag)
+		if len(holders) == 0 {
+			log.Fatal("--holders is required in token mode")
+		}
+		mappingSlot := uint64(*slotFlag)
+		if *slotFlag < 0 {
+			if mappingSlot, err = proof.FindBalanceSlot(ctx, client, address, parseAddress(holders[0], "--holders"), block, 20); err != nil {
+				log.Fatalf("detect balance slot: %v (pass --balance-slot)", err)
+			}
+			log.Printf("balances mapping detected at slot %d", mappingSlot)
+		}
+		for _, h := range holders {
+			slots = append(slots, proof.Balance(parseAddress(h, "--holders"), mappingSlot))
+		}
+	default:
+		log.Fatalf("unknown mode %q", *modeFlag)
+	}
+
+	bundle, err := proof.Fetch(ctx, client, address, slots, block)
+	if err != nil {
+		log.Fatalf("prove %s: %v", address.Hex(), err)
+	}
+	printBundle(bundle)
+	if *outFlag != "" {
+		if err := bundle.Save(*outFlag); err != nil {
+			log.Fatalf("write bundle: %v", err)
+		}
+		log.Printf("wrote proof bundle to %s", *outFlag)
+	}
+}
+
+func verify(path string) {
+	bundle, err := proof.Load(path)
+	if err != nil {
+		log.Fatalf("load bundle: %v", err)
+	}
+	if err := bundle.Verify(); err != nil {
+		log.Fatalf("bundle does not verify: %v", err)
+	}
+	printBundle(bundle)
+	log.Printf("%s verifies against block %d (%s)", path, bundle.BlockNumber, bundle.BlockHash.Hex())
+}
+
+func printBundle(b *proof.Bundle) {
+	fmt.Printf("Block    : %d %s (proofs verified against its stateRoot)\n", b.BlockNumber, b.BlockHash.Hex())
+	fmt.Printf("Account  : %s\n", b.Address.Hex())
+	fmt.Printf("  Nonce  : %d\n", b.Account.Nonce)
+	fmt.Printf("  Balance: %s wei\n", b.Account.Balance.ToInt())
+	fmt.Printf("  Storage: %s\n", b.Account.StorageHash.Hex())
+	fmt.Printf("  Code   : %s\n", b.Account.CodeHash.Hex())
+	for _, s := range b.Storage {
+		value := storestate.Format(s.Value)
+		if strings.HasPrefix(s.Label, "balances[") {
+			value = s.Value.Big().String()
+		}
+		fmt.Printf("  %s = %s (slot %s)\n", s.Label, value, s.Key.Hex())
+	}
+}
+
+// storeKeys parses --keys, or replays ItemSet events when it is empty.
+func storeKeys(ctx context.Context, client *ethclient.Client, address common.Address, list string, from, block uint64) [][32]byte {
+	var keys [][32]byte
+	for _, k := range splitList(list) {
+		if strings.HasPrefix(k, "0x") && len(k) == 66 {
+			keys = append(keys, common.HexToHash(k))
+			continue
+		}
+		if len(k) > 32 {
+			log.Fatalf("key %q is longer than 32 bytes", k)
+		}
+		var key [32]byte
+		copy(key[:], k)
+		keys = append(keys, key)
+	}
+	if len(keys) > 0 {
+		return keys
+	}
+	state, err := storestate.Replay(ctx, client, address, from, block, logscan.Config{})
+	if err != nil {
+		log.Fatalf("replay ItemSet events: %v", err)
+	}
+	for _, item := range state.Items() {
+		keys = append(keys, item.Key)
+	}
+	log.Printf("proving %d keys found in ItemSet events up to block %d", len(keys), block)
+	return keys
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func parseAddress(s, flagName string) common.Address {
+	if !common.IsHexAddress(s) {
+		log.Fatalf("%s must be a hex address, got %q", flagName, s)
+	}
+	return common.HexToAddress(s)
+}
+
+func blockNumber(n int64) *big.Int {
+	if n < 0 {
+		return nil
+	}
+	return big.NewInt(n)
+}
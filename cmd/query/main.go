@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/obingo31/go-eth/indexer"
+	"github.com/obingo31/go-eth/logdecode"
+)
+
+func main() {
+	dbFlag := flag.String("db", indexer.DefaultPath, "index database directory (used when --server is empty)")
+	serverFlag := flag.String("server", "", "URL of a running index command, e.g. http://127.0.0.1:8600 (needed while it holds the database)")
+	contractFlag := flag.String("contract", "", "only events emitted by this contract address")
+	eventFlag := flag.String("event", "", "only events with this name, e.g. Transfer")
+	argsFlag := flag.String("arg", "", "comma-separated name=value argument filters, e.g. to=0x...,value=100")
+	fromFlag := flag.Uint64("from", 0, "first block")
+	toFlag := flag.Uint64("to", 0, "last block (0 for no limit)")
+	limitFlag := flag.Int("limit", 0, "maximum number of events (0 for all)")
+	jsonFlag := flag.Bool("json", false, "print one JSON record per event instead of text")
+	flag.Parse()
+
+	filter := indexer.Filter{Event: *eventFlag, From: *fromFlag, To: *toFlag, Limit: *limitFlag}
+	if *contractFlag != "" {
+		if !common.IsHexAddress(*contractFlag) {
+			log.Fatalf("invalid --contract address %q", *contractFlag)
+		}
+		addr := common.HexToAddress(*contractFlag)
+		filter.Contract = &addr
+	}
+	args, err := indexer.ParseArgs(*argsFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	filter.Args = args
+
+	var recs []logdecode.Record
+	if *serverFlag != "" {
+		recs, err = remoteQuery(*serverFlag, filter)
+	} else {
+		recs, err = localQuery(*dbFlag, filter)
+	}
+	if err != nil {
+		log.Fatalf("query: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, rec := range recs {
+		if *jsonFlag {
+			if err := enc.Encode(rec); err != nil {
+				log.Fatalf("encode record: %v", err)
+			}
+			continue
+		}
+		fmt.Printf("block=%d tx=%s log=%d %s %s\n", rec.BlockNumber, rec.TxHash.Hex(), rec.LogIndex, rec.Address.Hex(), rec)
+	}
+	log.Printf("%d events", len(recs))
+}
+
+func localQuery(path string, filter indexer.Filter) ([]logdecode.Record, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("no index at %s (run cmd/index first): %w", path, err)
+	}
+	ix, err := indexer.Open(path, true)
+	if err != nil {
+		return nil, fmt.Errorf("%w (if cmd/index is running, use --server)", err)
+	}
+	defer ix.Close()
+	return ix.Query(filter)
+}
+
+func remoteQuery(server string, filter indexer.Filter) ([]logdecode.Record, error) {
+	client := &http.Client{Timeout: time.Minute}
+	resp, err := client.Get(strings.TrimRight(server, "/") + "/events?" + filter.Values().Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(msg))
+	}
+
+	var recs []logdecode.Record
+	dec := json.NewDecoder(resp.Body)
+	for dec.More() {
+		var rec logdecode.Record
+		if err := dec.Decode(&rec); err != nil {
+			return nil, fmt.Errorf("decode event: %w", err)
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/obingo31/go-eth/reorg"
+	"github.com/obingo31/go-eth/subscription"
+)
+
+func main() {
+	rpcFlag := flag.String("rpc", "ws://127.0.0.1:8546", "Ethereum RPC endpoint (WebSocket, or HTTP with filter polling)")
+	modeFlag := flag.String("mode", "auto", "subscription mechanism: auto, ws, filter or poll")
+	pollFlag := flag.Duration("poll", 2*time.Second, "polling interval when the endpoint cannot push new heads")
+	windowFlag := flag.Int("window", 128, "canonical blocks remembered; deeper reorgs restart the window")
+	skipTxsFlag := flag.Bool("skip-txs", false, "do not fetch the blocks of both branches to list affected transactions")
+	jsonFlag := flag.Bool("json", false, "print reorg events as NDJSON")
+	statusFlag := flag.String("status-addr", "", "address serving the detector counters at /status (empty to disable)")
+	flag.Parse()
+
+	mode, err := subscription.ParseMode(*modeFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := subscription.Dial(ctx, *rpcFlag, subscription.Options{Mode: mode, Interval: *pollFlag})
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	detector := reorg.NewDetector(client, reorg.Config{Window: *windowFlag, SkipTransactions: *skipTxsFlag})
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		log.Fatalf("fetch head: %v", err)
+	}
+	if err := detector.Seed(ctx, head); err != nil {
+		log.Fatalf("seed window: %v", err)
+	}
+
+	if *statusFlag != "" {
+		http.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			enc.Encode(detector.Stats())
+		})
+		go func() {
+			log.Fatal(http.ListenAndServe(*statusFlag, nil))
+		}()
+	}
+
+	headers := make(chan *types.Header)
+	sub, err := client.SubscribeNewHead(ctx, headers)
+	if err != nil {
+		log.Fatalf("subscribe new heads: %v", err)
+	}
+	defer sub.Unsubscribe()
+	log.Printf("watching for reorgs from block %d via %s (window %d)", head.Number, client.Mode(), *windowFlag)
+
+	enc := json.NewEncoder(os.Stdout)
+	for {
+		select {
+		case err := <-sub.Err():
+			log.Fatalf("subscription error: %v", err)
+		case <-ctx.Done():
+			s := detector.Stats()
+			log.Printf("stopped at block %d: %d heads, %d reorgs (max depth %d), %d txs dropped", s.TipNumber, s.Heads, s.Reorgs, s.MaxDepth, s.DroppedTxs)
+			return
+		case header := <-headers:
+			ev, err := detector.Add(ctx, header)
+			if err != nil {
+				log.Printf("block %d: %v", header.Number, err)
+				continue
+			}
+			if ev == nil {
+				continue
+			}
+			if *jsonFlag {
+				if err := enc.Encode(ev); err != nil {
+					log.Fatalf("encode event: %v", err)
+				}
+				continue
+			}
+			printEvent(ev)
+		}
+	}
+}
+
+func printEvent(ev *reorg.Event) {
+	fmt.Println(ev)
+	fmt.Printf("  ancestor: %d %s\n", ev.Ancestor.Number, ev.Ancestor.Hash.Hex())
+	for _, b := range ev.Old {
+		fmt.Printf("  - %d %s\n", b.Number, b.Hash.Hex())
+	}
+	for _, b := range ev.New {
+		fmt.Printf("  + %d %s\n", b.Number, b.Hash.Hex())
+	}
+	for _, t := range ev.Txs {
+		if t.Dropped() {
+			fmt.Printf("  tx %s dropped from block %d\n", t.Hash.Hex(), t.OldBlock)
+		} else {
+			fmt.Printf("  tx %s moved from block %d to %d\n", t.Hash.Hex(), t.OldBlock, t.NewBlock)
+		}
+	}
+	if ev.TxsIncomplete {
+		fmt.Println("  (some blocks could not be fetched; the transaction list may be incomplete)")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/obingo31/go-eth/logdecode"
+	"github.com/obingo31/go-eth/rules"
+	"github.com/obingo31/go-eth/subscription"
+	"github.com/obingo31/go-eth/tokenlist"
+	"github.com/obingo31/go-eth/transactor"
+)
+
+func main() {
+	configFlag := flag.String("config", "rules.yaml", "YAML rules file (see rules/example.yaml)")
+	rpcFlag := flag.String("rpc", "ws://127.0.0.1:8546", "Ethereum RPC endpoint (WebSocket, or HTTP with filter polling)")
+	modeFlag := flag.String("mode", "auto", "subscription mechanism: auto, ws, filter or poll")
+	pollFlag := flag.Duration("poll", 2*time.Second, "polling interval when the endpoint cannot push notifications")
+	privFlag := flag.String("priv", "", "hex private key signing transaction actions (default $RULES_PRIVATE_KEY)")
+	journalFlag := flag.String("journal", "rules-journal.ndjson", "file recording the actions run, so each runs at most once per log")
+	tokenListFlag := flag.String("tokenlist", tokenlist.DefaultPath, "comma-separated Uniswap token list files used to resolve contract symbols")
+	abiFlag := flag.String("abi", logdecode.DefaultPatterns, "comma-separated ABI files, globs or directories used to decode events")
+	dryRunFlag := flag.Bool("dry-run", false, "log matches without running actions")
+	flag.Parse()
+	// Read after parsing so usage output never prints the key.
+	if *privFlag == "" {
+		*privFlag = os.Getenv("RULES_PRIVATE_KEY")
+	}
+
+	cfg, err := rules.Load(*configFlag)
+	if err != nil {
+		log.Fatalf("load rules: %v", err)
+	}
+	mode, err := subscription.ParseMode(*modeFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	decoder, err := logdecode.NewDefault(*abiFlag)
+	if err != nil {
+		log.Fatalf("load ABIs: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := subscription.Dial(ctx, *rpcFlag, subscription.Options{Mode: mode, Interval: *pollFlag})
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	resolver, err := tokenlist.OpenFor(ctx, client, *tokenListFlag, cfg.Refs()...)
+	if err != nil {
+		log.Fatalf("open token lists: %v", err)
+	}
+
+	var key *ecdsa.PrivateKey
+	if *privFlag != "" {
+		if key, err = transactor.ParseKey(*privFlag); err != nil {
+			log.Fatalf("parse private key: %v", err)
+		}
+	}
+
+	var journal *rules.Journal
+	if !*dryRunFlag {
+		if journal, err = rules.OpenJournal(*journalFlag); err != nil {
+			log.Fatalf("open journal: %v", err)
+		}
+		defer journal.Close()
+	}
+
+	engine, err := rules.New(cfg, client, rules.Options{
+		Decoder:  decoder,
+		Resolver: resolver,
+		Journal:  journal,
+		Key:      key,
+		DryRun:   *dryRunFlag,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("watching %d rules from %s over %s", len(cfg.Rules), *configFlag, *rpcFlag)
+	if err := engine.Run(ctx); err != nil {
+		log.Fatalf("rules: %v", err)
+	}
+	log.Printf("stopped")
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/chaincheck"
+	"github.com/obingo31/go-eth/logdecode"
+	"github.com/obingo31/go-eth/logscan"
+	"github.com/obingo31/go-eth/tokenlist"
+)
+
+func main() {
+	rpcFlag := flag.String("rpc", "http://127.0.0.1:8545", "Ethereum RPC endpoint")
+	addrFlag := flag.String("addr", "", "comma-separated contract addresses or token symbols (empty for any contract)")
+	tokenListFlag := flag.String("tokenlist", tokenlist.DefaultPath, "comma-separated Uniswap token list files used to resolve --addr symbols")
+	eventFlag := flag.String("event", "", "comma-separated topic0 values: event signatures such as Transfer(address,address,uint256) or hashes")
+	topic1Flag := flag.String("topic1", "", "comma-separated values for the first indexed argument (addresses, numbers or hashes)")
+	topic2Flag := flag.String("topic2", "", "comma-separated values for the second indexed argument")
+	topic3Flag := flag.String("topic3", "", "comma-separated values for the third indexed argument")
+	fromFlag := flag.Uint64("from", 0, "first block to scan")
+	toFlag := flag.Int64("to", -1, "last block to scan (-1 for latest)")
+	fromAtFlag := flag.String("from-at", "", "start at the first block at or after this time instead of --from (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	toAtFlag := flag.String("to-at", "", "end at the last block at or before this time instead of --to")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	chunkFlag := flag.Uint64("chunk", 2000, "initial blocks per eth_getLogs call")
+	maxChunkFlag := flag.Uint64("max-chunk", 100000, "largest chunk the scanner may grow to")
+	concurrencyFlag := flag.Int("concurrency", 4, "chunks fetched in parallel")
+	checkpointFlag := flag.String("checkpoint", "", "file recording progress so an interrupted scan can resume")
+	abiFlag := flag.String("abi", logdecode.DefaultPatterns, "comma-separated ABI files, globs or directories used to decode events")
+	jsonFlag := flag.Bool("json", false, "print one JSON record per log instead of text")
+	bloomFlag := flag.Bool("bloom", false, "fetch headers only and request logs just for blocks whose logsBloom matches (for rare events)")
+	bloomBatchFlag := flag.Uint64("bloom-batch", 100, "headers fetched per batch in --bloom mode")
+	verifyFlag := flag.Bool("verify", false, "check every log against receipts whose trie root matches the block header")
+	flag.Parse()
+
+	decoder, err := logdecode.NewDefault(*abiFlag)
+	if err != nil {
+		log.Fatalf("load ABIs: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := ethclient.DialContext(ctx, *rpcFlag)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	query, err := buildQuery(ctx, client, *tokenListFlag, *addrFlag, *eventFlag, *topic1Flag, *topic2Flag, *topic3Flag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := blocktime.SetRange(ctx, client, *blockTimeCacheFlag, *fromAtFlag, *toAtFlag, fromFlag, toFlag); err != nil {
+		log.Fatalf("resolve --from-at/--to-at: %v", err)
+	}
+
+	to := uint64(*toFlag)
+	if *toFlag < 0 {
+		if to, err = client.BlockNumber(ctx); err != nil {
+			log.Fatalf("fetch block number: %v", err)
+		}
+	}
+
+	scanner := logscan.New(client, logscan.Config{
+		ChunkSize:   *chunkFlag,
+		MaxChunk:    *maxChunkFlag,
+		Concurrency: *concurrencyFlag,
+		Checkpoint:  *checkpointFlag,
+		BloomBatch:  *bloomBatchFlag,
+	})
+
+	var verifier *chaincheck.LogVerifier
+	if *verifyFlag {
+		verifier = chaincheck.NewLogVerifier(client)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	start := time.Now()
+	handle := func(from, to uint64, logs []types.Log) error {
+		for _, l := range logs {
+			if verifier != nil {
+				if err := verifier.Verify(ctx, l); err != nil {
+					return err
+				}
+			}
+			rec := decoder.Decode(l)
+			if *jsonFlag {
+				if err := enc.Encode(rec); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Printf("block=%d tx=%s log=%d %s %s\n", rec.BlockNumber, rec.TxHash.Hex(), rec.LogIndex, rec.Address.Hex(), rec)
+		}
+		return nil
+	}
+	if *bloomFlag {
+		err = scanner.ScanBloom(ctx, client, query, *fromFlag, to, handle)
+	} else {
+		err = scanner.Scan(ctx, query, *fromFlag, to, handle)
+	}
+	stats := scanner.Stats()
+	if stats.Resumed {
+		log.Printf("resumed from checkpoint %s", *checkpointFlag)
+	}
+	log.Printf("blocks %d-%d: %d logs, %d requests, %d splits, %d retries, final chunk %d, %s",
+		*fromFlag, to, stats.Logs, stats.Requests, stats.Splits, stats.Retries, scanner.ChunkSize(), time.Since(start).Round(time.Millisecond))
+	if *bloomFlag {
+		log.Printf("bloom: %d headers checked, %d blocks skipped, %d candidates (%d false positives)",
+			stats.Headers, stats.Skipped, stats.Candidates, stats.FalsePositives)
+	}
+	if verifier != nil {
+		vs := verifier.Stats()
+		log.Printf("verify: %d logs matched receipts of %d blocks with verified roots", vs.Logs, vs.Blocks)
+	}
+	if err != nil {
+		log.Fatalf("scan: %v", err)
+	}
+}
+
+func buildQuery(ctx context.Context, client *ethclient.Client, tokenLists, addrs string, topicLists ...string) (ethereum.FilterQuery, error) {
+	var query ethereum.FilterQuery
+	if strings.TrimSpace(addrs) != "" {
+		resolver, err := tokenlist.OpenFor(ctx, client, tokenLists, strings.Split(addrs, ",")...)
+		if err != nil {
+			return query, fmt.Errorf("open token lists: %w", err)
+		}
+		for _, ref := range strings.Split(addrs, ",") {
+			if ref = strings.TrimSpace(ref); ref == "" {
+				continue
+			}
+			addr, err := resolver.Resolve(ref)
+			if err != nil {
+				return query, fmt.Errorf("resolve --addr: %w", err)
+			}
+			query.Addresses = append(query.Addresses, addr)
+		}
+	}
+
+	// Trailing wildcard positions are dropped; inner ones stay as nil.
+	topics := make([][]common.Hash, len(topicLists))
+	last := -1
+	for i, list := range topicLists {
+		parsed, err := logscan.ParseTopics(list)
+		if err != nil {
+			return query, fmt.Errorf("topic %d: %w", i, err)
+		}
+		topics[i] = parsed
+		if len(parsed) > 0 {
+			last = i
+		}
+	}
+	query.Topics = topics[:last+1]
+	return query, nil
+}
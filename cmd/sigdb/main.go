@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/obingo31/go-eth/logdecode"
+	"github.com/obingo31/go-eth/sigdb"
+)
+
+func main() {
+	modeFlag := flag.String("mode", "lookup", "operation to perform: build, lookup or collisions")
+	dbFlag := flag.String("db", sigdb.DefaultPath, "signature database file")
+	abiFlag := flag.String("abi", logdecode.DefaultPatterns, "comma-separated ABI files, globs or directories to add (build mode)")
+	importFlag := flag.String("import", "", "comma-separated 4byte-style text dumps to add (build mode)")
+	resetFlag := flag.Bool("reset", false, "start from an empty database instead of extending the existing one (build mode)")
+	jsonFlag := flag.Bool("json", false, "print lookups and collisions as JSON")
+	flag.Parse()
+
+	db := sigdb.New()
+	if !*resetFlag || *modeFlag != "build" {
+		var err error
+		if db, err = sigdb.Open(*dbFlag); err != nil {
+			log.Fatalf("open signature database: %v", err)
+		}
+	}
+
+	switch *modeFlag {
+	case "build":
+		build(db, *dbFlag, *abiFlag, *importFlag)
+	case "lookup":
+		if flag.NArg() == 0 {
+			log.Fatal("lookup mode takes one or more selectors or topics as arguments")
+		}
+		lookup(db, flag.Args(), *jsonFlag)
+	case "collisions":
+		collisions(db, *jsonFlag)
+	default:
+		log.Fatalf("unknown mode %q", *modeFlag)
+	}
+}
+
+func build(db *sigdb.DB, path, patterns, imports string) {
+	added := 0
+	for _, b := range logdecode.Bindings {
+		n, err := db.AddJSON(b.Name, strings.NewReader(b.ABI))
+		if err != nil {
+			log.Fatalf("load %s: %v", b.Name, err)
+		}
+		added += n
+	}
+	files, n, err := db.LoadFiles(patterns)
+	if err != nil {
+		log.Fatalf("load ABIs: %v", err)
+	}
+	fmt.Printf("ABIs     : %d bindings, %d files, %d new signatures\n", len(logdecode.Bindings), files, added+n)
+
+	for _, name := range strings.Split(imports, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			log.Fatalf("open dump: %v", err)
+		}
+		stats, err := db.Import(name, f)
+		f.Close()
+		if err != nil {
+			log.Fatalf("import %s: %v", name, err)
+		}
+		fmt.Printf("Import   : %s: %d new, %d known, %d invalid, %d mismatched IDs\n",
+			name, stats.Added, stats.Duplicates, stats.Invalid, stats.Mismatched)
+	}
+
+	if err := db.Save(path); err != nil {
+		log.Fatalf("save signature database: %v", err)
+	}
+	functions, events := db.Len()
+	fmt.Printf("Database : %s (%d functions, %d events, %d collisions)\n", path, functions, events, len(db.Collisions()))
+}
+
+func lookup(db *sigdb.DB, ids []string, asJSON bool) {
+	results := make(map[string][]sigdb.Signature, len(ids))
+	for _, id := range ids {
+		sigs, err := db.Lookup(id)
+		if err != nil {
+			log.Fatal(err)
+		}
+		results[id] = sigs
+		if asJSON {
+			continue
+		}
+		switch len(sigs) {
+		case 0:
+			fmt.Printf("%s: unknown\n", id)
+		case 1:
+			fmt.Printf("%s: %s %s (%s)\n", id, sigs[0].Kind, sigs[0].Text, sigs[0].Source)
+		default:
+			fmt.Printf("%s: %d colliding signatures\n", id, len(sigs))
+			for _, s := range sigs {
+				fmt.Printf("  %s %s (%s)\n", s.Kind, s.Text, s.Source)
+			}
+		}
+	}
+	if asJSON {
+		printJSON(results)
+	}
+}
+
+func collisions(db *sigdb.DB, asJSON bool) {
+	list := db.Collisions()
+	if asJSON {
+		printJSON(list)
+		return
+	}
+	if len(list) == 0 {
+		fmt.Println("no collisions")
+		return
+	}
+	for _, c := range list {
+		fmt.Printf("%s %s\n", c.Kind, c.ID)
+		for _, s := range c.Signatures {
+			fmt.Printf("  %s (%s)\n", s.Text, s.Source)
+		}
+	}
+}
+
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatalf("encode JSON: %v", err)
+	}
+}
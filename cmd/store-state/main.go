@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/logscan"
+	"github.com/obingo31/go-eth/storestate"
+)
+
+// jsonItem is one key of the --json output.
+type jsonItem struct {
+	Key       hexutil.Bytes `json:"key"`
+	KeyText   string        `json:"keyText,omitempty"`
+	Value     hexutil.Bytes `json:"value"`
+	ValueText string        `json:"valueText,omitempty"`
+	Writes    int           `json:"writes"`
+	Block     uint64        `json:"block"`
+	TxHash    common.Hash   `json:"txHash"`
+	OnChain   hexutil.Bytes `json:"onChain,omitempty"`
+	Drift     bool          `json:"drift,omitempty"`
+}
+
+func main() {
+	rpcFlag := flag.String("rpc", "http://127.0.0.1:8545", "Ethereum RPC endpoint (an archive node for past blocks)")
+	addrFlag := flag.String("addr", "", "Store contract address")
+	fromFlag := flag.Uint64("from", 0, "first block to replay (the deployment block saves requests)")
+	blockFlag := flag.Int64("block", -1, "block to reconstruct the state at (-1 for latest)")
+	atFlag := flag.String("at", "", "reconstruct the state at the last block at or before this time instead of --block (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	chunkFlag := flag.Uint64("chunk", 2000, "initial blocks per eth_getLogs call")
+	verifyFlag := flag.Bool("verify", true, "check every replayed key against items() at the same block")
+	concurrencyFlag := flag.Int("concurrency", 8, "items() calls in flight while verifying")
+	jsonFlag := flag.Bool("json", false, "print the state as JSON")
+	flag.Parse()
+
+	if !common.IsHexAddress(*addrFlag) {
+		log.Fatal("--addr is required (Store contract address)")
+	}
+	address := common.HexToAddress(*addrFlag)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := ethclient.DialContext(ctx, *rpcFlag)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	if err := blocktime.SetBlock(ctx, client, *blockTimeCacheFlag, *atFlag, blockFlag); err != nil {
+		log.Fatalf("resolve --at: %v", err)
+	}
+
+	block := uint64(*blockFlag)
+	if *blockFlag < 0 {
+		if block, err = client.BlockNumber(ctx); err != nil {
+			log.Fatalf("fetch block number: %v", err)
+		}
+	}
+
+	state, err := storestate.Replay(ctx, client, address, *fromFlag, block, logscan.Config{ChunkSize: *chunkFlag})
+	if err != nil {
+		log.Fatalf("replay ItemSet events: %v", err)
+	}
+
+	drifted := make(map[[32]byte][32]byte)
+	if *verifyFlag {
+		drift, err := state.Verify(ctx, client, *concurrencyFlag)
+		if err != nil {
+			log.Fatalf("verify: %v", err)
+		}
+		for _, d := range drift {
+			drifted[d.Key] = d.OnChain
+		}
+	}
+
+	if *jsonFlag {
+		printJSON(state, drifted)
+	} else {
+		printTable(state, drifted)
+	}
+
+	if *verifyFlag {
+		log.Printf("verified %d keys against items() at block %d: %d drifted", state.Len(), block, len(drifted))
+		if len(drifted) > 0 {
+			os.Exit(1)
+		}
+	}
+}
+
+func printTable(state *storestate.State, drifted map[[32]byte][32]byte) {
+	fmt.Printf("Store %s at block %d: %d keys from %d ItemSet events\n\n", state.Address.Hex(), state.Block, state.Len(), state.Events)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tWRITES\tLAST BLOCK\tSTATUS")
+	for _, item := range state.Items() {
+		status := "ok"
+		if onChain, ok := drifted[item.Key]; ok {
+			status = "DRIFT: on chain " + storestate.Format(onChain)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", storestate.Format(item.Key), storestate.Format(item.Value), item.Writes, item.Block, status)
+	}
+	w.Flush()
+}
+
+func printJSON(state *storestate.State, drifted map[[32]byte][32]byte) {
+	out := struct {
+		Address common.Address `json:"address"`
+		Block   uint64         `json:"block"`
+		Events  int            `json:"events"`
+		Items   []jsonItem     `json:"items"`
+	}{Address: state.Address, Block: state.Block, Events: state.Events}
+	for _, item := range state.Items() {
+		j := jsonItem{
+			Key:    item.Key[:],
+			Value:  item.Value[:],
+			Writes: item.Writes,
+			Block:  item.Block,
+			TxHash: item.TxHash,
+		}
+		j.KeyText, _ = storestate.Text(item.Key)
+		j.ValueText, _ = storestate.Text(item.Value)
+		if onChain, ok := drifted[item.Key]; ok {
+			j.OnChain, j.Drift = onChain[:], true
+		}
+		out.Items = append(out.Items, j)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(out); err != nil {
+		log.Fatalf("encode JSON: %v", err)
+	}
+}
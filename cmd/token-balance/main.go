@@ -14,16 +14,23 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	"github.com/obingo31/go-eth/token"
+	"github.com/obingo31/go-eth/tokenlist"
 )
 
 func main() {
 	rpcFlag := flag.String("rpc", "https://mainnet.infura.io/v3/YOUR_KEY", "Ethereum RPC endpoint")
-	contractFlag := flag.String("contract", "", "ERC-20 token contract address")
+	contractFlag := flag.String("contract", "", "ERC-20 token contract address (alias for --token)")
+	tokenFlag := flag.String("token", "", "ERC-20 token symbol from --tokenlist (e.g. USDC) or contract address")
+	tokenListFlag := flag.String("tokenlist", tokenlist.DefaultPath, "comma-separated Uniswap token list files used to resolve --token symbols")
 	accountFlag := flag.String("account", "", "Address whose balance should be fetched")
 	flag.Parse()
 
-	if !common.IsHexAddress(*contractFlag) {
-		log.Fatal("--contract must be a valid hex address")
+	tokenRef := *tokenFlag
+	if tokenRef == "" {
+		tokenRef = *contractFlag
+	}
+	if tokenRef == "" {
+		log.Fatal("--token (or --contract) is required")
 	}
 	if !common.IsHexAddress(*accountFlag) {
 		log.Fatal("--account must be a valid hex address")
@@ -40,7 +47,15 @@ func main() {
 	}
 	defer client.Close()
 
-	tokenAddress := common.HexToAddress(*contractFlag)
+	resolver, err := tokenlist.OpenFor(context.Background(), client, *tokenListFlag, tokenRef)
+	if err != nil {
+		log.Fatalf("open token lists: %v", err)
+	}
+	tokenAddress, err := resolver.Resolve(tokenRef)
+	if err != nil {
+		log.Fatalf("resolve token: %v", err)
+	}
+
 	instance, err := token.NewToken(tokenAddress, client)
 	if err != nil {
 		log.Fatalf("instantiate token binding: %v", err)
@@ -69,6 +84,7 @@ func main() {
 		log.Fatalf("fetch decimals: %v", err)
 	}
 
+	fmt.Printf("contract: %s\n", tokenAddress.Hex())
 	fmt.Printf("name: %s\n", name)
 	fmt.Printf("symbol: %s\n", symbol)
 	fmt.Printf("decimals: %d\n", decimals)
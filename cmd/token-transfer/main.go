@@ -16,12 +16,16 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"golang.org/x/crypto/sha3"
+
+	"github.com/obingo31/go-eth/tokenlist"
 )
 
 func main() {
 	rpcFlag := flag.String("rpc", "https://sepolia.infura.io/v3/b3ce18e518ab499cb2975c8952bb0a47", "Ethereum RPC endpoint")
 	privFlag := flag.String("priv", "", "hex-encoded private key (no passphrase)")
-	contractFlag := flag.String("contract", "0xF4D17Dd253A5a21555bF1a5B9B7285ed764AF706", "ERC-20 token contract address")
+	contractFlag := flag.String("contract", "0xF4D17Dd253A5a21555bF1a5B9B7285ed764AF706", "ERC-20 token contract address (overridden by --token)")
+	tokenFlag := flag.String("token", "", "ERC-20 token symbol from --tokenlist (e.g. DEMO) or contract address")
+	tokenListFlag := flag.String("tokenlist", tokenlist.DefaultPath, "comma-separated Uniswap token list files used to resolve --token symbols")
 	toFlag := flag.String("to", "0x5bb34D0bf5DC32df87Ae454DEb17001F808b986b", "recipient address")
 	amountFlag := flag.String("amount", "1000000000000000000000", "token amount in the smallest unit (wei-style)")
 	flag.Parse()
@@ -29,8 +33,11 @@ func main() {
 	if *privFlag == "" {
 		log.Fatal("--priv is required")
 	}
-	if !common.IsHexAddress(*contractFlag) || !common.IsHexAddress(*toFlag) {
-		log.Fatal("invalid --contract or --to address")
+	if !common.IsHexAddress(*toFlag) {
+		log.Fatal("invalid --to address")
+	}
+	if *tokenFlag == "" && !common.IsHexAddress(*contractFlag) {
+		log.Fatal("invalid --contract address")
 	}
 
 	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(*privFlag, "0x"))
@@ -43,7 +50,6 @@ func main() {
 		log.Fatalf("invalid amount: %s", *amountFlag)
 	}
 
-	tokenAddress := common.HexToAddress(*contractFlag)
 	recipient := common.HexToAddress(*toFlag)
 
 	transferFnSignature := []byte("transfer(address,uint256)")
@@ -70,6 +76,18 @@ func main() {
 	}
 	defer client.Close()
 
+	tokenAddress := common.HexToAddress(*contractFlag)
+	if *tokenFlag != "" {
+		resolver, err := tokenlist.OpenFor(context.Background(), client, *tokenListFlag, *tokenFlag)
+		if err != nil {
+			log.Fatalf("open token lists: %v", err)
+		}
+		if tokenAddress, err = resolver.Resolve(*tokenFlag); err != nil {
+			log.Fatalf("resolve token: %v", err)
+		}
+	}
+	fmt.Printf("Token          : %s\n", tokenAddress.Hex())
+
 	publicKey, ok := privateKey.Public().(*ecdsa.PublicKey)
 	if !ok {
 		log.Fatal("unable to cast public key to ECDSA")
@@ -6,19 +6,37 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"net/http"
+	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/blobs"
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/chaincheck"
+	"github.com/obingo31/go-eth/logdecode"
 )
 
 func main() {
 	rpcFlag := flag.String("rpc", "http://localhost:8545", "Ethereum RPC endpoint")
 	blockFlag := flag.Int64("block", 5671744, "block number to inspect")
+	atFlag := flag.String("at", "", "inspect the last block at or before this time instead of --block (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
 	hashFlag := flag.String("tx", "", "specific transaction hash to fetch")
+	abiFlag := flag.String("abi", logdecode.DefaultPatterns, "comma-separated ABI files, globs or directories used to decode calldata")
+	verifyFlag := flag.Bool("verify", false, "rebuild the transactions and receipts tries and check them against the header")
+	sidecarFlag := flag.String("sidecar", "", "blob sidecar file to verify against the versioned hashes of --tx (or of the whole block)")
+	beaconFlag := flag.String("beacon", "", "beacon node URL to fetch the block's blob sidecars from and verify them")
 	flag.Parse()
 
+	decoder, err := logdecode.NewDefault(*abiFlag)
+	if err != nil {
+		log.Fatalf("load ABIs: %v", err)
+	}
+
 	client, err := ethclient.Dial(*rpcFlag)
 	if err != nil {
 		log.Fatalf("dial RPC: %v", err)
@@ -28,6 +46,10 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
+	if err := blocktime.SetBlock(ctx, client, *blockTimeCacheFlag, *atFlag, blockFlag); err != nil {
+		log.Fatalf("resolve --at: %v", err)
+	}
+
 	blockNumber := big.NewInt(*blockFlag)
 	block, err := client.BlockByNumber(ctx, blockNumber)
 	if err != nil {
@@ -36,6 +58,13 @@ func main() {
 
 	fmt.Printf("Inspecting block %d (%s) with %d txns\n", block.NumberU64(), block.Hash(), len(block.Transactions()))
 
+	if *verifyFlag {
+		if _, _, err := chaincheck.FetchVerified(ctx, client, block.Hash()); err != nil {
+			log.Fatalf("verify block %d: %v", block.NumberU64(), err)
+		}
+		fmt.Printf("Roots verified: transactions %s, receipts %s\n", block.TxHash().Hex(), block.ReceiptHash().Hex())
+	}
+
 	chainID, err := client.NetworkID(ctx)
 	if err != nil {
 		log.Fatalf("fetch network ID: %v", err)
@@ -50,6 +79,15 @@ func main() {
 		fmt.Printf("  GasPrice: %s\n", tx.GasPrice().String())
 		fmt.Printf("  Nonce   : %d\n", tx.Nonce())
 		fmt.Printf("  DataLen : %d\n", len(tx.Data()))
+		if tx.Type() == types.BlobTxType {
+			fmt.Printf("  BlobGas : %d (max blob fee %s wei)\n", tx.BlobGas(), tx.BlobGasFeeCap())
+			for _, h := range tx.BlobHashes() {
+				fmt.Printf("  Blob    : %s\n", h.Hex())
+			}
+		}
+		if len(tx.Data()) > 0 && tx.To() != nil {
+			fmt.Printf("  Call    : %s\n", decoder.DecodeCall(tx.Data()))
+		}
 		if to := tx.To(); to != nil {
 			fmt.Printf("  To      : %s\n", to.Hex())
 		} else {
@@ -65,6 +103,9 @@ func main() {
 			log.Fatalf("receipt for %s: %v", tx.Hash(), err)
 		}
 		fmt.Printf("  Status  : %d\n", receipt.Status)
+		if receipt.BlobGasUsed > 0 {
+			fmt.Printf("  BlobFee : %d blob gas at %s wei\n", receipt.BlobGasUsed, receipt.BlobGasPrice)
+		}
 	}
 
 	blockHash := block.Hash()
@@ -90,4 +131,59 @@ func main() {
 		}
 		fmt.Printf("TransactionByHash %s -> pending=%v\n", tx.Hash().Hex(), isPending)
 	}
+
+	if *sidecarFlag != "" || *beaconFlag != "" {
+		verifySidecar(ctx, client, block, *hashFlag, *sidecarFlag, *beaconFlag)
+	}
+}
+
+// verifySidecar checks a sidecar from a file or a beacon node against the
+// versioned hashes of one transaction, or of every blob transaction in the
+// block when txHash is empty. A transaction's sidecar is fetched for the
+// block that included it, which need not be block.
+func verifySidecar(ctx context.Context, client *ethclient.Client, block *types.Block, txHash, path, beaconURL string) {
+	var hashes []common.Hash
+	number := block.NumberU64()
+	if txHash != "" {
+		hash := common.HexToHash(txHash)
+		tx, isPending, err := client.TransactionByHash(ctx, hash)
+		if err != nil {
+			log.Fatalf("transaction by hash: %v", err)
+		}
+		if isPending {
+			log.Fatalf("transaction %s is pending, so no block holds its blobs yet", txHash)
+		}
+		if tx.Type() != types.BlobTxType {
+			log.Fatalf("transaction %s carries no blobs", txHash)
+		}
+		receipt, err := client.TransactionReceipt(ctx, hash)
+		if err != nil {
+			log.Fatalf("receipt for %s: %v", txHash, err)
+		}
+		hashes = tx.BlobHashes()
+		number = receipt.BlockNumber.Uint64()
+	} else {
+		hashes = blobs.Hashes(blobs.Transactions(block))
+	}
+
+	var (
+		sidecar []blobs.Blob
+		err     error
+	)
+	if path != "" {
+		sidecar, err = blobs.Load(path)
+	} else {
+		sidecar, err = blobs.FetchBlock(ctx, client, http.DefaultClient, beaconURL, number)
+	}
+	if err != nil {
+		log.Fatalf("load sidecar: %v", err)
+	}
+
+	// A block's sidecar also covers the block's other blob transactions, so
+	// leftover blobs only fail a whole-block check.
+	report := blobs.Check(sidecar, hashes, txHash != "")
+	report.Print(os.Stdout)
+	if err := report.Err(); err != nil {
+		log.Fatalf("verify sidecar: %v", err)
+	}
 }
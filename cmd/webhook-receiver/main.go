@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/obingo31/go-eth/webhook"
+)
+
+// A local stand-in for a webhook consumer: it verifies signatures, prints
+// each delivery and can fail on purpose to exercise retries.
+func main() {
+	listenFlag := flag.String("listen", "127.0.0.1:9000", "address to listen on")
+	secretFlag := flag.String("secret", "", "shared HMAC secret (default $WEBHOOK_SECRET; empty skips verification)")
+	failRateFlag := flag.Float64("fail-rate", 0, "fraction of deliveries answered with 503 to exercise retries")
+	rejectFlag := flag.Bool("reject", false, "answer every delivery with 400 to exercise dead-lettering")
+	toleranceFlag := flag.Duration("tolerance", 5*time.Minute, "maximum age of a delivery's timestamp")
+	flag.Parse()
+	// Read after parsing so usage output never prints the secret.
+	if *secretFlag == "" {
+		*secretFlag = os.Getenv("WEBHOOK_SECRET")
+	}
+
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "POST only", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if *secretFlag != "" {
+			if err := webhook.Verify(*secretFlag, r.Header, body, *toleranceFlag); err != nil {
+				log.Printf("rejected %s: %v", r.Header.Get(webhook.EventIDHeader), err)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+		if *rejectFlag {
+			http.Error(w, "rejecting deliveries", http.StatusBadRequest)
+			return
+		}
+		if rand.Float64() < *failRateFlag {
+			http.Error(w, "simulated outage", http.StatusServiceUnavailable)
+			return
+		}
+
+		var p webhook.Payload
+		if err := json.Unmarshal(body, &p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		key := fmt.Sprintf("%s/%t", p.ID, p.Removed)
+		mu.Lock()
+		seen[key]++
+		count := seen[key]
+		mu.Unlock()
+		status := ""
+		if p.Removed {
+			status = " REMOVED"
+		}
+		if count > 1 {
+			status += fmt.Sprintf(" (duplicate #%d)", count)
+		}
+		fmt.Printf("%s%s block=%d %s %s\n", p.ID, status, p.Event.BlockNumber, p.Event.Address.Hex(), p.Event)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("receiving webhooks on http://%s/", *listenFlag)
+	log.Fatal(http.ListenAndServe(*listenFlag, nil))
+}
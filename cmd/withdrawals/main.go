@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/withdrawals"
+)
+
+func main() {
+	rpcFlag := flag.String("rpc", "http://localhost:8545", "Ethereum RPC endpoint")
+	fromFlag := flag.Int64("from", -1, "first block (-1 for --last blocks before --to)")
+	toFlag := flag.Int64("to", -1, "last block (-1 for latest)")
+	fromAtFlag := flag.String("from-at", "", "start at the first block at or after this time instead of --from (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	toAtFlag := flag.String("to-at", "", "end at the last block at or before this time instead of --to")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	lastFlag := flag.Uint64("last", 1000, "number of blocks when --from is not set")
+	addressFlag := flag.String("address", "", "comma-separated withdrawal recipients to keep (empty for all)")
+	validatorFlag := flag.String("validator", "", "comma-separated validator indexes to keep (empty for all)")
+	periodFlag := flag.String("period", withdrawals.PeriodAll, "totals per address and day, week, month or all")
+	listFlag := flag.Bool("list", false, "print every withdrawal before the totals")
+	formatFlag := flag.String("format", "table", "output format: table, csv or json")
+	concurrencyFlag := flag.Int("concurrency", 8, "blocks fetched in parallel")
+	flag.Parse()
+
+	if *formatFlag != "table" && *formatFlag != "csv" && *formatFlag != "json" {
+		log.Fatalf("unknown format %q (want table, csv or json)", *formatFlag)
+	}
+	if _, err := withdrawals.PeriodLabel(0, *periodFlag); err != nil {
+		log.Fatal(err)
+	}
+	cfg := withdrawals.Config{Concurrency: *concurrencyFlag}
+	for _, a := range splitList(*addressFlag) {
+		if !common.IsHexAddress(a) {
+			log.Fatalf("--address must be hex addresses, got %q", a)
+		}
+		cfg.Addresses = append(cfg.Addresses, common.HexToAddress(a))
+	}
+	for _, v := range splitList(*validatorFlag) {
+		index, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			log.Fatalf("--validator must be validator indexes, got %q", v)
+		}
+		cfg.Validators = append(cfg.Validators, index)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := ethclient.DialContext(ctx, *rpcFlag)
+	if err != nil {
+		log.Fatalf("dial RPC: %v", err)
+	}
+	defer client.Close()
+
+	if err := blocktime.SetRange(ctx, client, *blockTimeCacheFlag, *fromAtFlag, *toAtFlag, fromFlag, toFlag); err != nil {
+		log.Fatalf("resolve --from-at/--to-at: %v", err)
+	}
+
+	to := uint64(*toFlag)
+	if *toFlag < 0 {
+		if to, err = client.BlockNumber(ctx); err != nil {
+			log.Fatalf("fetch block number: %v", err)
+		}
+	}
+	from := uint64(*fromFlag)
+	if *fromFlag < 0 {
+		from = 0
+		if to+1 > *lastFlag {
+			from = to + 1 - *lastFlag
+		}
+	}
+
+	ws, err := withdrawals.Scan(ctx, client, from, to, cfg)
+	if err != nil {
+		log.Fatalf("scan blocks %d-%d: %v", from, to, err)
+	}
+	totals, err := withdrawals.Totals(ws, *periodFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch *formatFlag {
+	case "json":
+		out := struct {
+			From        uint64                   `json:"from"`
+			To          uint64                   `json:"to"`
+			Withdrawals []withdrawals.Withdrawal `json:"withdrawals,omitempty"`
+			Totals      []withdrawals.Total      `json:"totals"`
+		}{From: from, To: to, Totals: totals}
+		if *listFlag {
+			out.Withdrawals = ws
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			log.Fatalf("encode report: %v", err)
+		}
+	case "csv":
+		if *listFlag {
+			writeWithdrawalsCSV(ws)
+			return
+		}
+		writeTotalsCSV(totals)
+	default:
+		fmt.Printf("Blocks %d-%d: %d withdrawals to %d addresses\n", from, to, len(ws), countAddresses(ws))
+		if *listFlag && len(ws) > 0 {
+			fmt.Println()
+			printWithdrawals(ws)
+		}
+		if len(totals) > 0 {
+			fmt.Println()
+			printTotals(totals)
+		}
+	}
+}
+
+func printWithdrawals(ws []withdrawals.Withdrawal) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "BLOCK\tTIME\tINDEX\tVALIDATOR\tADDRESS\tETH")
+	for _, wd := range ws {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%s\t%s\n", wd.Block, blockTime(wd.Time), wd.Index, wd.Validator, wd.Address.Hex(), gweiToEth(wd.Amount))
+	}
+	w.Flush()
+}
+
+func printTotals(totals []withdrawals.Total) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PERIOD\tADDRESS\tCOUNT\tVALIDATORS\tBLOCKS\tETH")
+	for _, t := range totals {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d-%d\t%s\n", t.Period, t.Address.Hex(), t.Count, t.Validators, t.FirstBlock, t.LastBlock, gweiToEth(t.Amount))
+	}
+	w.Flush()
+}
+
+func writeWithdrawalsCSV(ws []withdrawals.Withdrawal) {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"block", "time", "index", "validator", "address", "amount_gwei"})
+	for _, wd := range ws {
+		w.Write([]string{
+			strconv.FormatUint(wd.Block, 10), strconv.FormatUint(wd.Time, 10), strconv.FormatUint(wd.Index, 10),
+			strconv.FormatUint(wd.Validator, 10), wd.Address.Hex(), strconv.FormatUint(wd.Amount, 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatalf("write csv: %v", err)
+	}
+}
+
+func writeTotalsCSV(totals []withdrawals.Total) {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"period", "address", "count", "validators", "first_block", "last_block", "amount_gwei"})
+	for _, t := range totals {
+		w.Write([]string{
+			t.Period, t.Address.Hex(), strconv.Itoa(t.Count), strconv.Itoa(t.Validators),
+			strconv.FormatUint(t.FirstBlock, 10), strconv.FormatUint(t.LastBlock, 10), strconv.FormatUint(t.Amount, 10),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatalf("write csv: %v", err)
+	}
+}
+
+func countAddresses(ws []withdrawals.Withdrawal) int {
+	seen := make(map[common.Address]bool)
+	for _, w := range ws {
+		seen[w.Address] = true
+	}
+	return len(seen)
+}
+
+// gweiToEth formats a gwei amount as ETH without rounding.
+func gweiToEth(gwei uint64) string {
+	const perEth = params.Ether / params.GWei
+	s := fmt.Sprintf("%d.%09d", gwei/perEth, gwei%perEth)
+	return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+}
+
+func blockTime(ts uint64) string {
+	return time.Unix(int64(ts), 0).UTC().Format(time.RFC3339)
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
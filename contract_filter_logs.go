@@ -2,104 +2,156 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"math/big"
+	"os"
+	"strconv"
 	"strings"
 
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
-	token "github.com/obingo31/go-eth/token"
+	"github.com/obingo31/go-eth/blocktime"
+	"github.com/obingo31/go-eth/logdecode"
+	"github.com/obingo31/go-eth/logscan"
+	"github.com/obingo31/go-eth/tokenlist"
 )
 
-type LogTransfer struct {
-	From   common.Address
-	To     common.Address
-	Tokens *big.Int `abi:"value"`
-}
-
-type LogApproval struct {
-	TokenOwner common.Address
-	Spender    common.Address
-	Tokens     *big.Int `abi:"value"`
-}
-
 func main() {
 	rpcFlag := flag.String("rpc", "https://mainnet.infura.io/v3/b3ce18e518ab499cb2975c8952bb0a47", "Ethereum RPC endpoint")
 	fromFlag := flag.String("from", "6383820", "start block number")
 	toFlag := flag.String("to", "6383840", "end block number (inclusive)")
-	addrFlag := flag.String("addr", "0xe41d2489571d322189246dafa5ebde1f4699f498", "ERC-20 contract address")
+	fromAtFlag := flag.String("from-at", "", "start at the first block at or after this time instead of --from (e.g. 2024-01-01T00:00:00Z, 2024-01-01 or unix seconds)")
+	toAtFlag := flag.String("to-at", "", "end at the last block at or before this time instead of --to")
+	blockTimeCacheFlag := flag.String("blocktime-cache", "", "file keeping resolved block times between runs (empty keeps them in memory)")
+	addrFlag := flag.String("addr", "0xe41d2489571d322189246dafa5ebde1f4699f498", "contract address, or a token symbol from --tokenlist")
+	tokenListFlag := flag.String("tokenlist", tokenlist.DefaultPath, "comma-separated Uniswap token list files used to resolve --addr symbols")
+	abiFlag := flag.String("abi", logdecode.DefaultPatterns, "comma-separated ABI files, globs or directories used to decode events")
+	jsonFlag := flag.Bool("json", false, "print one JSON record per log instead of text")
+	chunkFlag := flag.Uint64("chunk", 2000, "initial blocks per eth_getLogs call; halved when the provider rejects a range")
 	flag.Parse()
 
-	fromBlock, ok := new(big.Int).SetString(*fromFlag, 10)
-	if !ok {
+	fromBlock, err := strconv.ParseUint(*fromFlag, 10, 64)
+	if err != nil {
 		log.Fatalf("invalid --from block: %s", *fromFlag)
 	}
-	toBlock, ok := new(big.Int).SetString(*toFlag, 10)
-	if !ok {
+	toBlock, err := strconv.ParseUint(*toFlag, 10, 64)
+	if err != nil {
 		log.Fatalf("invalid --to block: %s", *toFlag)
 	}
 
+	decoder, err := logdecode.NewDefault(*abiFlag)
+	if err != nil {
+		log.Fatalf("load ABIs: %v", err)
+	}
+
 	client, err := ethclient.Dial(*rpcFlag)
 	if err != nil {
 		log.Fatalf("dial RPC: %v", err)
 	}
 	defer client.Close()
 
-	contractAddress := common.HexToAddress(*addrFlag)
-	query := ethereum.FilterQuery{
-		FromBlock: fromBlock,
-		ToBlock:   toBlock,
-		Addresses: []common.Address{contractAddress},
+	if err := blocktime.SetRange(context.Background(), client, *blockTimeCacheFlag, *fromAtFlag, *toAtFlag, &fromBlock, &toBlock); err != nil {
+		log.Fatalf("resolve --from-at/--to-at: %v", err)
 	}
 
-	logs, err := client.FilterLogs(context.Background(), query)
+	resolver, err := tokenlist.OpenFor(context.Background(), client, *tokenListFlag, *addrFlag)
 	if err != nil {
-		log.Fatalf("filter logs: %v", err)
+		log.Fatalf("open token lists: %v", err)
+	}
+	contractAddress, err := resolver.Resolve(*addrFlag)
+	if err != nil {
+		log.Fatalf("resolve --addr: %v", err)
 	}
 
-	contractABI, err := abi.JSON(strings.NewReader(token.TokenABI))
+	query := ethereum.FilterQuery{Addresses: []common.Address{contractAddress}}
+
+	enc := json.NewEncoder(os.Stdout)
+	scanner := logscan.New(client, logscan.Config{ChunkSize: *chunkFlag})
+	err = scanner.Scan(context.Background(), query, fromBlock, toBlock, func(_, _ uint64, logs []types.Log) error {
+		for _, vLog := range logs {
+			rec := decoder.Decode(vLog)
+			if *jsonFlag {
+				if err := enc.Encode(rec); err != nil {
+					return fmt.Errorf("encode record: %w", err)
+				}
+				continue
+			}
+			printRecord(rec)
+		}
+		return nil
+	})
 	if err != nil {
-		log.Fatalf("parse token ABI: %v", err)
+		log.Fatalf("filter logs: %v", err)
 	}
+}
 
-	transferSig := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
-	approvalSig := crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+func printRecord(rec logdecode.Record) {
+	fmt.Printf("Log Block Number: %d\n", rec.BlockNumber)
+	fmt.Printf("Log Index: %d\n", rec.LogIndex)
+	switch {
+	case !rec.Decoded():
+		fmt.Println("Log Name: Unknown event signature")
+		for _, candidate := range rec.Candidates {
+			fmt.Printf("Candidate: %s\n", candidate)
+		}
+		for i, topic := range rec.Topics {
+			fmt.Printf("Topic %d: %s\n", i, topic.Hex())
+		}
+		fmt.Printf("Data: %s\n", rec.Data)
+	case rec.Event == "TransferBatch":
+		printBatch(rec)
+	case rec.Guessed:
+		fmt.Printf("Log Name: %s (from %s)\n", rec.Event, rec.Signature)
+		printArgs(rec.Args)
+	default:
+		fmt.Printf("Log Name: %s\n", rec.Event)
+		printArgs(rec.Args)
+	}
+	fmt.Println()
+}
 
-	for _, vLog := range logs {
-		fmt.Printf("Log Block Number: %d\n", vLog.BlockNumber)
-		fmt.Printf("Log Index: %d\n", vLog.Index)
+// printBatch prints one row per id so ERC-1155 batches read like a series of
+// TransferSingle events.
+func printBatch(rec logdecode.Record) {
+	ids, _ := rec.Arg("ids")
+	values, _ := rec.Arg("values")
+	idList, _ := ids.([]*big.Int)
+	valueList, _ := values.([]*big.Int)
+	if len(idList) != len(valueList) {
+		log.Fatalf("transfer batch in tx %s has %d ids but %d values", rec.TxHash.Hex(), len(idList), len(valueList))
+	}
+	var shared []logdecode.Arg
+	for _, a := range rec.Args {
+		if a.Name != "ids" && a.Name != "values" {
+			shared = append(shared, a)
+		}
+	}
+	for i := range idList {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Log Name: TransferBatch (%d/%d)\n", i+1, len(idList))
+		printArgs(shared)
+		fmt.Printf("ID: %s\n", idList[i])
+		fmt.Printf("Value: %s\n", valueList[i])
+	}
+}
 
-		switch vLog.Topics[0].Hex() {
-		case transferSig.Hex():
-			fmt.Println("Log Name: Transfer")
-			var transferEvent LogTransfer
-			if err := contractABI.UnpackIntoInterface(&transferEvent, "Transfer", vLog.Data); err != nil {
-				log.Fatalf("unpack transfer: %v", err)
-			}
-			transferEvent.From = common.HexToAddress(vLog.Topics[1].Hex())
-			transferEvent.To = common.HexToAddress(vLog.Topics[2].Hex())
-			fmt.Printf("From: %s\n", transferEvent.From.Hex())
-			fmt.Printf("To: %s\n", transferEvent.To.Hex())
-			fmt.Printf("Tokens: %s\n", transferEvent.Tokens.String())
-		case approvalSig.Hex():
-			fmt.Println("Log Name: Approval")
-			var approvalEvent LogApproval
-			if err := contractABI.UnpackIntoInterface(&approvalEvent, "Approval", vLog.Data); err != nil {
-				log.Fatalf("unpack approval: %v", err)
-			}
-			approvalEvent.TokenOwner = common.HexToAddress(vLog.Topics[1].Hex())
-			approvalEvent.Spender = common.HexToAddress(vLog.Topics[2].Hex())
-			fmt.Printf("Token Owner: %s\n", approvalEvent.TokenOwner.Hex())
-			fmt.Printf("Spender: %s\n", approvalEvent.Spender.Hex())
-			fmt.Printf("Tokens: %s\n", approvalEvent.Tokens.String())
-		default:
-			fmt.Println("Log Name: Unknown event signature")
+func printArgs(args []logdecode.Arg) {
+	for i, a := range args {
+		name := a.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		value := logdecode.FormatValue(a.Value)
+		if a.Hashed {
+			value += " (keccak256)"
 		}
-		fmt.Println()
+		fmt.Printf("%s: %s\n", strings.ToUpper(name[:1])+name[1:], value)
 	}
 }
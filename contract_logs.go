@@ -5,53 +5,130 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/obingo31/go-eth/logdecode"
+	"github.com/obingo31/go-eth/logstream"
+	"github.com/obingo31/go-eth/subscription"
+	"github.com/obingo31/go-eth/webhook"
 )
 
 func main() {
-	wsFlag := flag.String("ws", "ws://127.0.0.1:8545", "WebSocket RPC endpoint")
+	wsFlag := flag.String("ws", "ws://127.0.0.1:8545", "RPC endpoint; ws:// subscribes, http:// falls back to filter polling")
+	modeFlag := flag.String("mode", "auto", "head subscription mechanism: auto, ws, filter or poll")
+	pollFlag := flag.Duration("poll", 2*time.Second, "polling interval when the endpoint cannot push new heads")
 	addrFlag := flag.String("addr", "", "Contract address to filter events for")
+	abiFlag := flag.String("abi", logdecode.DefaultPatterns, "comma-separated ABI files, globs or directories used to decode events")
+	confirmationsFlag := flag.Uint64("confirmations", 0, "blocks to wait before printing a log (0 prints at the head and retracts on reorgs)")
+	cursorFlag := flag.String("cursor", "", "file persisting the stream position so a restart resumes without gaps or duplicates")
+	fromFlag := flag.Int64("from", -1, "first block to print when there is no cursor yet (-1 for the current head)")
+	webhookFlag := flag.String("webhook", "", "comma-separated URLs that receive every decoded event as signed JSON")
+	secretFlag := flag.String("webhook-secret", "", "HMAC secret used to sign webhook deliveries (default $WEBHOOK_SECRET)")
+	deadLetterFlag := flag.String("dead-letter", "webhook-deadletter.ndjson", "file collecting events that could not be delivered")
+	statusFlag := flag.String("status-addr", "", "address serving the webhook delivery report at /status (empty to disable)")
 	flag.Parse()
+	// Read after parsing so usage output never prints the secret.
+	if *secretFlag == "" {
+		*secretFlag = os.Getenv("WEBHOOK_SECRET")
+	}
 
 	if *addrFlag == "" {
 		log.Fatal("--addr is required")
 	}
+	mode, err := subscription.ParseMode(*modeFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	ctx := context.Background()
-	client, err := ethclient.DialContext(ctx, *wsFlag)
+	decoder, err := logdecode.NewDefault(*abiFlag)
 	if err != nil {
-		log.Fatalf("dial websocket: %v", err)
+		log.Fatalf("load ABIs: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := subscription.Dial(ctx, *wsFlag, subscription.Options{Mode: mode, Interval: *pollFlag})
+	if err != nil {
+		log.Fatalf("dial rpc: %v", err)
 	}
 	defer client.Close()
 
 	contractAddress := common.HexToAddress(*addrFlag)
 	query := ethereum.FilterQuery{Addresses: []common.Address{contractAddress}}
 
-	logsCh := make(chan types.Log)
-	sub, err := client.SubscribeFilterLogs(ctx, query, logsCh)
+	cfg := logstream.Config{
+		Confirmations: *confirmationsFlag,
+		From:          *fromFlag,
+		OnError:       func(err error) { log.Printf("warn: %v", err) },
+	}
+	if *cursorFlag != "" {
+		cfg.Cursor = logstream.FileCursor(*cursorFlag)
+	}
+	stream, err := logstream.New(client, query, cfg)
 	if err != nil {
-		log.Fatalf("subscribe logs: %v", err)
+		log.Fatalf("open stream: %v", err)
 	}
-	defer sub.Unsubscribe()
 
-	log.Printf("listening for logs from %s over %s", contractAddress.Hex(), *wsFlag)
+	var relay *webhook.Relay
+	if *webhookFlag != "" {
+		relay, err = webhook.New(webhook.Config{
+			Endpoints:  webhook.ParseEndpoints(*webhookFlag, *secretFlag),
+			DeadLetter: *deadLetterFlag,
+		})
+		if err != nil {
+			log.Fatalf("start webhook relay: %v", err)
+		}
+		if *statusFlag != "" {
+			go func() {
+				log.Fatal(http.ListenAndServe(*statusFlag, http.StripPrefix("/status", relay.StatusHandler())))
+			}()
+		}
+	}
+
+	log.Printf("listening for logs from %s over %s (%d confirmations, %s mode)", contractAddress.Hex(), *wsFlag, *confirmationsFlag, mode)
 
-	for {
-		select {
-		case err := <-sub.Err():
-			log.Fatalf("subscription error: %v", err)
-		case event := <-logsCh:
-			fmt.Printf("[%s] block=%d tx=%s topics=%d data=%d bytes\n",
+	err = stream.Run(ctx, func(b logstream.Block) error {
+		status := ""
+		if b.Removed {
+			status = " REMOVED"
+		}
+		for _, event := range b.Logs {
+			rec := decoder.Decode(event)
+			fmt.Printf("[%s]%s block=%d tx=%s %s\n",
 				time.Now().Format(time.RFC3339),
+				status,
 				event.BlockNumber,
 				event.TxHash.Hex(),
-				len(event.Topics),
-				len(event.Data))
+				rec)
+			if relay != nil {
+				if err := relay.Send(rec); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if relay != nil {
+		// Give in-flight deliveries a moment; the rest goes to the dead-letter file.
+		closeCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := relay.Close(closeCtx); err != nil {
+			log.Printf("close webhook relay: %v", err)
 		}
+		cancel()
+		for _, st := range relay.Status() {
+			log.Printf("webhook %s: %d delivered, %d retries, %d dead-lettered", st.URL, st.Delivered, st.Retries, st.DeadLettered)
+		}
+	}
+	stats := stream.Stats()
+	log.Printf("stopped at block %d: %d blocks, %d logs, %d reorgs (%d blocks retracted, max depth %d), heads via %s, %d filters recreated",
+		stats.Next, stats.Blocks, stats.Logs, stats.Reorgs, stats.Retracted, stats.MaxDepth, client.Mode(), client.FiltersRecreated())
+	if err != nil && ctx.Err() == nil {
+		log.Fatalf("stream: %v", err)
 	}
 }
@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.20;
+
+interface IERC1155Receiver {
+    function onERC1155Received(address operator, address from, uint256 id, uint256 value, bytes calldata data)
+        external
+        returns (bytes4);
+
+    function onERC1155BatchReceived(
+        address operator,
+        address from,
+        uint256[] calldata ids,
+        uint256[] calldata values,
+        bytes calldata data
+    ) external returns (bytes4);
+}
+
+/// @title DemoMultiToken - a minimal ERC-1155 for local testing
+contract DemoMultiToken {
+    address public owner;
+    string private baseURI;
+
+    mapping(uint256 => mapping(address => uint256)) private balances;
+    mapping(address => mapping(address => bool)) private operatorApprovals;
+
+    event TransferSingle(address indexed operator, address indexed from, address indexed to, uint256 id, uint256 value);
+    event TransferBatch(
+        address indexed operator, address indexed from, address indexed to, uint256[] ids, uint256[] values
+    );
+    event ApprovalForAll(address indexed account, address indexed operator, bool approved);
+    event URI(string value, uint256 indexed id);
+
+    constructor(string memory baseURI_) {
+        owner = msg.sender;
+        baseURI = baseURI_;
+    }
+
+    modifier onlyOwner() {
+        require(msg.sender == owner, "DemoMultiToken: caller is not the owner");
+        _;
+    }
+
+    function supportsInterface(bytes4 interfaceId) external pure returns (bool) {
+        return interfaceId == 0x01ffc9a7 // ERC165
+            || interfaceId == 0xd9b67a26 // ERC1155
+            || interfaceId == 0x0e89341c; // ERC1155MetadataURI
+    }
+
+    function uri(uint256) external view returns (string memory) {
+        return baseURI;
+    }
+
+    function balanceOf(address account, uint256 id) public view returns (uint256) {
+        return balances[id][account];
+    }
+
+    function balanceOfBatch(address[] calldata accounts, uint256[] calldata ids)
+        external
+        view
+        returns (uint256[] memory batchBalances)
+    {
+        require(accounts.length == ids.length, "ERC1155: accounts and ids length mismatch");
+        batchBalances = new uint256[](accounts.length);
+        for (uint256 i = 0; i < accounts.length; i++) {
+            batchBalances[i] = balances[ids[i]][accounts[i]];
+        }
+    }
+
+    function isApprovedForAll(address account, address operator) public view returns (bool) {
+        return operatorApprovals[account][operator];
+    }
+
+    function setApprovalForAll(address operator, bool approved) external {
+        require(msg.sender != operator, "ERC1155: setting approval status for self");
+        operatorApprovals[msg.sender][operator] = approved;
+        emit ApprovalForAll(msg.sender, operator, approved);
+    }
+
+    function setURI(string calldata newURI, uint256 id) external onlyOwner {
+        baseURI = newURI;
+        emit URI(newURI, id);
+    }
+
+    function safeTransferFrom(address from, address to, uint256 id, uint256 value, bytes calldata data) external {
+        require(from == msg.sender || isApprovedForAll(from, msg.sender), "ERC1155: caller is not owner nor approved");
+        require(to != address(0), "ERC1155: transfer to the zero address");
+        _move(from, to, id, value);
+        emit TransferSingle(msg.sender, from, to, id, value);
+        _checkReceiver(from, to, id, value, data);
+    }
+
+    function safeBatchTransferFrom(
+        address from,
+        address to,
+        uint256[] calldata ids,
+        uint256[] calldata values,
+        bytes calldata data
+    ) external {
+        require(from == msg.sender || isApprovedForAll(from, msg.sender), "ERC1155: caller is not owner nor approved");
+        require(to != address(0), "ERC1155: transfer to the zero address");
+        require(ids.length == values.length, "ERC1155: ids and values length mismatch");
+        for (uint256 i = 0; i < ids.length; i++) {
+            _move(from, to, ids[i], values[i]);
+        }
+        emit TransferBatch(msg.sender, from, to, ids, values);
+        _checkBatchReceiver(from, to, ids, values, data);
+    }
+
+    function mint(address to, uint256 id, uint256 value) external onlyOwner {
+        require(to != address(0), "ERC1155: mint to the zero address");
+        balances[id][to] += value;
+        emit TransferSingle(msg.sender, address(0), to, id, value);
+        _checkReceiver(address(0), to, id, value, "");
+    }
+
+    function mintBatch(address to, uint256[] calldata ids, uint256[] calldata values) external onlyOwner {
+        require(to != address(0), "ERC1155: mint to the zero address");
+        require(ids.length == values.length, "ERC1155: ids and values length mismatch");
+        for (uint256 i = 0; i < ids.length; i++) {
+            balances[ids[i]][to] += values[i];
+        }
+        emit TransferBatch(msg.sender, address(0), to, ids, values);
+        _checkBatchReceiver(address(0), to, ids, values, "");
+    }
+
+    function _move(address from, address to, uint256 id, uint256 value) private {
+        uint256 fromBalance = balances[id][from];
+        require(fromBalance >= value, "ERC1155: insufficient balance for transfer");
+        balances[id][from] = fromBalance - value;
+        balances[id][to] += value;
+    }
+
+    function _checkReceiver(address from, address to, uint256 id, uint256 value, bytes memory data) private {
+        if (to.code.length == 0) {
+            return;
+        }
+        bytes4 retval = IERC1155Receiver(to).onERC1155Received(msg.sender, from, id, value, data);
+        require(retval == IERC1155Receiver.onERC1155Received.selector, "ERC1155: ERC1155Receiver rejected tokens");
+    }
+
+    function _checkBatchReceiver(
+        address from,
+        address to,
+        uint256[] calldata ids,
+        uint256[] calldata values,
+        bytes memory data
+    ) private {
+        if (to.code.length == 0) {
+            return;
+        }
+        bytes4 retval = IERC1155Receiver(to).onERC1155BatchReceived(msg.sender, from, ids, values, data);
+        require(
+            retval == IERC1155Receiver.onERC1155BatchReceived.selector, "ERC1155: ERC1155Receiver rejected tokens"
+        );
+    }
+}
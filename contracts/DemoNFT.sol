@@ -0,0 +1,184 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.20;
+
+interface IERC721Receiver {
+    function onERC721Received(address operator, address from, uint256 tokenId, bytes calldata data)
+        external
+        returns (bytes4);
+}
+
+/// @title DemoNFT - a minimal ERC-721 (with Metadata and Enumerable) for local testing
+contract DemoNFT {
+    string public name;
+    string public symbol;
+    string public baseURI;
+    address public owner;
+
+    mapping(uint256 => address) private owners;
+    mapping(address => uint256) private balances;
+    mapping(uint256 => address) private tokenApprovals;
+    mapping(address => mapping(address => bool)) private operatorApprovals;
+
+    uint256[] private allTokens;
+    mapping(uint256 => uint256) private allTokensIndex;
+    mapping(address => mapping(uint256 => uint256)) private ownedTokens;
+    mapping(uint256 => uint256) private ownedTokensIndex;
+
+    event Transfer(address indexed from, address indexed to, uint256 indexed tokenId);
+    event Approval(address indexed owner, address indexed approved, uint256 indexed tokenId);
+    event ApprovalForAll(address indexed owner, address indexed operator, bool approved);
+
+    constructor(string memory name_, string memory symbol_, string memory baseURI_) {
+        name = name_;
+        symbol = symbol_;
+        baseURI = baseURI_;
+        owner = msg.sender;
+    }
+
+    modifier onlyOwner() {
+        require(msg.sender == owner, "DemoNFT: caller is not the owner");
+        _;
+    }
+
+    function supportsInterface(bytes4 interfaceId) external pure returns (bool) {
+        return interfaceId == 0x01ffc9a7 // ERC165
+            || interfaceId == 0x80ac58cd // ERC721
+            || interfaceId == 0x5b5e139f // ERC721Metadata
+            || interfaceId == 0x780e9d63; // ERC721Enumerable
+    }
+
+    function balanceOf(address account) external view returns (uint256) {
+        require(account != address(0), "ERC721: zero address");
+        return balances[account];
+    }
+
+    function ownerOf(uint256 tokenId) public view returns (address) {
+        address tokenOwner = owners[tokenId];
+        require(tokenOwner != address(0), "ERC721: invalid token ID");
+        return tokenOwner;
+    }
+
+    function tokenURI(uint256 tokenId) external view returns (string memory) {
+        ownerOf(tokenId);
+        return string.concat(baseURI, _toString(tokenId));
+    }
+
+    function totalSupply() external view returns (uint256) {
+        return allTokens.length;
+    }
+
+    function tokenByIndex(uint256 index) external view returns (uint256) {
+        require(index < allTokens.length, "ERC721Enumerable: global index out of bounds");
+        return allTokens[index];
+    }
+
+    function tokenOfOwnerByIndex(address account, uint256 index) external view returns (uint256) {
+        require(index < balances[account], "ERC721Enumerable: owner index out of bounds");
+        return ownedTokens[account][index];
+    }
+
+    function getApproved(uint256 tokenId) public view returns (address) {
+        ownerOf(tokenId);
+        return tokenApprovals[tokenId];
+    }
+
+    function isApprovedForAll(address account, address operator) public view returns (bool) {
+        return operatorApprovals[account][operator];
+    }
+
+    function approve(address to, uint256 tokenId) external {
+        address tokenOwner = ownerOf(tokenId);
+        require(
+            msg.sender == tokenOwner || isApprovedForAll(tokenOwner, msg.sender),
+            "ERC721: caller is not token owner or approved for all"
+        );
+        tokenApprovals[tokenId] = to;
+        emit Approval(tokenOwner, to, tokenId);
+    }
+
+    function setApprovalForAll(address operator, bool approved) external {
+        operatorApprovals[msg.sender][operator] = approved;
+        emit ApprovalForAll(msg.sender, operator, approved);
+    }
+
+    function transferFrom(address from, address to, uint256 tokenId) public {
+        address tokenOwner = ownerOf(tokenId);
+        require(
+            msg.sender == tokenOwner || getApproved(tokenId) == msg.sender || isApprovedForAll(tokenOwner, msg.sender),
+            "ERC721: caller is not token owner or approved"
+        );
+        require(tokenOwner == from, "ERC721: transfer from incorrect owner");
+        require(to != address(0), "ERC721: transfer to the zero address");
+
+        delete tokenApprovals[tokenId];
+        _removeFromOwner(from, tokenId);
+        _addToOwner(to, tokenId);
+        emit Transfer(from, to, tokenId);
+    }
+
+    function safeTransferFrom(address from, address to, uint256 tokenId) external {
+        safeTransferFrom(from, to, tokenId, "");
+    }
+
+    function safeTransferFrom(address from, address to, uint256 tokenId, bytes memory data) public {
+        transferFrom(from, to, tokenId);
+        _checkReceiver(from, to, tokenId, data);
+    }
+
+    function mint(address to, uint256 tokenId) external onlyOwner {
+        require(to != address(0), "ERC721: mint to the zero address");
+        require(owners[tokenId] == address(0), "ERC721: token already minted");
+
+        allTokensIndex[tokenId] = allTokens.length;
+        allTokens.push(tokenId);
+        _addToOwner(to, tokenId);
+        emit Transfer(address(0), to, tokenId);
+    }
+
+    function _addToOwner(address to, uint256 tokenId) private {
+        uint256 length = balances[to];
+        ownedTokens[to][length] = tokenId;
+        ownedTokensIndex[tokenId] = length;
+        balances[to] = length + 1;
+        owners[tokenId] = to;
+    }
+
+    function _removeFromOwner(address from, uint256 tokenId) private {
+        uint256 lastIndex = balances[from] - 1;
+        uint256 tokenIndex = ownedTokensIndex[tokenId];
+        if (tokenIndex != lastIndex) {
+            uint256 lastTokenId = ownedTokens[from][lastIndex];
+            ownedTokens[from][tokenIndex] = lastTokenId;
+            ownedTokensIndex[lastTokenId] = tokenIndex;
+        }
+        delete ownedTokensIndex[tokenId];
+        delete ownedTokens[from][lastIndex];
+        balances[from] = lastIndex;
+        delete owners[tokenId];
+    }
+
+    function _checkReceiver(address from, address to, uint256 tokenId, bytes memory data) private {
+        if (to.code.length == 0) {
+            return;
+        }
+        bytes4 retval = IERC721Receiver(to).onERC721Received(msg.sender, from, tokenId, data);
+        require(retval == IERC721Receiver.onERC721Received.selector, "ERC721: transfer to non ERC721Receiver implementer");
+    }
+
+    function _toString(uint256 value) private pure returns (string memory) {
+        if (value == 0) {
+            return "0";
+        }
+        uint256 digits;
+        for (uint256 temp = value; temp != 0; temp /= 10) {
+            digits++;
+        }
+        bytes memory buffer = new bytes(digits);
+        while (value != 0) {
+            digits--;
+            buffer[digits] = bytes1(uint8(48 + (value % 10)));
+            value /= 10;
+        }
+        return string(buffer);
+    }
+}
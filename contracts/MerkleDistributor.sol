@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.20;
+
+interface IERC20Transfer {
+    function transfer(address to, uint256 amount) external returns (bool);
+}
+
+/// @title MerkleDistributor - pays out ERC-20 airdrops against a sorted-pair Merkle root
+/// @notice Leaves follow OpenZeppelin's StandardMerkleTree encoding:
+///         keccak256(bytes.concat(keccak256(abi.encode(account, amount)))).
+contract MerkleDistributor {
+    address public immutable token;
+    bytes32 public immutable merkleRoot;
+    mapping(address => bool) public isClaimed;
+
+    event Claimed(address indexed account, uint256 amount);
+
+    constructor(address token_, bytes32 merkleRoot_) {
+        token = token_;
+        merkleRoot = merkleRoot_;
+    }
+
+    function claim(address account, uint256 amount, bytes32[] calldata proof) external {
+        require(!isClaimed[account], "MerkleDistributor: already claimed");
+
+        bytes32 leaf = keccak256(bytes.concat(keccak256(abi.encode(account, amount))));
+        require(MerkleProof.verify(proof, merkleRoot, leaf), "MerkleDistributor: invalid proof");
+
+        isClaimed[account] = true;
+        require(IERC20Transfer(token).transfer(account, amount), "MerkleDistributor: transfer failed");
+        emit Claimed(account, amount);
+    }
+}
+
+/// @dev Same algorithm as OpenZeppelin's MerkleProof (commutative keccak256 pair hashing).
+library MerkleProof {
+    function verify(bytes32[] calldata proof, bytes32 root, bytes32 leaf) internal pure returns (bool) {
+        bytes32 computed = leaf;
+        for (uint256 i = 0; i < proof.length; i++) {
+            computed = _hashPair(computed, proof[i]);
+        }
+        return computed == root;
+    }
+
+    function _hashPair(bytes32 a, bytes32 b) private pure returns (bytes32) {
+        return a < b ? keccak256(abi.encodePacked(a, b)) : keccak256(abi.encodePacked(b, a));
+    }
+}
@@ -0,0 +1,275 @@
+// Package export writes chain data — blocks, transactions, receipts, logs
+// and decoded token transfers — to CSV, NDJSON or Parquet files for offline
+// analysis. Each entity has a fixed schema and its files are partitioned by
+// block range. A partition gets its final name only once it is complete, and
+// a checkpoint records the next block, so an interrupted export resumes
+// where it stopped.
+package export
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/obingo31/go-eth/logscan"
+	"github.com/obingo31/go-eth/parallel"
+)
+
+// Client is the part of ethclient.Client an export needs.
+type Client interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Config describes an export. Dir is required; zero fields otherwise take
+// the defaults.
+type Config struct {
+	Dir         string   // output directory, one subdirectory per entity
+	Entities    []string // default all of Entities
+	Format      string   // FormatCSV (default), FormatNDJSON or FormatParquet
+	Partition   uint64   // blocks per file, aligned to multiples of it (default 1000)
+	Concurrency int      // blocks fetched in parallel (default 8)
+	Checkpoint  string   // default Dir/checkpoint.json
+
+	// Progress, if set, is called after each partition is committed.
+	Progress func(Partition)
+}
+
+func (c Config) withDefaults() Config {
+	if len(c.Entities) == 0 {
+		c.Entities = Entities
+	}
+	if c.Format == "" {
+		c.Format = FormatCSV
+	}
+	if c.Partition == 0 {
+		c.Partition = 1000
+	}
+	if c.Concurrency <= 0 {
+		c.Concurrency = 8
+	}
+	if c.Checkpoint == "" {
+		c.Checkpoint = filepath.Join(c.Dir, "checkpoint.json")
+	}
+	return c
+}
+
+// key fingerprints what shapes the files, so a checkpoint is only resumed
+// by the same export.
+func (c Config) key() string {
+	entities := slices.Clone(c.Entities)
+	slices.Sort(entities)
+	return crypto.Keccak256Hash([]byte(fmt.Sprintf("%s|%s|%d", strings.Join(entities, ","), c.Format, c.Partition))).Hex()
+}
+
+// Partition reports one committed partition.
+type Partition struct {
+	From, To uint64
+	Rows     map[string]int // rows written per entity
+	Files    []string
+}
+
+// Run exports blocks from-to. When the checkpoint names a later block, the
+// export resumes there; blocks before it were already written.
+func Run(ctx context.Context, client Client, from, to uint64, cfg Config) error {
+	if cfg.Dir == "" {
+		return fmt.Errorf("no output directory")
+	}
+	cfg = cfg.withDefaults()
+	for _, e := range cfg.Entities {
+		if _, ok := rowTypes[e]; !ok {
+			return fmt.Errorf("unknown entity %q (want %s)", e, strings.Join(Entities, ", "))
+		}
+	}
+	ext, ok := extensions[cfg.Format]
+	if !ok {
+		return fmt.Errorf("unknown format %q (want csv, ndjson or parquet)", cfg.Format)
+	}
+
+	key := cfg.key()
+	cp, err := logscan.LoadCheckpoint(cfg.Checkpoint)
+	if err != nil {
+		return err
+	}
+	if cp != nil {
+		if cp.Key != key {
+			return fmt.Errorf("checkpoint %s belongs to an export with other entities, format or partition size", cfg.Checkpoint)
+		}
+		from = max(from, cp.Next)
+	}
+	if from > to {
+		return nil
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch chain ID: %w", err)
+	}
+	x := &exporter{
+		client:   client,
+		cfg:      cfg,
+		ext:      ext,
+		signer:   types.LatestSignerForChainID(chainID),
+		receipts: slices.ContainsFunc(cfg.Entities, func(e string) bool { return e != Blocks && e != Transactions }),
+	}
+	for start := from; start <= to; {
+		end := min((start/cfg.Partition+1)*cfg.Partition-1, to)
+		p, err := x.partition(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		if err := (logscan.Checkpoint{Key: key, Next: end + 1, Updated: time.Now().UTC()}).Save(cfg.Checkpoint); err != nil {
+			return err
+		}
+		if cfg.Progress != nil {
+			cfg.Progress(p)
+		}
+		start = end + 1
+	}
+	return nil
+}
+
+type exporter struct {
+	client   Client
+	cfg      Config
+	ext      string
+	signer   types.Signer
+	receipts bool // whether receipts must be fetched
+}
+
+// fetched is one block with its receipts (when needed).
+type fetched struct {
+	block    *types.Block
+	receipts []*types.Receipt
+}
+
+func (x *exporter) partition(ctx context.Context, from, to uint64) (Partition, error) {
+	p := Partition{From: from, To: to, Rows: make(map[string]int)}
+	files := make(map[string]*partFile, len(x.cfg.Entities))
+	discard := func() {
+		for _, f := range files {
+			f.discard()
+		}
+	}
+	for _, e := range x.cfg.Entities {
+		path := filepath.Join(x.cfg.Dir, e, fmt.Sprintf("%s-%09d-%09d%s", e, from, to, x.ext))
+		f, err := createPart(path, e, x.cfg.Format)
+		if err != nil {
+			discard()
+			return p, fmt.Errorf("create %s: %w", path, err)
+		}
+		files[e] = f
+	}
+
+	// Fetch in chunks so blocks are written in order while several are in
+	// flight.
+	chunk := uint64(x.cfg.Concurrency) * 4
+	for start := from; start <= to; start += chunk {
+		blocks, err := x.fetch(ctx, start, min(start+chunk-1, to))
+		if err != nil {
+			discard()
+			return p, err
+		}
+		for _, b := range blocks {
+			if err := x.write(files, p.Rows, b); err != nil {
+				discard()
+				return p, fmt.Errorf("block %d: %w", b.block.NumberU64(), err)
+			}
+		}
+	}
+
+	for _, e := range x.cfg.Entities {
+		if err := files[e].commit(); err != nil {
+			discard()
+			return p, fmt.Errorf("write %s: %w", files[e].path, err)
+		}
+		p.Files = append(p.Files, files[e].path)
+	}
+	return p, nil
+}
+
+func (x *exporter) fetch(ctx context.Context, from, to uint64) ([]fetched, error) {
+	out := make([]fetched, to-from+1)
+	err := parallel.Run(ctx, len(out), x.cfg.Concurrency, func(ctx context.Context, i int) error {
+		var err error
+		out[i], err = x.fetchBlock(ctx, from+uint64(i))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (x *exporter) fetchBlock(ctx context.Context, number uint64) (fetched, error) {
+	block, err := x.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		return fetched{}, fmt.Errorf("fetch block %d: %w", number, err)
+	}
+	f := fetched{block: block}
+	if !x.receipts || len(block.Transactions()) == 0 {
+		return f, nil
+	}
+	// Receipts are requested by hash so they belong to the block fetched
+	// even if the chain reorganises meanwhile.
+	if f.receipts, err = x.client.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(block.Hash(), false)); err != nil {
+		return fetched{}, fmt.Errorf("fetch receipts of block %d: %w", number, err)
+	}
+	if len(f.receipts) != len(block.Transactions()) {
+		return fetched{}, fmt.Errorf("block %d: %d receipts for %d transactions", number, len(f.receipts), len(block.Transactions()))
+	}
+	return f, nil
+}
+
+func (x *exporter) write(files map[string]*partFile, counts map[string]int, b fetched) error {
+	put := func(entity string, row any) error {
+		f, ok := files[entity]
+		if !ok {
+			return nil
+		}
+		counts[entity]++
+		return f.rows.Write(row)
+	}
+	if err := put(Blocks, blockRow(b.block)); err != nil {
+		return err
+	}
+	if _, ok := files[Transactions]; ok {
+		for i, tx := range b.block.Transactions() {
+			row, err := transactionRow(b.block, i, tx, x.signer)
+			if err != nil {
+				return err
+			}
+			if err := put(Transactions, row); err != nil {
+				return err
+			}
+		}
+	}
+	for _, r := range b.receipts {
+		if err := put(Receipts, receiptRow(r)); err != nil {
+			return err
+		}
+		for _, l := range r.Logs {
+			if err := put(Logs, logRow(l)); err != nil {
+				return err
+			}
+			if _, ok := files[Transfers]; !ok {
+				continue
+			}
+			for _, t := range transferRows(l) {
+				if err := put(Transfers, t); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
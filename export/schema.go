@@ -0,0 +1,320 @@
+package export
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Entity names.
+const (
+	Blocks       = "blocks"
+	Transactions = "transactions"
+	Receipts     = "receipts"
+	Logs         = "logs"
+	Transfers    = "transfers"
+)
+
+// Entities lists every entity in the order files are written.
+var Entities = []string{Blocks, Transactions, Receipts, Logs, Transfers}
+
+// rowTypes maps each entity to its row struct. Field tags name the columns
+// for every format; amounts in wei are decimal strings so no format loses
+// precision, and byte strings are 0x hex.
+var rowTypes = map[string]reflect.Type{
+	Blocks:       reflect.TypeOf(Block{}),
+	Transactions: reflect.TypeOf(Transaction{}),
+	Receipts:     reflect.TypeOf(Receipt{}),
+	Logs:         reflect.TypeOf(Log{}),
+	Transfers:    reflect.TypeOf(Transfer{}),
+}
+
+// Block is a row of the blocks entity.
+type Block struct {
+	Number        uint64 `parquet:"number" json:"number"`
+	Hash          string `parquet:"hash" json:"hash"`
+	ParentHash    string `parquet:"parent_hash" json:"parent_hash"`
+	Timestamp     uint64 `parquet:"timestamp" json:"timestamp"`
+	Miner         string `parquet:"miner" json:"miner"`
+	GasUsed       uint64 `parquet:"gas_used" json:"gas_used"`
+	GasLimit      uint64 `parquet:"gas_limit" json:"gas_limit"`
+	BaseFee       string `parquet:"base_fee" json:"base_fee"`
+	Size          uint64 `parquet:"size" json:"size"`
+	TxCount       int    `parquet:"tx_count" json:"tx_count"`
+	BlobGasUsed   uint64 `parquet:"blob_gas_used" json:"blob_gas_used"`
+	ExcessBlobGas uint64 `parquet:"excess_blob_gas" json:"excess_blob_gas"`
+	Withdrawals   int    `parquet:"withdrawals" json:"withdrawals"`
+}
+
+// Transaction is a row of the transactions entity.
+type Transaction struct {
+	BlockNumber    uint64 `parquet:"block_number" json:"block_number"`
+	Index          int    `parquet:"tx_index" json:"tx_index"`
+	Hash           string `parquet:"hash" json:"hash"`
+	Type           uint8  `parquet:"type" json:"type"`
+	From           string `parquet:"from" json:"from"`
+	To             string `parquet:"to" json:"to"` // empty for contract creations
+	Value          string `parquet:"value" json:"value"`
+	Nonce          uint64 `parquet:"nonce" json:"nonce"`
+	Gas            uint64 `parquet:"gas" json:"gas"`
+	GasPrice       string `parquet:"gas_price" json:"gas_price"`
+	MaxFee         string `parquet:"max_fee_per_gas" json:"max_fee_per_gas"`
+	MaxPriorityFee string `parquet:"max_priority_fee_per_gas" json:"max_priority_fee_per_gas"`
+	Input          string `parquet:"input" json:"input"`
+	BlobHashes     int    `parquet:"blob_hashes" json:"blob_hashes"`
+}
+
+// Receipt is a row of the receipts entity.
+type Receipt struct {
+	BlockNumber       uint64 `parquet:"block_number" json:"block_number"`
+	TxIndex           uint   `parquet:"tx_index" json:"tx_index"`
+	TxHash            string `parquet:"tx_hash" json:"tx_hash"`
+	Status            uint64 `parquet:"status" json:"status"`
+	GasUsed           uint64 `parquet:"gas_used" json:"gas_used"`
+	CumulativeGasUsed uint64 `parquet:"cumulative_gas_used" json:"cumulative_gas_used"`
+	EffectiveGasPrice string `parquet:"effective_gas_price" json:"effective_gas_price"`
+	ContractAddress   string `parquet:"contract_address" json:"contract_address"`
+	Logs              int    `parquet:"logs" json:"logs"`
+	BlobGasUsed       uint64 `parquet:"blob_gas_used" json:"blob_gas_used"`
+	BlobGasPrice      string `parquet:"blob_gas_price" json:"blob_gas_price"`
+}
+
+// Log is a row of the logs entity.
+type Log struct {
+	BlockNumber uint64 `parquet:"block_number" json:"block_number"`
+	TxIndex     uint   `parquet:"tx_index" json:"tx_index"`
+	TxHash      string `parquet:"tx_hash" json:"tx_hash"`
+	LogIndex    uint   `parquet:"log_index" json:"log_index"`
+	Address     string `parquet:"address" json:"address"`
+	Topic0      string `parquet:"topic0" json:"topic0"`
+	Topic1      string `parquet:"topic1" json:"topic1"`
+	Topic2      string `parquet:"topic2" json:"topic2"`
+	Topic3      string `parquet:"topic3" json:"topic3"`
+	Data        string `parquet:"data" json:"data"`
+}
+
+// Transfer is a row of the transfers entity: an ERC-20, ERC-721 or
+// ERC-1155 transfer decoded from its event. Batch transfers give one row
+// per token id.
+type Transfer struct {
+	BlockNumber uint64 `parquet:"block_number" json:"block_number"`
+	TxHash      string `parquet:"tx_hash" json:"tx_hash"`
+	LogIndex    uint   `parquet:"log_index" json:"log_index"`
+	Standard    string `parquet:"standard" json:"standard"` // erc20, erc721 or erc1155
+	Token       string `parquet:"token" json:"token"`
+	From        string `parquet:"from" json:"from"`
+	To          string `parquet:"to" json:"to"`
+	TokenID     string `parquet:"token_id" json:"token_id"` // empty for erc20
+	Amount      string `parquet:"amount" json:"amount"`     // 1 for erc721
+}
+
+// Columns returns the column names of entity in file order.
+func Columns(entity string) ([]string, error) {
+	t, ok := rowTypes[entity]
+	if !ok {
+		return nil, fmt.Errorf("unknown entity %q", entity)
+	}
+	columns := make([]string, t.NumField())
+	for i := range columns {
+		columns[i] = columnName(t.Field(i))
+	}
+	return columns, nil
+}
+
+// Schema returns the Parquet schema of entity.
+func Schema(entity string) (*parquet.Schema, error) {
+	t, ok := rowTypes[entity]
+	if !ok {
+		return nil, fmt.Errorf("unknown entity %q", entity)
+	}
+	return parquet.SchemaOf(reflect.New(t).Interface()), nil
+}
+
+func columnName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("parquet"), ",")
+	return name
+}
+
+// csvRecord formats a row struct as CSV fields in column order.
+func csvRecord(row any) []string {
+	v := reflect.ValueOf(row)
+	record := make([]string, v.NumField())
+	for i := range record {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.String:
+			record[i] = f.String()
+		case reflect.Int, reflect.Int64:
+			record[i] = strconv.FormatInt(f.Int(), 10)
+		default:
+			record[i] = strconv.FormatUint(f.Uint(), 10)
+		}
+	}
+	return record
+}
+
+// Token transfer events.
+var (
+	transferTopic       = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	transferSingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	transferBatchTopic  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+)
+
+var batchArgs = func() abi.Arguments {
+	uints, _ := abi.NewType("uint256[]", "", nil)
+	return abi.Arguments{{Type: uints}, {Type: uints}}
+}()
+
+func blockRow(b *types.Block) Block {
+	row := Block{
+		Number:      b.NumberU64(),
+		Hash:        b.Hash().Hex(),
+		ParentHash:  b.ParentHash().Hex(),
+		Timestamp:   b.Time(),
+		Miner:       b.Coinbase().Hex(),
+		GasUsed:     b.GasUsed(),
+		GasLimit:    b.GasLimit(),
+		BaseFee:     decimal(b.BaseFee()),
+		Size:        b.Size(),
+		TxCount:     len(b.Transactions()),
+		Withdrawals: len(b.Withdrawals()),
+	}
+	if h := b.Header(); h.BlobGasUsed != nil {
+		row.BlobGasUsed = *h.BlobGasUsed
+	}
+	if h := b.Header(); h.ExcessBlobGas != nil {
+		row.ExcessBlobGas = *h.ExcessBlobGas
+	}
+	return row
+}
+
+func transactionRow(b *types.Block, i int, tx *types.Transaction, signer types.Signer) (Transaction, error) {
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("recover sender of %s: %w", tx.Hash().Hex(), err)
+	}
+	row := Transaction{
+		BlockNumber: b.NumberU64(),
+		Index:       i,
+		Hash:        tx.Hash().Hex(),
+		Type:        tx.Type(),
+		From:        from.Hex(),
+		Value:       decimal(tx.Value()),
+		Nonce:       tx.Nonce(),
+		Gas:         tx.Gas(),
+		GasPrice:    decimal(tx.GasPrice()),
+		Input:       hexutil.Encode(tx.Data()),
+		BlobHashes:  len(tx.BlobHashes()),
+	}
+	if tx.To() != nil {
+		row.To = tx.To().Hex()
+	}
+	if tx.Type() >= types.DynamicFeeTxType {
+		row.MaxFee = decimal(tx.GasFeeCap())
+		row.MaxPriorityFee = decimal(tx.GasTipCap())
+	}
+	return row, nil
+}
+
+func receiptRow(r *types.Receipt) Receipt {
+	row := Receipt{
+		BlockNumber:       r.BlockNumber.Uint64(),
+		TxIndex:           r.TransactionIndex,
+		TxHash:            r.TxHash.Hex(),
+		Status:            r.Status,
+		GasUsed:           r.GasUsed,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		EffectiveGasPrice: decimal(r.EffectiveGasPrice),
+		Logs:              len(r.Logs),
+		BlobGasUsed:       r.BlobGasUsed,
+		BlobGasPrice:      decimal(r.BlobGasPrice),
+	}
+	if r.ContractAddress != (common.Address{}) {
+		row.ContractAddress = r.ContractAddress.Hex()
+	}
+	return row
+}
+
+func logRow(l *types.Log) Log {
+	row := Log{
+		BlockNumber: l.BlockNumber,
+		TxIndex:     l.TxIndex,
+		TxHash:      l.TxHash.Hex(),
+		LogIndex:    l.Index,
+		Address:     l.Address.Hex(),
+		Data:        hexutil.Encode(l.Data),
+	}
+	topics := []*string{&row.Topic0, &row.Topic1, &row.Topic2, &row.Topic3}
+	for i, t := range l.Topics {
+		if i < len(topics) {
+			*topics[i] = t.Hex()
+		}
+	}
+	return row
+}
+
+// transferRows decodes l if it is a token transfer. Logs that share a
+// signature but not the standard's layout yield no rows.
+func transferRows(l *types.Log) []Transfer {
+	if len(l.Topics) == 0 {
+		return nil
+	}
+	row := Transfer{BlockNumber: l.BlockNumber, TxHash: l.TxHash.Hex(), LogIndex: l.Index, Token: l.Address.Hex()}
+	switch {
+	case l.Topics[0] == transferTopic && len(l.Topics) == 3 && len(l.Data) == 32:
+		row.Standard = "erc20"
+		row.From, row.To = topicAddress(l.Topics[1]), topicAddress(l.Topics[2])
+		row.Amount = new(big.Int).SetBytes(l.Data).String()
+		return []Transfer{row}
+	case l.Topics[0] == transferTopic && len(l.Topics) == 4 && len(l.Data) == 0:
+		row.Standard = "erc721"
+		row.From, row.To = topicAddress(l.Topics[1]), topicAddress(l.Topics[2])
+		row.TokenID = l.Topics[3].Big().String()
+		row.Amount = "1"
+		return []Transfer{row}
+	case l.Topics[0] == transferSingleTopic && len(l.Topics) == 4 && len(l.Data) == 64:
+		row.Standard = "erc1155"
+		row.From, row.To = topicAddress(l.Topics[2]), topicAddress(l.Topics[3])
+		row.TokenID = new(big.Int).SetBytes(l.Data[:32]).String()
+		row.Amount = new(big.Int).SetBytes(l.Data[32:]).String()
+		return []Transfer{row}
+	case l.Topics[0] == transferBatchTopic && len(l.Topics) == 4:
+		values, err := batchArgs.Unpack(l.Data)
+		if err != nil {
+			return nil
+		}
+		ids, amounts := values[0].([]*big.Int), values[1].([]*big.Int)
+		if len(ids) != len(amounts) {
+			return nil
+		}
+		row.Standard = "erc1155"
+		row.From, row.To = topicAddress(l.Topics[2]), topicAddress(l.Topics[3])
+		rows := make([]Transfer, len(ids))
+		for i := range ids {
+			rows[i] = row
+			rows[i].TokenID, rows[i].Amount = ids[i].String(), amounts[i].String()
+		}
+		return rows
+	}
+	return nil
+}
+
+func topicAddress(t common.Hash) string {
+	return common.BytesToAddress(t.Bytes()).Hex()
+}
+
+func decimal(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
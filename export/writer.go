@@ -0,0 +1,120 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Formats accepted by Config.Format.
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// extensions maps each format to its file extension.
+var extensions = map[string]string{
+	FormatCSV:     ".csv",
+	FormatNDJSON:  ".ndjson",
+	FormatParquet: ".parquet",
+}
+
+// rowWriter writes the rows of one entity to one partition file.
+type rowWriter interface {
+	Write(row any) error
+	Close() error
+}
+
+// partFile is a partition file being written under a temporary name, so
+// that only complete partitions carry their final name.
+type partFile struct {
+	f    *os.File
+	buf  *bufio.Writer
+	path string
+	rows rowWriter
+}
+
+func createPart(path, entity, format string) (*partFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.Create(path + ".tmp")
+	if err != nil {
+		return nil, err
+	}
+	p := &partFile{f: f, buf: bufio.NewWriterSize(f, 1<<20), path: path}
+	switch format {
+	case FormatCSV:
+		columns, err := Columns(entity)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		w := csv.NewWriter(p.buf)
+		if err := w.Write(columns); err != nil {
+			f.Close()
+			return nil, err
+		}
+		p.rows = &csvWriter{w: w}
+	case FormatNDJSON:
+		p.rows = &ndjsonWriter{enc: json.NewEncoder(p.buf)}
+	case FormatParquet:
+		schema, err := Schema(entity)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		p.rows = &parquetWriter{w: parquet.NewWriter(p.buf, schema)}
+	default:
+		f.Close()
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	return p, nil
+}
+
+// commit flushes the file and gives it its final name.
+func (p *partFile) commit() error {
+	if err := p.rows.Close(); err != nil {
+		p.f.Close()
+		return err
+	}
+	if err := p.buf.Flush(); err != nil {
+		p.f.Close()
+		return err
+	}
+	if err := p.f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(p.path+".tmp", p.path)
+}
+
+// discard removes an unfinished file.
+func (p *partFile) discard() {
+	p.f.Close()
+	os.Remove(p.path + ".tmp")
+}
+
+type csvWriter struct{ w *csv.Writer }
+
+func (c *csvWriter) Write(row any) error { return c.w.Write(csvRecord(row)) }
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonWriter struct{ enc *json.Encoder }
+
+func (n *ndjsonWriter) Write(row any) error { return n.enc.Encode(row) }
+func (n *ndjsonWriter) Close() error        { return nil }
+
+type parquetWriter struct{ w *parquet.Writer }
+
+func (p *parquetWriter) Write(row any) error { return p.w.Write(row) }
+func (p *parquetWriter) Close() error        { return p.w.Close() }
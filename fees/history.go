@@ -0,0 +1,198 @@
+// Package fees summarises eth_feeHistory: the base fee trend over a window,
+// priority fee percentiles, and the highest base fee EIP-1559 allows a few
+// blocks ahead, which bounds the fee cap a transaction needs to stay
+// includable that long.
+package fees
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// maxHistoryBlocks is the most blocks geth returns per eth_feeHistory call.
+const maxHistoryBlocks = 1024
+
+// Block is one block of the window.
+type Block struct {
+	Number       uint64     `json:"number"`
+	BaseFee      *big.Int   `json:"baseFee"`
+	GasUsedRatio float64    `json:"gasUsedRatio"`
+	Rewards      []*big.Int `json:"rewards,omitempty"` // priority fee at each requested percentile
+}
+
+// History is a fee history window, oldest block first.
+type History struct {
+	Percentiles []float64
+	Blocks      []Block
+	NextBaseFee *big.Int // base fee of the block after the window
+}
+
+// Fetch requests the fee history of the blocks blocks ending at last (nil
+// for latest), in several calls when the window exceeds what nodes return
+// at once.
+func Fetch(ctx context.Context, client ethereum.FeeHistoryReader, blocks uint64, last *big.Int, percentiles []float64) (*History, error) {
+	if blocks == 0 {
+		return nil, fmt.Errorf("the window needs at least one block")
+	}
+	h := &History{Percentiles: percentiles}
+	remaining := blocks
+	for remaining > 0 {
+		count := min(remaining, maxHistoryBlocks)
+		res, err := client.FeeHistory(ctx, count, last, percentiles)
+		if err != nil {
+			return nil, fmt.Errorf("eth_feeHistory: %w", err)
+		}
+		if len(res.BaseFee) == 0 || res.OldestBlock == nil {
+			break
+		}
+		oldest := res.OldestBlock.Uint64()
+		chunk := make([]Block, len(res.GasUsedRatio))
+		for i := range chunk {
+			chunk[i] = Block{Number: oldest + uint64(i), BaseFee: res.BaseFee[i], GasUsedRatio: res.GasUsedRatio[i]}
+			if i < len(res.Reward) {
+				chunk[i].Rewards = res.Reward[i]
+			}
+		}
+		if h.NextBaseFee == nil {
+			h.NextBaseFee = res.BaseFee[len(res.BaseFee)-1]
+		}
+		h.Blocks = append(chunk, h.Blocks...)
+		remaining -= uint64(len(chunk))
+		if oldest == 0 || len(chunk) == 0 {
+			break
+		}
+		last = new(big.Int).SetUint64(oldest - 1)
+	}
+	if len(h.Blocks) == 0 {
+		return nil, fmt.Errorf("eth_feeHistory returned no blocks")
+	}
+	return h, nil
+}
+
+// Trend summarises the base fee over the window.
+type Trend struct {
+	First            *big.Int `json:"first"`
+	Last             *big.Int `json:"last"`
+	Min              *big.Int `json:"min"`
+	Max              *big.Int `json:"max"`
+	Mean             *big.Int `json:"mean"`
+	Change           float64  `json:"change"` // (Last - First) / First
+	MeanGasUsedRatio float64  `json:"meanGasUsedRatio"`
+	// Blocks whose base fee rose or fell from the previous one.
+	Rising  int `json:"rising"`
+	Falling int `json:"falling"`
+}
+
+// Trend computes the base fee trend.
+func (h *History) Trend() Trend {
+	t := Trend{First: h.Blocks[0].BaseFee, Last: h.Blocks[len(h.Blocks)-1].BaseFee, Min: h.Blocks[0].BaseFee, Max: h.Blocks[0].BaseFee}
+	sum := new(big.Int)
+	for i, b := range h.Blocks {
+		sum.Add(sum, b.BaseFee)
+		t.MeanGasUsedRatio += b.GasUsedRatio
+		if b.BaseFee.Cmp(t.Min) < 0 {
+			t.Min = b.BaseFee
+		}
+		if b.BaseFee.Cmp(t.Max) > 0 {
+			t.Max = b.BaseFee
+		}
+		if i > 0 {
+			switch b.BaseFee.Cmp(h.Blocks[i-1].BaseFee) {
+			case 1:
+				t.Rising++
+			case -1:
+				t.Falling++
+			}
+		}
+	}
+	n := int64(len(h.Blocks))
+	t.Mean = sum.Div(sum, big.NewInt(n))
+	t.MeanGasUsedRatio /= float64(n)
+	if t.First.Sign() > 0 {
+		diff, _ := new(big.Float).SetInt(new(big.Int).Sub(t.Last, t.First)).Float64()
+		first, _ := new(big.Float).SetInt(t.First).Float64()
+		t.Change = diff / first
+	}
+	return t
+}
+
+// Tip summarises one requested percentile across the window.
+type Tip struct {
+	Percentile float64  `json:"percentile"`
+	Median     *big.Int `json:"median"`
+	Min        *big.Int `json:"min"`
+	Max        *big.Int `json:"max"`
+}
+
+// Tips summarises the priority fee percentiles, skipping blocks without
+// transactions (which report zero rewards). It returns nil when no block in
+// the window had transactions.
+func (h *History) Tips() []Tip {
+	var tips []Tip
+	for p, percentile := range h.Percentiles {
+		var values []*big.Int
+		for _, b := range h.Blocks {
+			if b.GasUsedRatio == 0 || p >= len(b.Rewards) {
+				continue
+			}
+			values = append(values, b.Rewards[p])
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i].Cmp(values[j]) < 0 })
+		tips = append(tips, Tip{Percentile: percentile, Median: values[len(values)/2], Min: values[0], Max: values[len(values)-1]})
+	}
+	return tips
+}
+
+// MaxBaseFee returns the highest base fee possible blocks blocks after a
+// block with base fee next: every block can at most be full, which raises
+// the base fee by 1/8 (and by at least 1 wei).
+func MaxBaseFee(next *big.Int, blocks int) *big.Int {
+	fee := new(big.Int).Set(next)
+	step := new(big.Int)
+	denominator := big.NewInt(int64(params.DefaultBaseFeeChangeDenominator))
+	for i := 0; i < blocks; i++ {
+		step.Div(fee, denominator)
+		if step.Sign() == 0 {
+			step.SetInt64(1)
+		}
+		fee.Add(fee, step)
+	}
+	return fee
+}
+
+// Suggestion is a fee cap and tip for a dynamic-fee transaction.
+type Suggestion struct {
+	Blocks     int      `json:"blocks"`     // blocks the fee cap stays above the base fee, even if they are all full
+	Percentile float64  `json:"percentile"` // percentile the tip was taken from
+	GasTipCap  *big.Int `json:"maxPriorityFeePerGas"`
+	GasFeeCap  *big.Int `json:"maxFeePerGas"`
+}
+
+// Suggest picks the median tip at percentile (which must be one of the
+// requested percentiles) and a fee cap covering the worst-case base fee
+// blocks blocks ahead. When the window had no transactions, fallbackTip is
+// used.
+func (h *History) Suggest(blocks int, percentile float64, fallbackTip *big.Int) (Suggestion, error) {
+	s := Suggestion{Blocks: blocks, Percentile: percentile, GasTipCap: fallbackTip}
+	index := slices.Index(h.Percentiles, percentile)
+	if index < 0 {
+		return s, fmt.Errorf("percentile %g was not requested", percentile)
+	}
+	if tips := h.Tips(); tips != nil {
+		s.GasTipCap = tips[index].Median
+	}
+	if s.GasTipCap == nil {
+		return s, fmt.Errorf("no transactions in the window to take a tip from")
+	}
+	s.GasFeeCap = new(big.Int).Add(MaxBaseFee(h.NextBaseFee, blocks), s.GasTipCap)
+	return s, nil
+}
@@ -0,0 +1,196 @@
+// Package indexer keeps decoded events in an embedded LevelDB database with
+// secondary indexes by contract, event name and argument value.
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/ethdb/leveldb"
+
+	"github.com/obingo31/go-eth/logdecode"
+	"github.com/obingo31/go-eth/logstream"
+)
+
+// DefaultPath is where the index commands keep their database.
+const DefaultPath = "eventindex"
+
+// Key layout. pos is the 8-byte block number followed by the 4-byte log
+// index, so every index iterates in chain order.
+var (
+	eventPrefix    = []byte("e") // e + pos -> record JSON
+	contractPrefix = []byte("c") // c + address + pos
+	namePrefix     = []byte("n") // n + event + 0x00 + pos
+	argPrefix      = []byte("a") // a + arg name + 0x00 + value + 0x00 + pos
+	cursorKey      = []byte("m:cursor")
+)
+
+// maxIndexedValue bounds the argument values given a secondary index entry.
+const maxIndexedValue = 128
+
+// Index is an on-disk event index. It implements logstream.CursorStore:
+// records staged with Apply are committed atomically with the cursor by Save.
+type Index struct {
+	db ethdb.KeyValueStore
+
+	mu      sync.Mutex
+	pending ethdb.Batch
+}
+
+// Open opens (creating if needed) the database at path. A read-only open
+// fails while another process holds the database.
+func Open(path string, readOnly bool) (*Index, error) {
+	db, err := leveldb.New(path, 16, 16, "", readOnly)
+	if err != nil {
+		return nil, fmt.Errorf("open index %s: %w", path, err)
+	}
+	return &Index{db: db, pending: db.NewBatch()}, nil
+}
+
+// Close releases the database.
+func (ix *Index) Close() error {
+	return ix.db.Close()
+}
+
+// Load returns the stored stream cursor, or nil for a new index.
+func (ix *Index) Load() (*logstream.Cursor, error) {
+	if ok, err := ix.db.Has(cursorKey); err != nil || !ok {
+		return nil, err
+	}
+	data, err := ix.db.Get(cursorKey)
+	if err != nil {
+		return nil, err
+	}
+	var c logstream.Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parse cursor: %w", err)
+	}
+	return &c, nil
+}
+
+// Save commits everything staged since the last Save together with c.
+func (ix *Index) Save(c logstream.Cursor) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if err := ix.pending.Put(cursorKey, data); err != nil {
+		return err
+	}
+	if err := ix.pending.Write(); err != nil {
+		return fmt.Errorf("commit index batch: %w", err)
+	}
+	ix.pending.Reset()
+	return nil
+}
+
+// Apply stages recs for a delivered block, or removes everything indexed at
+// the block's height when b was retracted. It returns the number of records
+// added or removed.
+func (ix *Index) Apply(b logstream.Block, recs []logdecode.Record) (int, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if b.Removed {
+		return ix.deleteBlock(b.Number)
+	}
+	for _, rec := range recs {
+		if err := ix.put(rec); err != nil {
+			return 0, err
+		}
+	}
+	return len(recs), nil
+}
+
+func (ix *Index) put(rec logdecode.Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode record: %w", err)
+	}
+	pos := position(rec.BlockNumber, rec.LogIndex)
+	if err := ix.pending.Put(concat(eventPrefix, pos), data); err != nil {
+		return err
+	}
+	for _, key := range secondaryKeys(rec, pos) {
+		if err := ix.pending.Put(key, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ix *Index) deleteBlock(number uint64) (int, error) {
+	var block [8]byte
+	binary.BigEndian.PutUint64(block[:], number)
+
+	it := ix.db.NewIterator(eventPrefix, block[:])
+	defer it.Release()
+	removed := 0
+	for it.Next() {
+		pos := it.Key()[len(eventPrefix):]
+		if !bytes.HasPrefix(pos, block[:]) {
+			break
+		}
+		var rec logdecode.Record
+		if err := json.Unmarshal(it.Value(), &rec); err != nil {
+			return removed, fmt.Errorf("decode record: %w", err)
+		}
+		if err := ix.pending.Delete(common.CopyBytes(it.Key())); err != nil {
+			return removed, err
+		}
+		for _, key := range secondaryKeys(rec, common.CopyBytes(pos)) {
+			if err := ix.pending.Delete(key); err != nil {
+				return removed, err
+			}
+		}
+		removed++
+	}
+	return removed, it.Error()
+}
+
+func secondaryKeys(rec logdecode.Record, pos []byte) [][]byte {
+	keys := [][]byte{
+		concat(contractPrefix, rec.Address.Bytes(), pos),
+		concat(namePrefix, []byte(rec.Event), []byte{0}, pos),
+	}
+	for _, a := range rec.Args {
+		value, ok := indexValue(a.Value)
+		if !ok || a.Name == "" {
+			continue
+		}
+		keys = append(keys, concat(argPrefix, []byte(a.Name), []byte{0}, []byte(value), []byte{0}, pos))
+	}
+	return keys
+}
+
+// indexValue normalises scalar argument values for the argument index;
+// arrays, tuples and long values are not indexed.
+func indexValue(v interface{}) (string, bool) {
+	rendered := logdecode.FormatValue(v)
+	if strings.HasPrefix(rendered, "[") || strings.HasPrefix(rendered, "{") || len(rendered) > maxIndexedValue {
+		return "", false
+	}
+	return strings.ToLower(rendered), true
+}
+
+func position(block uint64, logIndex uint) []byte {
+	pos := make([]byte, 12)
+	binary.BigEndian.PutUint64(pos, block)
+	binary.BigEndian.PutUint32(pos[8:], uint32(logIndex))
+	return pos
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
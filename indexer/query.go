@@ -0,0 +1,221 @@
+package indexer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/obingo31/go-eth/logdecode"
+)
+
+// Filter selects indexed events. Zero fields match everything; To == 0
+// means no upper bound.
+type Filter struct {
+	Contract *common.Address
+	Event    string
+	Args     map[string]string // argument name -> rendered value (case-insensitive)
+	From     uint64
+	To       uint64
+	Limit    int
+}
+
+// ParseArgs parses comma-separated name=value pairs.
+func ParseArgs(list string) (map[string]string, error) {
+	args := make(map[string]string)
+	for _, pair := range strings.Split(list, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid argument filter %q (want name=value)", pair)
+		}
+		args[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return args, nil
+}
+
+// Values encodes f as URL query parameters.
+func (f Filter) Values() url.Values {
+	v := url.Values{}
+	if f.Contract != nil {
+		v.Set("contract", f.Contract.Hex())
+	}
+	if f.Event != "" {
+		v.Set("event", f.Event)
+	}
+	names := make([]string, 0, len(f.Args))
+	for name := range f.Args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v.Add("arg", name+"="+f.Args[name])
+	}
+	if f.From > 0 {
+		v.Set("from", strconv.FormatUint(f.From, 10))
+	}
+	if f.To > 0 {
+		v.Set("to", strconv.FormatUint(f.To, 10))
+	}
+	if f.Limit > 0 {
+		v.Set("limit", strconv.Itoa(f.Limit))
+	}
+	return v
+}
+
+// ParseFilter decodes the parameters produced by Values.
+func ParseFilter(v url.Values) (Filter, error) {
+	var f Filter
+	var err error
+	if c := v.Get("contract"); c != "" {
+		if !common.IsHexAddress(c) {
+			return f, fmt.Errorf("invalid contract %q", c)
+		}
+		addr := common.HexToAddress(c)
+		f.Contract = &addr
+	}
+	f.Event = v.Get("event")
+	if f.Args, err = ParseArgs(strings.Join(v["arg"], ",")); err != nil {
+		return f, err
+	}
+	for name, dst := range map[string]*uint64{"from": &f.From, "to": &f.To} {
+		if s := v.Get(name); s != "" {
+			if *dst, err = strconv.ParseUint(s, 10, 64); err != nil {
+				return f, fmt.Errorf("invalid %s %q", name, s)
+			}
+		}
+	}
+	if s := v.Get("limit"); s != "" {
+		if f.Limit, err = strconv.Atoi(s); err != nil {
+			return f, fmt.Errorf("invalid limit %q", s)
+		}
+	}
+	return f, nil
+}
+
+// Query returns the events matching f in chain order. The most selective
+// available index (argument, contract, event name, then block range) drives
+// the iteration; remaining conditions are checked on each record.
+func (ix *Index) Query(f Filter) ([]logdecode.Record, error) {
+	var prefix []byte
+	switch {
+	case len(f.Args) > 0:
+		names := make([]string, 0, len(f.Args))
+		for name := range f.Args {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		prefix = concat(argPrefix, []byte(names[0]), []byte{0}, []byte(strings.ToLower(f.Args[names[0]])), []byte{0})
+	case f.Contract != nil:
+		prefix = concat(contractPrefix, f.Contract.Bytes())
+	case f.Event != "":
+		prefix = concat(namePrefix, []byte(f.Event), []byte{0})
+	default:
+		prefix = eventPrefix
+	}
+
+	var start [8]byte
+	binary.BigEndian.PutUint64(start[:], f.From)
+	it := ix.db.NewIterator(prefix, start[:])
+	defer it.Release()
+
+	var out []logdecode.Record
+	for it.Next() {
+		pos := it.Key()[len(prefix):]
+		if len(pos) != 12 {
+			continue
+		}
+		if f.To > 0 && binary.BigEndian.Uint64(pos) > f.To {
+			break
+		}
+		data := it.Value()
+		if !bytes.Equal(prefix, eventPrefix) {
+			var err error
+			if data, err = ix.db.Get(concat(eventPrefix, pos)); err != nil {
+				return nil, fmt.Errorf("load event: %w", err)
+			}
+		}
+		var rec logdecode.Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return nil, fmt.Errorf("decode event: %w", err)
+		}
+		if !f.matches(rec) {
+			continue
+		}
+		out = append(out, rec)
+		if f.Limit > 0 && len(out) >= f.Limit {
+			break
+		}
+	}
+	return out, it.Error()
+}
+
+func (f Filter) matches(rec logdecode.Record) bool {
+	if f.Contract != nil && rec.Address != *f.Contract {
+		return false
+	}
+	if f.Event != "" && rec.Event != f.Event {
+		return false
+	}
+	for name, want := range f.Args {
+		value, ok := rec.Arg(name)
+		if !ok {
+			return false
+		}
+		if got, ok := indexValue(value); !ok || got != strings.ToLower(want) {
+			return false
+		}
+	}
+	return true
+}
+
+// Handler serves GET /events (Filter as query parameters, NDJSON response)
+// and GET /status (the stream cursor) so the index can be queried while the
+// indexing process holds the database.
+func (ix *Index) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
+		f, err := ParseFilter(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		recs, err := ix.Query(f)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, rec := range recs {
+			if err := enc.Encode(rec); err != nil {
+				return
+			}
+		}
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		cursor, err := ix.Load()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		status := struct {
+			Next uint64 `json:"next"`
+		}{}
+		if cursor != nil {
+			status.Next = cursor.Next
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	})
+	return mux
+}
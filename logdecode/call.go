@@ -0,0 +1,122 @@
+package logdecode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Call is decoded transaction calldata (or, when Method is empty, calldata
+// that matched nothing).
+type Call struct {
+	Selector   hexutil.Bytes `json:"selector,omitempty"`
+	Method     string        `json:"method,omitempty"`
+	Signature  string        `json:"signature,omitempty"`
+	Source     string        `json:"source,omitempty"`
+	Guessed    bool          `json:"guessed,omitempty"`
+	Args       []Arg         `json:"args,omitempty"`
+	Candidates []string      `json:"candidates,omitempty"`
+	Data       hexutil.Bytes `json:"data,omitempty"`
+}
+
+// Decoded reports whether the calldata matched a known function.
+func (c Call) Decoded() bool {
+	return c.Method != ""
+}
+
+// String renders the call on one line, e.g. "transfer(to=0x.., value=100)".
+func (c Call) String() string {
+	if c.Decoded() {
+		return c.Method + "(" + formatArgs(c.Args) + ")"
+	}
+	if len(c.Selector) == 0 {
+		return fmt.Sprintf("unknown(data=%d bytes)", len(c.Data))
+	}
+	out := fmt.Sprintf("unknown(selector=%s, data=%d bytes", c.Selector, len(c.Data))
+	if len(c.Candidates) > 0 {
+		out += ", candidates=" + strings.Join(c.Candidates, "|")
+	}
+	return out + ")"
+}
+
+func (d *Decoder) addMethods(source string, parsed abi.ABI) {
+	names := make([]string, 0, len(parsed.Methods))
+	for name := range parsed.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		m := parsed.Methods[name]
+		key := "function " + m.Sig
+		if d.seen[key] {
+			continue
+		}
+		d.seen[key] = true
+		sel := [4]byte(m.ID)
+		d.bySelector[sel] = append(d.bySelector[sel], &method{Method: m, source: source})
+	}
+}
+
+// DecodeCall matches calldata against the registered functions by selector,
+// then against the signature database. Empty calldata (a plain transfer)
+// comes back as an undecoded Call without a selector.
+func (d *Decoder) DecodeCall(data []byte) Call {
+	if len(data) < 4 {
+		return Call{Data: data}
+	}
+	sel := [4]byte(data[:4])
+	call := Call{Selector: sel[:]}
+	for _, m := range d.bySelector[sel] {
+		if args, err := decodeInputs(m.Inputs, data[4:]); err == nil {
+			call.Method, call.Signature, call.Source, call.Args = m.RawName, m.Sig, m.source, args
+			return call
+		}
+	}
+
+	call.Data = data
+	if d.sigs == nil {
+		return call
+	}
+	var fits []string
+	var fitArgs []Arg
+	var fitSource string
+	for _, sig := range d.sigs.Function(sel) {
+		call.Candidates = append(call.Candidates, sig.Text)
+		inputs, err := sig.Inputs()
+		if err != nil || !exactEncoding(inputs, data[4:]) {
+			continue
+		}
+		args, err := decodeInputs(inputs, data[4:])
+		if err != nil {
+			continue
+		}
+		fits = append(fits, sig.Text)
+		fitArgs, fitSource = args, "sigdb:"+sig.Source
+	}
+	switch len(fits) {
+	case 0:
+	case 1:
+		call.Method = strings.SplitN(fits[0], "(", 2)[0]
+		call.Signature, call.Source, call.Args, call.Guessed = fits[0], fitSource, fitArgs, true
+		call.Candidates, call.Data = nil, nil
+	default:
+		call.Candidates = fits
+	}
+	return call
+}
+
+func decodeInputs(inputs abi.Arguments, data []byte) ([]Arg, error) {
+	values, err := inputs.UnpackValues(data)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]Arg, len(inputs))
+	for i, in := range inputs {
+		args[i] = Arg{Name: in.Name, Type: in.Type.String(), Value: values[i]}
+	}
+	return args, nil
+}
@@ -0,0 +1,330 @@
+// Package logdecode turns raw logs (and calldata) into structured records
+// using any number of contract ABIs, instead of per-event structs and
+// hand-computed topics. Logs and calls no ABI describes are labelled from a
+// signature database when one is attached.
+package logdecode
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	store "github.com/obingo31/go-eth/contracts"
+	"github.com/obingo31/go-eth/merkle"
+	"github.com/obingo31/go-eth/sigdb"
+	"github.com/obingo31/go-eth/token"
+)
+
+// DefaultPatterns are the ABI files shipped with the repository.
+const DefaultPatterns = "build/*.abi,contracts/*.abi"
+
+// event is one ABI event together with the file it came from.
+type event struct {
+	abi.Event
+	source  string
+	indexed int
+}
+
+// method is one ABI function together with the file it came from.
+type method struct {
+	abi.Method
+	source string
+}
+
+// Decoder indexes events by topic0 (and anonymous events by indexed arity)
+// and functions by selector, and decodes logs and calldata against them.
+type Decoder struct {
+	byTopic    map[common.Hash][]*event
+	anonymous  []*event
+	seen       map[string]bool
+	bySelector map[[4]byte][]*method
+	sigs       *sigdb.DB
+}
+
+// New returns an empty decoder.
+func New() *Decoder {
+	return &Decoder{
+		byTopic:    make(map[common.Hash][]*event),
+		seen:       make(map[string]bool),
+		bySelector: make(map[[4]byte][]*method),
+	}
+}
+
+// NewDefault returns a decoder preloaded with the generated bindings and the
+// ABI files matched by patterns (comma-separated globs or directories), and
+// falling back to the signature database at sigdb.DefaultPath if one has
+// been built.
+func NewDefault(patterns string) (*Decoder, error) {
+	d := New()
+	if err := d.AddBindings(); err != nil {
+		return nil, err
+	}
+	if _, err := d.LoadFiles(patterns); err != nil {
+		return nil, err
+	}
+	sigs, err := sigdb.Open(sigdb.DefaultPath)
+	if err != nil {
+		return nil, fmt.Errorf("open signature database: %w", err)
+	}
+	d.UseSignatures(sigs)
+	return d, nil
+}
+
+// UseSignatures attaches a signature database consulted for logs and calls
+// that match no registered ABI.
+func (d *Decoder) UseSignatures(db *sigdb.DB) {
+	d.sigs = db
+}
+
+// Bindings are the ABIs of the repository's abigen bindings.
+var Bindings = []struct{ Name, ABI string }{
+	{"token.Token", token.TokenABI},
+	{"token.ERC721", token.ERC721ABI},
+	{"token.ERC1155", token.ERC1155ABI},
+	{"store.Store", store.StoreABI},
+	{"merkle.Distributor", merkle.DistributorABI},
+}
+
+// AddBindings registers the ABIs of the repository's abigen bindings.
+func (d *Decoder) AddBindings() error {
+	for _, b := range Bindings {
+		if err := d.AddJSON(b.Name, strings.NewReader(b.ABI)); err != nil {
+			return fmt.Errorf("%s: %w", b.Name, err)
+		}
+	}
+	return nil
+}
+
+// AddJSON registers the events of an ABI. Both a bare ABI array and a build
+// artifact object with an "abi" field are accepted.
+func (d *Decoder) AddJSON(source string, r io.Reader) error {
+	parsed, err := sigdb.ReadABI(r)
+	if err != nil {
+		return err
+	}
+	d.AddABI(source, parsed)
+	return nil
+}
+
+// AddABI registers the events and functions of an already parsed ABI.
+// Entries identical to one already known (same signature and, for events,
+// indexed layout) are skipped, so loading overlapping ABIs is harmless.
+func (d *Decoder) AddABI(source string, parsed abi.ABI) {
+	d.addMethods(source, parsed)
+
+	names := make([]string, 0, len(parsed.Events))
+	for name := range parsed.Events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ev := parsed.Events[name]
+		key := layoutKey(ev)
+		if d.seen[key] {
+			continue
+		}
+		d.seen[key] = true
+
+		e := &event{Event: ev, source: source}
+		for _, in := range ev.Inputs {
+			if in.Indexed {
+				e.indexed++
+			}
+		}
+		if ev.Anonymous {
+			d.anonymous = append(d.anonymous, e)
+		} else {
+			d.byTopic[ev.ID] = append(d.byTopic[ev.ID], e)
+		}
+	}
+}
+
+// LoadFiles registers every ABI matched by patterns, a comma-separated list
+// of globs, files or directories (directories contribute *.abi and *.json).
+// It returns the number of files loaded.
+func (d *Decoder) LoadFiles(patterns string) (int, error) {
+	files, err := sigdb.ExpandPatterns(patterns)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			return 0, err
+		}
+		err = d.AddJSON(filepath.Base(path), f)
+		f.Close()
+		if err != nil {
+			return 0, fmt.Errorf("load %s: %w", path, err)
+		}
+	}
+	return len(files), nil
+}
+
+// Events returns the number of distinct events known to the decoder.
+func (d *Decoder) Events() int {
+	n := len(d.anonymous)
+	for _, events := range d.byTopic {
+		n += len(events)
+	}
+	return n
+}
+
+// Knows reports whether any registered event uses topic0.
+func (d *Decoder) Knows(topic0 common.Hash) bool {
+	return len(d.byTopic[topic0]) > 0
+}
+
+// Decode matches l against the registered events. Named events are looked up
+// by topic0 and disambiguated by their number of indexed arguments (ERC-20
+// and ERC-721 Transfer share a topic0); anonymous events are tried when no
+// named event fits. The signature database comes last. Logs that match
+// nothing come back with Event == "".
+func (d *Decoder) Decode(l types.Log) Record {
+	rec := newRecord(l)
+	if len(l.Topics) > 0 {
+		for _, ev := range d.byTopic[l.Topics[0]] {
+			if ev.indexed != len(l.Topics)-1 {
+				continue
+			}
+			if args, err := decodeArgs(ev, l.Topics[1:], l.Data); err == nil {
+				rec.setEvent(ev, args)
+				return rec
+			}
+		}
+	}
+	for _, ev := range d.anonymous {
+		if ev.indexed != len(l.Topics) {
+			continue
+		}
+		if args, err := decodeArgs(ev, l.Topics, l.Data); err == nil {
+			rec.setEvent(ev, args)
+			return rec
+		}
+	}
+	if d.sigs != nil && len(l.Topics) > 0 {
+		d.guessEvent(&rec, l)
+	}
+	return rec
+}
+
+// guessEvent decodes l with the text signatures registered for its topic0.
+// Text signatures do not say which parameters are indexed, so the leading
+// ones are assumed to fill the topics, and a guess only counts if the data
+// re-encodes to exactly the bytes in the log. A single fitting signature
+// decodes the log; otherwise the candidates are recorded.
+func (d *Decoder) guessEvent(rec *Record, l types.Log) {
+	var fits []*event
+	var names []string
+	for _, sig := range d.sigs.Event(l.Topics[0]) {
+		names = append(names, sig.Text)
+		inputs, err := sig.Inputs()
+		if err != nil || len(inputs) < len(l.Topics)-1 {
+			continue
+		}
+		for i := range inputs {
+			inputs[i].Indexed = i < len(l.Topics)-1
+		}
+		ev := &event{
+			Event:   abi.NewEvent(sig.Name(), sig.Name(), false, inputs),
+			source:  "sigdb:" + sig.Source,
+			indexed: len(l.Topics) - 1,
+		}
+		if exactEncoding(ev.Inputs.NonIndexed(), l.Data) {
+			fits = append(fits, ev)
+		}
+	}
+	if len(fits) == 1 {
+		if args, err := decodeArgs(fits[0], l.Topics[1:], l.Data); err == nil {
+			rec.setEvent(fits[0], args)
+			rec.Guessed = true
+			return
+		}
+	}
+	if len(fits) > 1 {
+		names = names[:0]
+		for _, ev := range fits {
+			names = append(names, ev.Sig)
+		}
+	}
+	rec.Candidates = names
+}
+
+// exactEncoding reports whether data is the canonical ABI encoding of some
+// values of args.
+func exactEncoding(args abi.Arguments, data []byte) bool {
+	values, err := args.UnpackValues(data)
+	if err != nil {
+		return false
+	}
+	packed, err := args.Pack(values...)
+	return err == nil && bytes.Equal(packed, data)
+}
+
+func decodeArgs(ev *event, topics []common.Hash, data []byte) ([]Arg, error) {
+	values, err := ev.Inputs.NonIndexed().UnpackValues(data)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]Arg, 0, len(ev.Inputs))
+	topicIdx, valueIdx := 0, 0
+	for _, in := range ev.Inputs {
+		arg := Arg{Name: in.Name, Type: in.Type.String(), Indexed: in.Indexed}
+		if in.Indexed {
+			topic := topics[topicIdx]
+			topicIdx++
+			if isHashedInTopic(in.Type) {
+				// Only keccak256 of the encoded value is stored in the topic.
+				arg.Hashed = true
+				arg.Value = topic
+			} else {
+				v, err := abi.Arguments{{Type: in.Type}}.Unpack(topic.Bytes())
+				if err != nil {
+					return nil, fmt.Errorf("indexed %s: %w", in.Name, err)
+				}
+				arg.Value = v[0]
+			}
+		} else {
+			arg.Value = values[valueIdx]
+			valueIdx++
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+func isHashedInTopic(t abi.Type) bool {
+	switch t.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return true
+	}
+	return false
+}
+
+// layoutKey identifies an event by signature plus which inputs are indexed.
+func layoutKey(ev abi.Event) string {
+	var b strings.Builder
+	b.WriteString(ev.Sig)
+	if ev.Anonymous {
+		b.WriteString(" anonymous")
+	}
+	for _, in := range ev.Inputs {
+		if in.Indexed {
+			b.WriteByte('i')
+		} else {
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,211 @@
+package logdecode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Arg is one decoded event parameter. Value holds the native go-ethereum
+// type (common.Address, *big.Int, []byte, ...); Hashed marks indexed dynamic
+// values for which only the keccak256 topic is available.
+type Arg struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Indexed bool        `json:"indexed,omitempty"`
+	Hashed  bool        `json:"hashed,omitempty"`
+	Value   interface{} `json:"-"`
+}
+
+// MarshalJSON renders Value in a JSON-safe form (big integers as decimal
+// strings, byte slices as 0x hex).
+func (a Arg) MarshalJSON() ([]byte, error) {
+	type plain Arg
+	return json.Marshal(struct {
+		plain
+		Value interface{} `json:"value"`
+	}{plain(a), jsonValue(a.Value)})
+}
+
+// UnmarshalJSON restores an Arg written by MarshalJSON. Value keeps its
+// rendered JSON form (strings for numbers, addresses and bytes).
+func (a *Arg) UnmarshalJSON(data []byte) error {
+	type plain Arg
+	var v struct {
+		plain
+		Value interface{} `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*a = Arg(v.plain)
+	a.Value = v.Value
+	return nil
+}
+
+// Record is a decoded (or, when Event is empty, undecodable) log.
+type Record struct {
+	Address     common.Address `json:"address"`
+	BlockNumber uint64         `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"txHash"`
+	TxIndex     uint           `json:"txIndex"`
+	LogIndex    uint           `json:"logIndex"`
+	Removed     bool           `json:"removed,omitempty"`
+	Event       string         `json:"event,omitempty"`
+	Signature   string         `json:"signature,omitempty"`
+	Anonymous   bool           `json:"anonymous,omitempty"`
+	Source      string         `json:"source,omitempty"`
+	Guessed     bool           `json:"guessed,omitempty"`
+	Args        []Arg          `json:"args,omitempty"`
+	Candidates  []string       `json:"candidates,omitempty"`
+	Topics      []common.Hash  `json:"topics,omitempty"`
+	Data        hexutil.Bytes  `json:"data,omitempty"`
+}
+
+func newRecord(l types.Log) Record {
+	return Record{
+		Address:     l.Address,
+		BlockNumber: l.BlockNumber,
+		BlockHash:   l.BlockHash,
+		TxHash:      l.TxHash,
+		TxIndex:     l.TxIndex,
+		LogIndex:    l.Index,
+		Removed:     l.Removed,
+		Topics:      l.Topics,
+		Data:        l.Data,
+	}
+}
+
+func (r *Record) setEvent(ev *event, args []Arg) {
+	r.Event = ev.RawName
+	r.Signature = ev.Sig
+	r.Anonymous = ev.Anonymous
+	r.Source = ev.source
+	r.Args = args
+	// Raw topics and data are only kept for logs we could not decode.
+	r.Topics = nil
+	r.Data = nil
+}
+
+// Decoded reports whether the log matched a known event. Guessed records
+// were decoded from a signature database entry alone, so their parameters
+// are unnamed and the indexed layout is inferred.
+func (r Record) Decoded() bool {
+	return r.Event != ""
+}
+
+// Arg returns the value of the named parameter.
+func (r Record) Arg(name string) (interface{}, bool) {
+	for _, a := range r.Args {
+		if a.Name == name {
+			return a.Value, true
+		}
+	}
+	return nil, false
+}
+
+// String renders the record on one line, e.g.
+// "Transfer(from=0x.., to=0x.., value=100)".
+func (r Record) String() string {
+	if !r.Decoded() {
+		topic0 := "none"
+		if len(r.Topics) > 0 {
+			topic0 = r.Topics[0].Hex()
+		}
+		out := fmt.Sprintf("unknown(topic0=%s, topics=%d, data=%d bytes", topic0, len(r.Topics), len(r.Data))
+		if len(r.Candidates) > 0 {
+			out += ", candidates=" + strings.Join(r.Candidates, "|")
+		}
+		return out + ")"
+	}
+	return r.Event + "(" + formatArgs(r.Args) + ")"
+}
+
+// formatArgs renders "name=value" pairs, naming unnamed parameters argN.
+func formatArgs(args []Arg) string {
+	parts := make([]string, len(args))
+	for i, a := range args {
+		name := a.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		parts[i] = name + "=" + FormatValue(a.Value)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatValue renders a decoded ABI value as text.
+func FormatValue(v interface{}) string {
+	switch x := jsonValue(v).(type) {
+	case string:
+		return x
+	default:
+		out, err := json.Marshal(x)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(out)
+	}
+}
+
+// jsonValue converts decoded ABI values into JSON-friendly equivalents.
+func jsonValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case common.Address:
+		return x.Hex()
+	case common.Hash:
+		return x.Hex()
+	case *big.Int:
+		return x.String()
+	case []byte:
+		return hexutil.Encode(x)
+	case string, bool:
+		return x
+	case uint8, uint16, uint32, uint64, int8, int16, int32, int64:
+		return fmt.Sprint(x)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array:
+		// Fixed-size byte arrays (bytesN) render as hex; other arrays as lists.
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		out := make([]interface{}, rv.Len())
+		for i := range out {
+			out[i] = jsonValue(rv.Index(i).Interface())
+		}
+		return out
+	case reflect.Struct:
+		out := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			field := rv.Type().Field(i)
+			name := field.Tag.Get("json")
+			if name == "" {
+				name = field.Name
+			}
+			out[name] = jsonValue(rv.Field(i).Interface())
+		}
+		return out
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return jsonValue(rv.Elem().Interface())
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,176 @@
+package logscan
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// HeaderReader fetches headers for ScanBloom. When it also has a
+// Client() *rpc.Client method, as *ethclient.Client does, headers are
+// fetched in JSON-RPC batches.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// BloomMatches reports whether a block with this logsBloom may contain a log
+// matching q: one of its addresses (if any) and, for every topic position
+// that is not a wildcard, one of the listed topics. Blooms give false
+// positives but never false negatives.
+func BloomMatches(bloom types.Bloom, q ethereum.FilterQuery) bool {
+	if bloom == (types.Bloom{}) {
+		return false
+	}
+	if len(q.Addresses) > 0 {
+		found := false
+		for _, addr := range q.Addresses {
+			if types.BloomLookup(bloom, addr) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, position := range q.Topics {
+		if len(position) == 0 {
+			continue
+		}
+		found := false
+		for _, topic := range position {
+			if types.BloomLookup(bloom, topic) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ScanBloom is Scan for rare events: it fetches only headers, tests each
+// logsBloom against q locally and requests logs (by block hash) just for
+// the candidate blocks. Chunks are BloomBatch blocks; ordering,
+// concurrency and checkpoints behave as in Scan.
+func (s *Scanner) ScanBloom(ctx context.Context, headers HeaderReader, q ethereum.FilterQuery, from, to uint64, fn Handler) error {
+	size := func() uint64 { return s.cfg.BloomBatch }
+	return s.run(ctx, q, from, to, size, func(ctx context.Context, from, to uint64) ([]types.Log, error) {
+		return s.fetchBloom(ctx, headers, q, from, to)
+	}, fn)
+}
+
+func (s *Scanner) fetchBloom(ctx context.Context, headers HeaderReader, q ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	var batch []*types.Header
+	err := s.retry(ctx, func() error {
+		var err error
+		batch, err = fetchHeaders(ctx, headers, from, to)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetch headers %d-%d: %w", from, to, err)
+	}
+
+	var logs []types.Log
+	for _, h := range batch {
+		s.mu.Lock()
+		s.stats.Headers++
+		s.mu.Unlock()
+		if !BloomMatches(h.Bloom, q) {
+			s.mu.Lock()
+			s.stats.Skipped++
+			s.mu.Unlock()
+			continue
+		}
+
+		hash := h.Hash()
+		bq := q
+		bq.FromBlock, bq.ToBlock, bq.BlockHash = nil, nil, &hash
+		var found []types.Log
+		err := s.retry(ctx, func() error {
+			s.mu.Lock()
+			s.stats.Requests++
+			s.mu.Unlock()
+			var err error
+			found, err = s.client.FilterLogs(ctx, bq)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("filter logs of block %d: %w", h.Number, err)
+		}
+		s.mu.Lock()
+		s.stats.Candidates++
+		if len(found) == 0 {
+			s.stats.FalsePositives++
+		}
+		s.mu.Unlock()
+		logs = append(logs, found...)
+	}
+	return logs, nil
+}
+
+// retry runs f until it succeeds, the retries run out or ctx is done.
+func (s *Scanner) retry(ctx context.Context, f func() error) error {
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil || ctx.Err() != nil || attempt >= s.cfg.Retries {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		s.mu.Lock()
+		s.stats.Retries++
+		s.mu.Unlock()
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func fetchHeaders(ctx context.Context, headers HeaderReader, from, to uint64) ([]*types.Header, error) {
+	out := make([]*types.Header, to-from+1)
+	if rc, ok := headers.(interface{ Client() *rpc.Client }); ok {
+		elems := make([]rpc.BatchElem, len(out))
+		for i := range elems {
+			out[i] = new(types.Header)
+			elems[i] = rpc.BatchElem{
+				Method: "eth_getBlockByNumber",
+				Args:   []interface{}{hexutil.EncodeUint64(from + uint64(i)), false},
+				Result: out[i],
+			}
+		}
+		if err := rc.Client().BatchCallContext(ctx, elems); err != nil {
+			return nil, err
+		}
+		for i, e := range elems {
+			if e.Error != nil {
+				return nil, fmt.Errorf("block %d: %w", from+uint64(i), e.Error)
+			}
+			if out[i].Number == nil {
+				return nil, fmt.Errorf("block %d: %w", from+uint64(i), ethereum.NotFound)
+			}
+		}
+		return out, nil
+	}
+	for i := range out {
+		h, err := headers.HeaderByNumber(ctx, new(big.Int).SetUint64(from+uint64(i)))
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %w", from+uint64(i), err)
+		}
+		out[i] = h
+	}
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package logscan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Checkpoint records the first block a scan has not handled yet.
+type Checkpoint struct {
+	Key     string    `json:"key"`
+	Next    uint64    `json:"next"`
+	Updated time.Time `json:"updated"`
+}
+
+// LoadCheckpoint reads a checkpoint file; a missing file yields nil.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read checkpoint: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parse checkpoint %s: %w", path, err)
+	}
+	return &cp, nil
+}
+
+// Save writes the checkpoint atomically (temp file + rename) so a crash
+// never leaves a truncated file behind.
+func (cp Checkpoint) Save(path string) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// QueryKey fingerprints the addresses and topics of q (not its block range),
+// so a checkpoint is only reused for the same filter.
+func QueryKey(q ethereum.FilterQuery) string {
+	addrs := make([]string, len(q.Addresses))
+	for i, a := range q.Addresses {
+		addrs[i] = strings.ToLower(a.Hex())
+	}
+	sort.Strings(addrs)
+
+	var b strings.Builder
+	b.WriteString(strings.Join(addrs, ","))
+	for _, position := range q.Topics {
+		topics := make([]string, len(position))
+		for i, t := range position {
+			topics[i] = t.Hex()
+		}
+		sort.Strings(topics)
+		b.WriteString("|" + strings.Join(topics, ","))
+	}
+	return crypto.Keccak256Hash([]byte(b.String())).Hex()
+}
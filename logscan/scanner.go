@@ -0,0 +1,353 @@
+// Package logscan walks long block ranges with eth_getLogs in adaptively
+// sized chunks, so scans survive provider result and range limits.
+package logscan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Filterer is the part of *ethclient.Client the scanner needs.
+type Filterer interface {
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// Config controls chunking, concurrency and checkpointing.
+type Config struct {
+	ChunkSize   uint64 // initial blocks per eth_getLogs call
+	MinChunk    uint64 // never split below this (a failing chunk this small is an error)
+	MaxChunk    uint64 // never grow above this
+	Concurrency int    // chunks fetched in parallel
+	Retries     int    // retries for errors that are not range/result limits (negative for none)
+	Checkpoint  string // file recording progress; empty disables resuming
+	BloomBatch  uint64 // headers fetched per chunk by ScanBloom
+}
+
+// DefaultConfig works against public providers with 10k-result or
+// few-thousand-block limits.
+func DefaultConfig() Config {
+	return Config{
+		ChunkSize:   2000,
+		MinChunk:    1,
+		MaxChunk:    100000,
+		Concurrency: 4,
+		Retries:     3,
+		BloomBatch:  100,
+	}
+}
+
+// Handler receives the logs of [from, to] once every earlier block has been
+// handled. Returning an error stops the scan.
+type Handler func(from, to uint64, logs []types.Log) error
+
+// Stats counts what a scan did.
+type Stats struct {
+	Requests int // eth_getLogs calls
+	Splits   int // chunks bisected after a limit error
+	Retries  int // transient errors retried
+	Logs     int // logs handed to the handler
+	Resumed  bool
+
+	// Bloom mode only.
+	Headers        int // headers checked
+	Skipped        int // blocks whose bloom ruled them out
+	Candidates     int // blocks whose logs were fetched
+	FalsePositives int // candidates without a matching log
+}
+
+// Scanner fetches logs for a query over a block range.
+type Scanner struct {
+	client Filterer
+	cfg    Config
+
+	mu    sync.Mutex
+	size  uint64
+	stats Stats
+}
+
+// New returns a scanner. Zero fields of cfg take their DefaultConfig value.
+func New(client Filterer, cfg Config) *Scanner {
+	def := DefaultConfig()
+	if cfg.ChunkSize == 0 {
+		cfg.ChunkSize = def.ChunkSize
+	}
+	if cfg.MinChunk == 0 {
+		cfg.MinChunk = def.MinChunk
+	}
+	if cfg.MaxChunk == 0 {
+		cfg.MaxChunk = def.MaxChunk
+	}
+	if cfg.MaxChunk < cfg.ChunkSize {
+		cfg.MaxChunk = cfg.ChunkSize
+	}
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = def.Concurrency
+	}
+	if cfg.BloomBatch == 0 {
+		cfg.BloomBatch = def.BloomBatch
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = def.Retries
+	} else if cfg.Retries < 0 {
+		cfg.Retries = 0
+	}
+	return &Scanner{client: client, cfg: cfg, size: cfg.ChunkSize}
+}
+
+// Stats returns the counters of the scans run so far.
+func (s *Scanner) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// ChunkSize returns the current adaptive chunk size.
+func (s *Scanner) ChunkSize() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
+
+// Scan fetches the logs matching q (its block bounds are ignored) for
+// blocks from..to inclusive and passes them to fn chunk by chunk, in block
+// order, while up to Concurrency chunks are fetched in parallel. With a
+// checkpoint configured, progress is saved after every handled chunk and a
+// later Scan with the same filter resumes after the last handled block.
+func (s *Scanner) Scan(ctx context.Context, q ethereum.FilterQuery, from, to uint64, fn Handler) error {
+	return s.run(ctx, q, from, to, s.ChunkSize, func(ctx context.Context, from, to uint64) ([]types.Log, error) {
+		return s.fetch(ctx, q, from, to)
+	}, fn)
+}
+
+// fetchFunc reads the logs of one chunk.
+type fetchFunc func(ctx context.Context, from, to uint64) ([]types.Log, error)
+
+// run splits from..to into chunks of size() blocks, fetches them with up to
+// Concurrency workers and hands them to fn in order, checkpointing as it goes.
+func (s *Scanner) run(ctx context.Context, q ethereum.FilterQuery, from, to uint64, size func() uint64, fetch fetchFunc, fn Handler) error {
+	if from > to {
+		return fmt.Errorf("invalid range %d-%d", from, to)
+	}
+	key := QueryKey(q)
+	if s.cfg.Checkpoint != "" {
+		cp, err := LoadCheckpoint(s.cfg.Checkpoint)
+		if err != nil {
+			return err
+		}
+		if cp != nil && cp.Key == key && cp.Next > from && cp.Next <= to+1 {
+			from = cp.Next
+			s.mu.Lock()
+			s.stats.Resumed = true
+			s.mu.Unlock()
+		} else if cp != nil && cp.Key == key && cp.Next > to {
+			// Everything requested was handled by an earlier run.
+			return nil
+		}
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type chunk struct {
+		seq      int
+		from, to uint64
+		logs     []types.Log
+		err      error
+	}
+	jobs := make(chan chunk)
+	results := make(chan chunk)
+	// The window bounds how far fetching may run ahead of the handler.
+	window := make(chan struct{}, 2*s.cfg.Concurrency)
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.cfg.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range jobs {
+				c.logs, c.err = fetch(ctx, c.from, c.to)
+				select {
+				case results <- c:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for seq, next := 0, from; next <= to; seq++ {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			end := next + size() - 1
+			if end > to || end < next {
+				end = to
+			}
+			select {
+			case jobs <- chunk{seq: seq, from: next, to: end}:
+			case <-ctx.Done():
+				return
+			}
+			if end == to {
+				return
+			}
+			next = end + 1
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := make(map[int]chunk)
+	want := 0
+	for c := range results {
+		pending[c.seq] = c
+		for {
+			r, ok := pending[want]
+			if !ok {
+				break
+			}
+			delete(pending, want)
+			want++
+			if r.err != nil {
+				return r.err
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(r.from, r.to, r.logs); err != nil {
+				return err
+			}
+			s.mu.Lock()
+			s.stats.Logs += len(r.logs)
+			s.mu.Unlock()
+			if s.cfg.Checkpoint != "" {
+				cp := Checkpoint{Key: key, Next: r.to + 1, Updated: time.Now().UTC()}
+				if err := cp.Save(s.cfg.Checkpoint); err != nil {
+					return err
+				}
+			}
+			<-window
+		}
+	}
+	return parent.Err()
+}
+
+// fetch reads one chunk, bisecting it while the provider rejects it for
+// returning too much and retrying other errors with backoff.
+func (s *Scanner) fetch(ctx context.Context, q ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	q.FromBlock = new(big.Int).SetUint64(from)
+	q.ToBlock = new(big.Int).SetUint64(to)
+	q.BlockHash = nil
+
+	backoff := 500 * time.Millisecond
+	for attempt := 0; ; attempt++ {
+		s.mu.Lock()
+		s.stats.Requests++
+		s.mu.Unlock()
+
+		logs, err := s.client.FilterLogs(ctx, q)
+		if err == nil {
+			s.grow(to - from + 1)
+			return logs, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if IsLimitError(err) && to-from+1 > s.cfg.MinChunk {
+			s.shrink(to - from + 1)
+			mid := from + (to-from)/2
+			left, err := s.fetch(ctx, q, from, mid)
+			if err != nil {
+				return nil, err
+			}
+			right, err := s.fetch(ctx, q, mid+1, to)
+			if err != nil {
+				return nil, err
+			}
+			return append(left, right...), nil
+		}
+		if IsLimitError(err) || attempt >= s.cfg.Retries {
+			return nil, fmt.Errorf("filter logs %d-%d: %w", from, to, err)
+		}
+
+		s.mu.Lock()
+		s.stats.Retries++
+		s.mu.Unlock()
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// shrink halves the chunk size below a range the provider refused.
+func (s *Scanner) shrink(failed uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Splits++
+	if half := failed / 2; half < s.size {
+		s.size = max(half, s.cfg.MinChunk)
+	}
+}
+
+// grow doubles the chunk size after a full-size chunk succeeds.
+func (s *Scanner) grow(ok uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ok >= s.size {
+		s.size = min(s.size*2, s.cfg.MaxChunk)
+	}
+}
+
+// limitMessages are the errors providers return for ranges that are too wide
+// or produce too many results (geth, Infura, Alchemy, QuickNode, Erigon...).
+var limitMessages = []string{
+	"query returned more than",
+	"more than 10000 results",
+	"block range",
+	"range is too large",
+	"range too large",
+	"exceed maximum block range",
+	"exceeds max results",
+	"response size exceeded",
+	"response size should not greater than",
+	"limit exceeded",
+	"too many blocks",
+	"query timeout exceeded",
+}
+
+// IsLimitError reports whether err means the requested range should be
+// split rather than retried as is.
+func IsLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == -32005 {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range limitMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package logscan
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ParseTopic turns a command-line value into a topic: an event signature
+// such as "Transfer(address,address,uint256)" is hashed, a 0x-prefixed
+// address and a decimal number are left-padded, and a 32-byte hex value is
+// used as is. Unprefixed digits are always decimal, even 40 of them.
+func ParseTopic(s string) (common.Hash, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.Contains(s, "("):
+		return crypto.Keccak256Hash([]byte(strings.ReplaceAll(s, " ", ""))), nil
+	case strings.HasPrefix(s, "0x") && len(s) == 2+2*common.AddressLength && common.IsHexAddress(s):
+		return common.BytesToHash(common.HexToAddress(s).Bytes()), nil
+	case strings.HasPrefix(s, "0x"):
+		b, err := hexutil.Decode(s)
+		if err != nil || len(b) != common.HashLength {
+			return common.Hash{}, fmt.Errorf("invalid topic %q (want 32 bytes of hex)", s)
+		}
+		return common.BytesToHash(b), nil
+	default:
+		n, ok := new(big.Int).SetString(s, 10)
+		if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+			return common.Hash{}, fmt.Errorf("invalid topic %q", s)
+		}
+		return common.BigToHash(n), nil
+	}
+}
+
+// ParseTopics parses the comma-separated alternatives for one topic
+// position. An empty list matches anything.
+func ParseTopics(list string) ([]common.Hash, error) {
+	var out []common.Hash
+	for _, item := range splitSignatures(list) {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		topic, err := ParseTopic(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, topic)
+	}
+	return out, nil
+}
+
+// splitSignatures splits on commas outside parentheses so event signatures
+// keep their argument lists.
+func splitSignatures(list string) []string {
+	var (
+		out   []string
+		depth int
+		start int
+	)
+	for i, r := range list {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(out, list[start:])
+}
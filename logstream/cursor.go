@@ -0,0 +1,92 @@
+package logstream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Block is one canonical block as delivered to the handler: its logs, or,
+// with Removed set, the logs being retracted because the block left the
+// canonical chain.
+type Block struct {
+	Number  uint64      `json:"number"`
+	Hash    common.Hash `json:"hash"`
+	Logs    []types.Log `json:"logs"`
+	Removed bool        `json:"removed,omitempty"`
+}
+
+// Cursor is the stream position: the next block to deliver and the recent
+// delivered blocks kept to detect (and retract across) reorgs.
+type Cursor struct {
+	Next   uint64  `json:"next"`
+	Blocks []Block `json:"blocks"`
+}
+
+// CursorStore persists the cursor. Save is called after the handler returns
+// for every block, so a store that commits together with the handler's own
+// writes gives exactly-once delivery.
+type CursorStore interface {
+	Load() (*Cursor, error)
+	Save(Cursor) error
+}
+
+// FileCursor stores the cursor as JSON in a file.
+type FileCursor string
+
+// Load reads the cursor; a missing file yields nil.
+func (f FileCursor) Load() (*Cursor, error) {
+	data, err := os.ReadFile(string(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cursor: %w", err)
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parse cursor %s: %w", string(f), err)
+	}
+	return &c, nil
+}
+
+// Save replaces the file atomically.
+func (f FileCursor) Save(c Cursor) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	path := string(f)
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("write cursor: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write cursor: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("write cursor: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write cursor: %w", err)
+	}
+	return nil
+}
+
+// memoryCursor is used when no store is configured.
+type memoryCursor struct{ c *Cursor }
+
+func (m *memoryCursor) Load() (*Cursor, error) { return m.c, nil }
+
+func (m *memoryCursor) Save(c Cursor) error {
+	m.c = &c
+	return nil
+}
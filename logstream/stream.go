@@ -0,0 +1,306 @@
+// Package logstream delivers the logs of a filter block by block, only once
+// blocks are N confirmations deep, and retracts them explicitly when a reorg
+// removes a block that was already delivered.
+package logstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Client is the part of *ethclient.Client the stream needs.
+type Client interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// Handler is called once per canonical block (Removed false) and once per
+// block that left the canonical chain (Removed true, logs in reverse order
+// with their Removed flag set). Returning an error stops the stream without
+// advancing the cursor.
+type Handler func(b Block) error
+
+// Config controls confirmation depth, resume behaviour and polling.
+type Config struct {
+	// Confirmations is how many blocks must be built on top of a block before
+	// its logs are delivered. 0 delivers at the head and relies on retraction.
+	Confirmations uint64
+	// From is the first block to deliver when the store has no cursor;
+	// negative starts at the current confirmed head.
+	From int64
+	// Window is how many delivered blocks are remembered for reorg checks.
+	Window int
+	// Poll is the fallback interval when no head notification arrives.
+	Poll time.Duration
+	// Cursor persists the position; nil keeps it in memory only.
+	Cursor CursorStore
+	// OnError receives transient errors (RPC failures, lost subscriptions,
+	// reorgs deeper than Window) that the stream recovers from by itself.
+	OnError func(error)
+}
+
+// Stats counts what the stream has done since it was created.
+type Stats struct {
+	Blocks       int    // canonical blocks delivered
+	Logs         int    // logs delivered
+	Reorgs       int    // reorgs that retracted delivered blocks
+	Retracted    int    // blocks retracted
+	MaxDepth     int    // deepest retraction seen
+	DeepReorgs   int    // reorgs that went past the remembered window
+	Resubscribes int    // head subscriptions re-established
+	Next         uint64 // next block to deliver
+}
+
+// ErrReorgTooDeep is reported through OnError when a reorg reaches below the
+// remembered window; blocks older than the window cannot be retracted.
+var ErrReorgTooDeep = errors.New("reorg deeper than the remembered window")
+
+// Stream follows the chain for one filter query.
+type Stream struct {
+	client Client
+	query  ethereum.FilterQuery
+	cfg    Config
+
+	cursor *Cursor
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+type handlerError struct{ err error }
+
+func (e handlerError) Error() string { return e.err.Error() }
+func (e handlerError) Unwrap() error { return e.err }
+
+// New creates a stream for q (its block bounds are ignored) and loads the
+// persisted cursor, if any.
+func New(client Client, q ethereum.FilterQuery, cfg Config) (*Stream, error) {
+	if cfg.Window <= 0 {
+		cfg.Window = 64
+	}
+	if uint64(cfg.Window) <= cfg.Confirmations {
+		cfg.Window = int(cfg.Confirmations) + 1
+	}
+	if cfg.Poll <= 0 {
+		cfg.Poll = 5 * time.Second
+	}
+	if cfg.Cursor == nil {
+		cfg.Cursor = &memoryCursor{}
+	}
+	cursor, err := cfg.Cursor.Load()
+	if err != nil {
+		return nil, err
+	}
+	if cursor == nil && cfg.From >= 0 {
+		cursor = &Cursor{Next: uint64(cfg.From)}
+	}
+
+	q.FromBlock, q.ToBlock, q.BlockHash = nil, nil, nil
+	s := &Stream{client: client, query: q, cfg: cfg, cursor: cursor}
+	if cursor != nil {
+		s.stats.Next = cursor.Next
+	}
+	return s, nil
+}
+
+// Stats returns a snapshot of the counters.
+func (s *Stream) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Run delivers blocks until ctx is done or fn fails. It wakes up on new
+// head notifications and, when the endpoint cannot subscribe or the
+// subscription drops, on every Poll tick, resubscribing as it goes.
+func (s *Stream) Run(ctx context.Context, fn Handler) error {
+	heads := make(chan *types.Header, 16)
+	var sub ethereum.Subscription
+	subscribe := func() {
+		var err error
+		if sub, err = s.client.SubscribeNewHead(ctx, heads); err != nil {
+			sub = nil
+			s.report(fmt.Errorf("subscribe new heads: %w", err))
+		}
+	}
+	defer func() {
+		if sub != nil {
+			sub.Unsubscribe()
+		}
+	}()
+	subscribe()
+
+	ticker := time.NewTicker(s.cfg.Poll)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(ctx, fn); err != nil {
+			var herr handlerError
+			if errors.As(err, &herr) {
+				return herr.err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			s.report(err)
+		}
+
+		var subErr <-chan error
+		if sub != nil {
+			subErr = sub.Err()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-heads:
+		case <-ticker.C:
+			if sub == nil {
+				subscribe()
+				if sub != nil {
+					s.count(func(st *Stats) { st.Resubscribes++ })
+				}
+			}
+		case err := <-subErr:
+			s.report(fmt.Errorf("head subscription: %w", err))
+			sub.Unsubscribe()
+			sub = nil
+		}
+	}
+}
+
+// Sync retracts blocks that are no longer canonical and delivers every
+// block up to the confirmed head. Errors returned by fn are passed through
+// unchanged by Run; RPC errors leave the cursor where it was.
+func (s *Stream) Sync(ctx context.Context, fn Handler) error {
+	head, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("fetch head: %w", err)
+	}
+	if head.Number.Uint64() < s.cfg.Confirmations {
+		return nil
+	}
+	target := head.Number.Uint64() - s.cfg.Confirmations
+	if s.cursor == nil {
+		s.cursor = &Cursor{Next: target + 1}
+		s.count(func(st *Stats) { st.Next = target + 1 })
+	}
+
+	if err := s.unwind(ctx, fn); err != nil {
+		return err
+	}
+	for s.cursor.Next <= target {
+		header, err := s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(s.cursor.Next))
+		if err != nil {
+			return fmt.Errorf("fetch header %d: %w", s.cursor.Next, err)
+		}
+		if tip := s.tip(); tip != nil && header.ParentHash != tip.Hash {
+			// The chain changed since the tip was delivered.
+			if err := s.unwind(ctx, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		hash := header.Hash()
+		q := s.query
+		q.BlockHash = &hash
+		logs, err := s.client.FilterLogs(ctx, q)
+		if err != nil {
+			return fmt.Errorf("filter logs of block %d: %w", s.cursor.Next, err)
+		}
+		b := Block{Number: s.cursor.Next, Hash: hash, Logs: logs}
+		if err := fn(b); err != nil {
+			return handlerError{err}
+		}
+
+		s.cursor.Blocks = append(s.cursor.Blocks, b)
+		if len(s.cursor.Blocks) > s.cfg.Window {
+			s.cursor.Blocks = s.cursor.Blocks[len(s.cursor.Blocks)-s.cfg.Window:]
+		}
+		s.cursor.Next++
+		if err := s.cfg.Cursor.Save(*s.cursor); err != nil {
+			return handlerError{err}
+		}
+		s.count(func(st *Stats) {
+			st.Blocks++
+			st.Logs += len(logs)
+			st.Next = s.cursor.Next
+		})
+	}
+	return nil
+}
+
+// unwind retracts delivered blocks, newest first, until the remembered tip
+// is canonical again.
+func (s *Stream) unwind(ctx context.Context, fn Handler) error {
+	depth := 0
+	for tip := s.tip(); tip != nil; tip = s.tip() {
+		header, err := s.client.HeaderByNumber(ctx, new(big.Int).SetUint64(tip.Number))
+		if err != nil && !errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("fetch header %d: %w", tip.Number, err)
+		}
+		if err == nil && header.Hash() == tip.Hash {
+			break
+		}
+
+		removed := Block{Number: tip.Number, Hash: tip.Hash, Removed: true, Logs: make([]types.Log, len(tip.Logs))}
+		for i, l := range tip.Logs {
+			l.Removed = true
+			removed.Logs[len(tip.Logs)-1-i] = l
+		}
+		if err := fn(removed); err != nil {
+			return handlerError{err}
+		}
+		s.cursor.Blocks = s.cursor.Blocks[:len(s.cursor.Blocks)-1]
+		s.cursor.Next = tip.Number
+		if err := s.cfg.Cursor.Save(*s.cursor); err != nil {
+			return handlerError{err}
+		}
+		depth++
+	}
+	if depth == 0 {
+		return nil
+	}
+
+	deep := len(s.cursor.Blocks) == 0
+	s.count(func(st *Stats) {
+		st.Reorgs++
+		st.Retracted += depth
+		st.MaxDepth = max(st.MaxDepth, depth)
+		st.Next = s.cursor.Next
+		if deep {
+			st.DeepReorgs++
+		}
+	})
+	if deep {
+		s.report(fmt.Errorf("%w: retracted %d blocks back to %d", ErrReorgTooDeep, depth, s.cursor.Next))
+	}
+	return nil
+}
+
+func (s *Stream) tip() *Block {
+	if s.cursor == nil || len(s.cursor.Blocks) == 0 {
+		return nil
+	}
+	return &s.cursor.Blocks[len(s.cursor.Blocks)-1]
+}
+
+func (s *Stream) count(update func(*Stats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update(&s.stats)
+}
+
+func (s *Stream) report(err error) {
+	if s.cfg.OnError != nil {
+		s.cfg.OnError(err)
+	}
+}
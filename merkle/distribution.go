@@ -0,0 +1,120 @@
+package merkle
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Claim is the per-recipient part of a Distribution.
+type Claim struct {
+	Amount string        `json:"amount"`
+	Leaf   common.Hash   `json:"leaf"`
+	Proof  []common.Hash `json:"proof"`
+}
+
+// Distribution is the JSON document emitted for an airdrop: the root to
+// deploy with and everything a recipient needs to claim.
+type Distribution struct {
+	MerkleRoot common.Hash               `json:"merkleRoot"`
+	TokenTotal string                    `json:"tokenTotal"`
+	Claims     map[common.Address]*Claim `json:"claims"`
+}
+
+// ReadCSV parses "address,amount" rows. A header row is skipped when its
+// first column is not a hex address; blank lines and "#" comments are ignored.
+func ReadCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var entries []Entry
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		addr := strings.TrimSpace(record[0])
+		if !common.IsHexAddress(addr) {
+			if line == 1 {
+				continue
+			}
+			row, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: invalid address %q", row, addr)
+		}
+		amount, ok := new(big.Int).SetString(strings.TrimSpace(record[1]), 10)
+		if !ok {
+			row, _ := reader.FieldPos(1)
+			return nil, fmt.Errorf("line %d: invalid amount %q", row, record[1])
+		}
+		entries = append(entries, Entry{Account: common.HexToAddress(addr), Amount: amount})
+	}
+	return entries, nil
+}
+
+// Distribution renders the tree as a Distribution with one proof per account.
+func (t *Tree) Distribution() (*Distribution, error) {
+	total := new(big.Int)
+	claims := make(map[common.Address]*Claim, len(t.entries))
+	for _, e := range t.entries {
+		proof, err := t.Proof(e.Account)
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, e.Amount)
+		claims[e.Account] = &Claim{
+			Amount: e.Amount.String(),
+			Leaf:   LeafHash(e.Account, e.Amount),
+			Proof:  proof,
+		}
+	}
+	return &Distribution{
+		MerkleRoot: t.Root(),
+		TokenTotal: total.String(),
+		Claims:     claims,
+	}, nil
+}
+
+// ReadDistribution decodes a Distribution previously written as JSON.
+func ReadDistribution(r io.Reader) (*Distribution, error) {
+	var d Distribution
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return nil, err
+	}
+	if len(d.Claims) == 0 {
+		return nil, errors.New("distribution has no claims")
+	}
+	return &d, nil
+}
+
+// VerifyClaim recomputes the leaf for account from the stored amount and
+// checks its proof against the distribution root.
+func (d *Distribution) VerifyClaim(account common.Address) (*big.Int, error) {
+	claim, ok := d.Claims[account]
+	if !ok {
+		return nil, fmt.Errorf("no claim for %s", account.Hex())
+	}
+	amount, ok := new(big.Int).SetString(claim.Amount, 10)
+	if !ok || amount.Sign() < 0 || amount.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid amount %q for %s", claim.Amount, account.Hex())
+	}
+	leaf := LeafHash(account, amount)
+	if leaf != claim.Leaf {
+		return nil, fmt.Errorf("leaf mismatch for %s: stored %s, computed %s", account.Hex(), claim.Leaf.Hex(), leaf.Hex())
+	}
+	if !Verify(d.MerkleRoot, leaf, claim.Proof) {
+		return nil, fmt.Errorf("proof for %s does not reach root %s", account.Hex(), d.MerkleRoot.Hex())
+	}
+	return amount, nil
+}
@@ -0,0 +1,462 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package merkle
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// DistributorMetaData contains all meta data concerning the Distributor contract.
+var DistributorMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"address\",\"name\":\"token_\",\"type\":\"address\"},{\"internalType\":\"bytes32\",\"name\":\"merkleRoot_\",\"type\":\"bytes32\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"indexed\":false,\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"}],\"name\":\"Claimed\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"account\",\"type\":\"address\"},{\"internalType\":\"uint256\",\"name\":\"amount\",\"type\":\"uint256\"},{\"internalType\":\"bytes32[]\",\"name\":\"proof\",\"type\":\"bytes32[]\"}],\"name\":\"claim\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"name\":\"isClaimed\",\"outputs\":[{\"internalType\":\"bool\",\"name\":\"\",\"type\":\"bool\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"merkleRoot\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"token\",\"outputs\":[{\"internalType\":\"address\",\"name\":\"\",\"type\":\"address\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	Bin: "0x60c060405234801561000f575f5ffd5b50604051610b50380380610b5083398181016040528101906100319190610105565b8173ffffffffffffffffffffffffffffffffffffffff1660808173ffffffffffffffffffffffffffffffffffffffff16815250508060a081815250505050610143565b5f5ffd5b5f73ffffffffffffffffffffffffffffffffffffffff82169050919050565b5f6100a182610078565b9050919050565b6100b181610097565b81146100bb575f5ffd5b50565b5f815190506100cc816100a8565b92915050565b5f819050919050565b6100e4816100d2565b81146100ee575f5ffd5b50565b5f815190506100ff816100db565b92915050565b5f5f6040838503121561011b5761011a610074565b5b5f610128858286016100be565b9250506020610139858286016100f1565b9150509250929050565b60805160a0516109df6101715f395f818160d801526101dc01525f818161029601526103e101526109df5ff3fe608060405234801561000f575f5ffd5b506004361061004a575f3560e01c80632eb4a7ab1461004e5780633d13f8741461006c5780638cc0802514610088578063fc0c546a146100b8575b5f5ffd5b6100566100d6565b60405161006391906104d9565b60405180910390f35b610086600480360381019061008191906105e8565b6100fa565b005b6100a2600480360381019061009d9190610659565b6103c3565b6040516100af919061069e565b60405180910390f35b6100c06103df565b6040516100cd91906106c6565b60405180910390f35b7f000000000000000000000000000000000000000000000000000000000000000081565b5f5f8573ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019081526020015f205f9054906101000a900460ff1615610183576040517f08c379a000000000000000000000000000000000000000000000000000000000815260040161017a9061075f565b60405180910390fd5b5f848460405160200161019792919061078c565b604051602081830303815290604052805190602001206040516020016101bd91906107d3565b60405160208183030381529060405280519060200120905061020183837f000000000000000000000000000000000000000000000000000000000000000084610403565b610240576040517f08c379a000000000000000000000000000000000000000000000000000000000815260040161023790610837565b60405180910390fd5b60015f5f8773ffffffffffffffffffffffffffffffffffffffff1673ffffffffffffffffffffffffffffffffffffffff1681526020019081526020015f205f6101000a81548160ff0219169083151502179055507f000000000000000000000000000000000000000000000000000000000000000073ffffffffffffffffffffffffffffffffffffffff1663a9059cbb86866040518363ffffffff1660e01b81526004016102ef92919061078c565b6020604051808303815f875af115801561030b573d5f5f3e3d5ffd5b505050506040513d601f19601f8201168201806040525081019061032f919061087f565b61036e576040517f08c379a00000000000000000000000000000000000000000000000000000000081526004016103659061091a565b60405180910390fd5b8473ffffffffffffffffffffffffffffffffffffffff167fd8138f8a3f377c5259ca548e70e4c2de94f129f5a11036a15b69513cba2b426a856040516103b49190610938565b60405180910390a25050505050565b5f602052805f5260405f205f915054906101000a900460ff1681565b7f000000000000000000000000000000000000000000000000000000000000000081565b5f5f8290505f5f90505b8686905081101561044a5761043b8288888481811061042f5761042e610951565b5b90506020020135610459565b9150808060010191505061040d565b50838114915050949350505050565b5f81831061048f57818360405160200161047492919061097e565b604051602081830303815290604052805190602001206104b9565b82826040516020016104a292919061097e565b604051602081830303815290604052805190602001205b905092915050565b5f819050919050565b6104d3816104c1565b82525050565b5f6020820190506104ec5f8301846104ca565b92915050565b5f5ffd5b5f5ffd5b5f73ffffffffffffffffffffffffffffffffffffffff82169050919050565b5f610523826104fa565b9050919050565b61053381610519565b811461053d575f5ffd5b50565b5f8135905061054e8161052a565b92915050565b5f819050919050565b61056681610554565b8114610570575f5ffd5b50565b5f813590506105818161055d565b92915050565b5f5ffd5b5f5ffd5b5f5ffd5b5f5f83601f8401126105a8576105a7610587565b5b8235905067ffffffffffffffff8111156105c5576105c461058b565b5b6020830191508360208202830111156105e1576105e061058f565b5b9250929050565b5f5f5f5f60608587031215610600576105ff6104f2565b5b5f61060d87828801610540565b945050602061061e87828801610573565b935050604085013567ffffffffffffffff81111561063f5761063e6104f6565b5b61064b87828801610593565b925092505092959194509250565b5f6020828403121561066e5761066d6104f2565b5b5f61067b84828501610540565b91505092915050565b5f8115159050919050565b61069881610684565b82525050565b5f6020820190506106b15f83018461068f565b92915050565b6106c081610519565b82525050565b5f6020820190506106d95f8301846106b7565b92915050565b5f82825260208201905092915050565b7f4d65726b6c654469737472696275746f723a20616c726561647920636c61696d5f8201527f6564000000000000000000000000000000000000000000000000000000000000602082015250565b5f6107496022836106df565b9150610754826106ef565b604082019050919050565b5f6020820190508181035f8301526107768161073d565b9050919050565b61078681610554565b82525050565b5f60408201905061079f5f8301856106b7565b6107ac602083018461077d565b9392505050565b5f819050919050565b6107cd6107c8826104c1565b6107b3565b82525050565b5f6107de82846107bc565b60208201915081905092915050565b7f4d65726b6c654469737472696275746f723a20696e76616c69642070726f6f665f82015250565b5f6108216020836106df565b915061082c826107ed565b602082019050919050565b5f6020820190508181035f83015261084e81610815565b9050919050565b61085e81610684565b8114610868575f5ffd5b50565b5f8151905061087981610855565b92915050565b5f60208284031215610894576108936104f2565b5b5f6108a18482850161086b565b91505092915050565b7f4d65726b6c654469737472696275746f723a207472616e73666572206661696c5f8201527f6564000000000000000000000000000000000000000000000000000000000000602082015250565b5f6109046022836106df565b915061090f826108aa565b604082019050919050565b5f6020820190508181035f830152610931816108f8565b9050919050565b5f60208201905061094b5f83018461077d565b92915050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52603260045260245ffd5b5f61098982856107bc565b60208201915061099982846107bc565b602082019150819050939250505056fea2646970667358221220541267b62cf4fb07a579ba62c0a71cde7150db3f29bec3bb9fcb1287f884004364736f6c634300081e0033",
+}
+
+// DistributorABI is the input ABI used to generate the binding from.
+// Deprecated: Use DistributorMetaData.ABI instead.
+var DistributorABI = DistributorMetaData.ABI
+
+// DistributorBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use DistributorMetaData.Bin instead.
+var DistributorBin = DistributorMetaData.Bin
+
+// DeployDistributor deploys a new Ethereum contract, binding an instance of Distributor to it.
+func DeployDistributor(auth *bind.TransactOpts, backend bind.ContractBackend, token_ common.Address, merkleRoot_ [32]byte) (common.Address, *types.Transaction, *Distributor, error) {
+	parsed, err := DistributorMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(DistributorBin), backend, token_, merkleRoot_)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &Distributor{DistributorCaller: DistributorCaller{contract: contract}, DistributorTransactor: DistributorTransactor{contract: contract}, DistributorFilterer: DistributorFilterer{contract: contract}}, nil
+}
+
+// Distributor is an auto generated Go binding around an Ethereum contract.
+type Distributor struct {
+	DistributorCaller     // Read-only binding to the contract
+	DistributorTransactor // Write-only binding to the contract
+	DistributorFilterer   // Log filterer for contract events
+}
+
+// DistributorCaller is an auto generated read-only Go binding around an Ethereum contract.
+type DistributorCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DistributorTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type DistributorTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DistributorFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type DistributorFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DistributorSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type DistributorSession struct {
+	Contract     *Distributor      // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// DistributorCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type DistributorCallerSession struct {
+	Contract *DistributorCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts      // Call options to use throughout this session
+}
+
+// DistributorTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type DistributorTransactorSession struct {
+	Contract     *DistributorTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts      // Transaction auth options to use throughout this session
+}
+
+// DistributorRaw is an auto generated low-level Go binding around an Ethereum contract.
+type DistributorRaw struct {
+	Contract *Distributor // Generic contract binding to access the raw methods on
+}
+
+// DistributorCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type DistributorCallerRaw struct {
+	Contract *DistributorCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// DistributorTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type DistributorTransactorRaw struct {
+	Contract *DistributorTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewDistributor creates a new instance of Distributor, bound to a specific deployed contract.
+func NewDistributor(address common.Address, backend bind.ContractBackend) (*Distributor, error) {
+	contract, err := bindDistributor(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Distributor{DistributorCaller: DistributorCaller{contract: contract}, DistributorTransactor: DistributorTransactor{contract: contract}, DistributorFilterer: DistributorFilterer{contract: contract}}, nil
+}
+
+// NewDistributorCaller creates a new read-only instance of Distributor, bound to a specific deployed contract.
+func NewDistributorCaller(address common.Address, caller bind.ContractCaller) (*DistributorCaller, error) {
+	contract, err := bindDistributor(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DistributorCaller{contract: contract}, nil
+}
+
+// NewDistributorTransactor creates a new write-only instance of Distributor, bound to a specific deployed contract.
+func NewDistributorTransactor(address common.Address, transactor bind.ContractTransactor) (*DistributorTransactor, error) {
+	contract, err := bindDistributor(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DistributorTransactor{contract: contract}, nil
+}
+
+// NewDistributorFilterer creates a new log filterer instance of Distributor, bound to a specific deployed contract.
+func NewDistributorFilterer(address common.Address, filterer bind.ContractFilterer) (*DistributorFilterer, error) {
+	contract, err := bindDistributor(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &DistributorFilterer{contract: contract}, nil
+}
+
+// bindDistributor binds a generic wrapper to an already deployed contract.
+func bindDistributor(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := DistributorMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Distributor *DistributorRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Distributor.Contract.DistributorCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Distributor *DistributorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Distributor.Contract.DistributorTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Distributor *DistributorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Distributor.Contract.DistributorTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Distributor *DistributorCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Distributor.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Distributor *DistributorTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Distributor.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Distributor *DistributorTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Distributor.Contract.contract.Transact(opts, method, params...)
+}
+
+// IsClaimed is a free data retrieval call binding the contract method 0x8cc08025.
+//
+// Solidity: function isClaimed(address ) view returns(bool)
+func (_Distributor *DistributorCaller) IsClaimed(opts *bind.CallOpts, arg0 common.Address) (bool, error) {
+	var out []interface{}
+	err := _Distributor.contract.Call(opts, &out, "isClaimed", arg0)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// IsClaimed is a free data retrieval call binding the contract method 0x8cc08025.
+//
+// Solidity: function isClaimed(address ) view returns(bool)
+func (_Distributor *DistributorSession) IsClaimed(arg0 common.Address) (bool, error) {
+	return _Distributor.Contract.IsClaimed(&_Distributor.CallOpts, arg0)
+}
+
+// IsClaimed is a free data retrieval call binding the contract method 0x8cc08025.
+//
+// Solidity: function isClaimed(address ) view returns(bool)
+func (_Distributor *DistributorCallerSession) IsClaimed(arg0 common.Address) (bool, error) {
+	return _Distributor.Contract.IsClaimed(&_Distributor.CallOpts, arg0)
+}
+
+// MerkleRoot is a free data retrieval call binding the contract method 0x2eb4a7ab.
+//
+// Solidity: function merkleRoot() view returns(bytes32)
+func (_Distributor *DistributorCaller) MerkleRoot(opts *bind.CallOpts) ([32]byte, error) {
+	var out []interface{}
+	err := _Distributor.contract.Call(opts, &out, "merkleRoot")
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// MerkleRoot is a free data retrieval call binding the contract method 0x2eb4a7ab.
+//
+// Solidity: function merkleRoot() view returns(bytes32)
+func (_Distributor *DistributorSession) MerkleRoot() ([32]byte, error) {
+	return _Distributor.Contract.MerkleRoot(&_Distributor.CallOpts)
+}
+
+// MerkleRoot is a free data retrieval call binding the contract method 0x2eb4a7ab.
+//
+// Solidity: function merkleRoot() view returns(bytes32)
+func (_Distributor *DistributorCallerSession) MerkleRoot() ([32]byte, error) {
+	return _Distributor.Contract.MerkleRoot(&_Distributor.CallOpts)
+}
+
+// Token is a free data retrieval call binding the contract method 0xfc0c546a.
+//
+// Solidity: function token() view returns(address)
+func (_Distributor *DistributorCaller) Token(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _Distributor.contract.Call(opts, &out, "token")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Token is a free data retrieval call binding the contract method 0xfc0c546a.
+//
+// Solidity: function token() view returns(address)
+func (_Distributor *DistributorSession) Token() (common.Address, error) {
+	return _Distributor.Contract.Token(&_Distributor.CallOpts)
+}
+
+// Token is a free data retrieval call binding the contract method 0xfc0c546a.
+//
+// Solidity: function token() view returns(address)
+func (_Distributor *DistributorCallerSession) Token() (common.Address, error) {
+	return _Distributor.Contract.Token(&_Distributor.CallOpts)
+}
+
+// Claim is a paid mutator transaction binding the contract method 0x3d13f874.
+//
+// Solidity: function claim(address account, uint256 amount, bytes32[] proof) returns()
+func (_Distributor *DistributorTransactor) Claim(opts *bind.TransactOpts, account common.Address, amount *big.Int, proof [][32]byte) (*types.Transaction, error) {
+	return _Distributor.contract.Transact(opts, "claim", account, amount, proof)
+}
+
+// Claim is a paid mutator transaction binding the contract method 0x3d13f874.
+//
+// Solidity: function claim(address account, uint256 amount, bytes32[] proof) returns()
+func (_Distributor *DistributorSession) Claim(account common.Address, amount *big.Int, proof [][32]byte) (*types.Transaction, error) {
+	return _Distributor.Contract.Claim(&_Distributor.TransactOpts, account, amount, proof)
+}
+
+// Claim is a paid mutator transaction binding the contract method 0x3d13f874.
+//
+// Solidity: function claim(address account, uint256 amount, bytes32[] proof) returns()
+func (_Distributor *DistributorTransactorSession) Claim(account common.Address, amount *big.Int, proof [][32]byte) (*types.Transaction, error) {
+	return _Distributor.Contract.Claim(&_Distributor.TransactOpts, account, amount, proof)
+}
+
+// DistributorClaimedIterator is returned from FilterClaimed and is used to iterate over the raw logs and unpacked data for Claimed events raised by the Distributor contract.
+type DistributorClaimedIterator struct {
+	Event *DistributorClaimed // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *DistributorClaimedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(DistributorClaimed)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(DistributorClaimed)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *DistributorClaimedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *DistributorClaimedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// DistributorClaimed represents a Claimed event raised by the Distributor contract.
+type DistributorClaimed struct {
+	Account common.Address
+	Amount  *big.Int
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterClaimed is a free log retrieval operation binding the contract event 0xd8138f8a3f377c5259ca548e70e4c2de94f129f5a11036a15b69513cba2b426a.
+//
+// Solidity: event Claimed(address indexed account, uint256 amount)
+func (_Distributor *DistributorFilterer) FilterClaimed(opts *bind.FilterOpts, account []common.Address) (*DistributorClaimedIterator, error) {
+
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _Distributor.contract.FilterLogs(opts, "Claimed", accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return &DistributorClaimedIterator{contract: _Distributor.contract, event: "Claimed", logs: logs, sub: sub}, nil
+}
+
+// WatchClaimed is a free log subscription operation binding the contract event 0xd8138f8a3f377c5259ca548e70e4c2de94f129f5a11036a15b69513cba2b426a.
+//
+// Solidity: event Claimed(address indexed account, uint256 amount)
+func (_Distributor *DistributorFilterer) WatchClaimed(opts *bind.WatchOpts, sink chan<- *DistributorClaimed, account []common.Address) (event.Subscription, error) {
+
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _Distributor.contract.WatchLogs(opts, "Claimed", accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(DistributorClaimed)
+				if err := _Distributor.contract.UnpackLog(event, "Claimed", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseClaimed is a log parse operation binding the contract event 0xd8138f8a3f377c5259ca548e70e4c2de94f129f5a11036a15b69513cba2b426a.
+//
+// Solidity: event Claimed(address indexed account, uint256 amount)
+func (_Distributor *DistributorFilterer) ParseClaimed(log types.Log) (*DistributorClaimed, error) {
+	event := new(DistributorClaimed)
+	if err := _Distributor.contract.UnpackLog(event, "Claimed", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,143 @@
+package merkle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Entry is a single airdrop allocation.
+type Entry struct {
+	Account common.Address
+	Amount  *big.Int
+}
+
+// Tree is a sorted-pair keccak256 Merkle tree laid out exactly like
+// OpenZeppelin's StandardMerkleTree, so roots and proofs are interchangeable
+// with @openzeppelin/merkle-tree and verify with MerkleProof.verify.
+type Tree struct {
+	nodes   []common.Hash
+	entries []Entry
+	index   map[common.Address]int // account -> position in nodes
+}
+
+var leafArgs = abi.Arguments{
+	{Type: mustType("address")},
+	{Type: mustType("uint256")},
+}
+
+func mustType(name string) abi.Type {
+	t, err := abi.NewType(name, "", nil)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// LeafHash returns keccak256(bytes.concat(keccak256(abi.encode(account, amount)))).
+func LeafHash(account common.Address, amount *big.Int) common.Hash {
+	packed, err := leafArgs.Pack(account, amount)
+	if err != nil {
+		// Only reachable with a negative or >256-bit amount, which NewTree rejects.
+		panic(fmt.Sprintf("encode leaf: %v", err))
+	}
+	return crypto.Keccak256Hash(crypto.Keccak256(packed))
+}
+
+// HashPair hashes two nodes in sorted order, matching MerkleProof._hashPair.
+func HashPair(a, b common.Hash) common.Hash {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return crypto.Keccak256Hash(a[:], b[:])
+}
+
+// NewTree builds the tree for the given allocations. Accounts must be unique
+// and amounts must fit in a uint256.
+func NewTree(entries []Entry) (*Tree, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("no entries")
+	}
+
+	maxAmount := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+	type leaf struct {
+		hash  common.Hash
+		entry int
+	}
+	leaves := make([]leaf, len(entries))
+	seen := make(map[common.Address]bool, len(entries))
+	for i, e := range entries {
+		if seen[e.Account] {
+			return nil, fmt.Errorf("duplicate account %s", e.Account.Hex())
+		}
+		seen[e.Account] = true
+		if e.Amount == nil || e.Amount.Sign() < 0 || e.Amount.Cmp(maxAmount) > 0 {
+			return nil, fmt.Errorf("amount for %s out of uint256 range", e.Account.Hex())
+		}
+		leaves[i] = leaf{hash: LeafHash(e.Account, e.Amount), entry: i}
+	}
+	sort.Slice(leaves, func(i, j int) bool {
+		return bytes.Compare(leaves[i].hash[:], leaves[j].hash[:]) < 0
+	})
+
+	// Leaves occupy the tail of the array in reverse order; node i has
+	// children 2i+1 and 2i+2.
+	n := len(leaves)
+	t := &Tree{
+		nodes:   make([]common.Hash, 2*n-1),
+		entries: entries,
+		index:   make(map[common.Address]int, n),
+	}
+	for i, l := range leaves {
+		pos := len(t.nodes) - 1 - i
+		t.nodes[pos] = l.hash
+		t.index[entries[l.entry].Account] = pos
+	}
+	for i := len(t.nodes) - 1 - n; i >= 0; i-- {
+		t.nodes[i] = HashPair(t.nodes[2*i+1], t.nodes[2*i+2])
+	}
+	return t, nil
+}
+
+// Root returns the Merkle root.
+func (t *Tree) Root() common.Hash {
+	return t.nodes[0]
+}
+
+// Entries returns the allocations in their original order.
+func (t *Tree) Entries() []Entry {
+	return t.entries
+}
+
+// Proof returns the sibling path from the account's leaf up to the root.
+func (t *Tree) Proof(account common.Address) ([]common.Hash, error) {
+	pos, ok := t.index[account]
+	if !ok {
+		return nil, fmt.Errorf("account %s not in tree", account.Hex())
+	}
+	var proof []common.Hash
+	for pos > 0 {
+		sibling := pos - 1
+		if pos%2 == 1 {
+			sibling = pos + 1
+		}
+		proof = append(proof, t.nodes[sibling])
+		pos = (pos - 1) / 2
+	}
+	return proof, nil
+}
+
+// Verify folds proof over leaf and compares the result to root.
+func Verify(root, leaf common.Hash, proof []common.Hash) bool {
+	computed := leaf
+	for _, p := range proof {
+		computed = HashPair(computed, p)
+	}
+	return computed == root
+}
@@ -0,0 +1,56 @@
+// Package parallel runs indexed work, such as fetching every block of a
+// range, on a bounded number of goroutines.
+package parallel
+
+import (
+	"context"
+	"sync"
+)
+
+// Run calls fn for every index in [0, n) from up to workers goroutines
+// (at least one). The first error cancels the context the other calls get
+// and stops handing out indexes; Run returns that error rather than the
+// cancellation errors it causes. If ctx is done before every index was
+// handed out, Run returns ctx's error.
+func Run(ctx context.Context, n, workers int, fn func(ctx context.Context, i int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg    sync.WaitGroup
+		once  sync.Once
+		first error
+	)
+	next := make(chan int)
+	for w := 0; w < max(workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if err := fn(ctx, i); err != nil {
+					once.Do(func() {
+						first = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+	handed := 0
+feed:
+	for ; handed < n; handed++ {
+		select {
+		case next <- handed:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+	if first != nil {
+		return first
+	}
+	if handed < n {
+		return ctx.Err()
+	}
+	return nil
+}
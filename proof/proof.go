@@ -0,0 +1,220 @@
+// Package proof reads account and storage values through eth_getProof and
+// verifies the Merkle-Patricia proofs against the block's state root, so a
+// value is only as trustworthy as the block hash it was proven against.
+// Proofs are kept in a portable Bundle that can be re-verified offline.
+package proof
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/holiman/uint256"
+)
+
+// Account is the state of an account as committed to in the state trie.
+type Account struct {
+	Nonce       hexutil.Uint64 `json:"nonce"`
+	Balance     *hexutil.Big   `json:"balance"`
+	StorageHash common.Hash    `json:"storageHash"`
+	CodeHash    common.Hash    `json:"codeHash"`
+}
+
+// StorageProof proves the value of one storage slot.
+type StorageProof struct {
+	Label string          `json:"label,omitempty"` // what the slot holds, e.g. items["foo"]
+	Key   common.Hash     `json:"key"`             // the slot
+	Value common.Hash     `json:"value"`           // the 32-byte word stored
+	Proof []hexutil.Bytes `json:"proof"`
+}
+
+// Bundle is everything needed to re-verify an account and its slots
+// offline: the RLP header (which must hash to BlockHash) and the proofs.
+type Bundle struct {
+	BlockNumber  uint64          `json:"blockNumber"`
+	BlockHash    common.Hash     `json:"blockHash"`
+	Header       hexutil.Bytes   `json:"header"`
+	Address      common.Address  `json:"address"`
+	Account      Account         `json:"account"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	Storage      []StorageProof  `json:"storage,omitempty"`
+}
+
+// Slot is a storage slot to prove, with an optional label.
+type Slot struct {
+	Key   common.Hash
+	Label string
+}
+
+// getProofResult is the eth_getProof response.
+type getProofResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []hexutil.Bytes `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []struct {
+		// Clients return keys and values either as quantities or as
+		// zero-padded words; HexToHash reads both.
+		Key   string          `json:"key"`
+		Value string          `json:"value"`
+		Proof []hexutil.Bytes `json:"proof"`
+	} `json:"storageProof"`
+}
+
+// Fetch proves addr and slots at block (nil for latest) and verifies the
+// result. The proof is requested by block hash, so it matches the header
+// even if the chain moves meanwhile.
+func Fetch(ctx context.Context, client *ethclient.Client, addr common.Address, slots []Slot, block *big.Int) (*Bundle, error) {
+	header, err := client.HeaderByNumber(ctx, block)
+	if err != nil {
+		return nil, fmt.Errorf("fetch header: %w", err)
+	}
+	keys := make([]string, len(slots))
+	for i, s := range slots {
+		keys[i] = s.Key.Hex()
+	}
+	var res getProofResult
+	at := map[string]interface{}{"blockHash": header.Hash()}
+	if err := client.Client().CallContext(ctx, &res, "eth_getProof", addr, keys, at); err != nil {
+		return nil, fmt.Errorf("eth_getProof at block %d: %w", header.Number, err)
+	}
+	if len(res.StorageProof) != len(slots) {
+		return nil, fmt.Errorf("eth_getProof returned %d storage proofs for %d slots", len(res.StorageProof), len(slots))
+	}
+
+	encoded, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{
+		BlockNumber: header.Number.Uint64(),
+		BlockHash:   header.Hash(),
+		Header:      encoded,
+		Address:     addr,
+		Account: Account{
+			Nonce:       res.Nonce,
+			Balance:     res.Balance,
+			StorageHash: res.StorageHash,
+			CodeHash:    res.CodeHash,
+		},
+		AccountProof: res.AccountProof,
+	}
+	for i, sp := range res.StorageProof {
+		if key := common.HexToHash(sp.Key); key != slots[i].Key {
+			return nil, fmt.Errorf("eth_getProof returned slot %s for %s", key.Hex(), slots[i].Key.Hex())
+		}
+		b.Storage = append(b.Storage, StorageProof{Label: slots[i].Label, Key: slots[i].Key, Value: common.HexToHash(sp.Value), Proof: sp.Proof})
+	}
+	if err := b.Verify(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Verify checks the bundle without any RPC: the header hashes to BlockHash,
+// the account proof leads from its state root to Account, and every storage
+// proof leads from the account's storage root to its value.
+func (b *Bundle) Verify() error {
+	var header types.Header
+	if err := rlp.DecodeBytes(b.Header, &header); err != nil {
+		return fmt.Errorf("decode header: %w", err)
+	}
+	if header.Hash() != b.BlockHash {
+		return fmt.Errorf("header hashes to %s, not block %s", header.Hash().Hex(), b.BlockHash.Hex())
+	}
+	if header.Number.Uint64() != b.BlockNumber {
+		return fmt.Errorf("header is block %d, not %d", header.Number, b.BlockNumber)
+	}
+
+	leaf, err := verifyProof(header.Root, b.Address.Bytes(), b.AccountProof)
+	if err != nil {
+		return fmt.Errorf("account proof of %s: %w", b.Address.Hex(), err)
+	}
+	proven := types.StateAccount{Balance: new(uint256.Int), Root: types.EmptyRootHash, CodeHash: types.EmptyCodeHash.Bytes()}
+	if leaf != nil {
+		if err := rlp.DecodeBytes(leaf, &proven); err != nil {
+			return fmt.Errorf("decode account %s: %w", b.Address.Hex(), err)
+		}
+	}
+	a := b.Account
+	if a.Balance == nil || uint64(a.Nonce) != proven.Nonce || a.Balance.ToInt().Cmp(proven.Balance.ToBig()) != 0 ||
+		a.StorageHash != proven.Root || !bytes.Equal(a.CodeHash.Bytes(), proven.CodeHash) {
+		return fmt.Errorf("account %s does not match its proof (proven nonce %d, balance %s)", b.Address.Hex(), proven.Nonce, proven.Balance)
+	}
+
+	for _, s := range b.Storage {
+		leaf, err := verifyProof(proven.Root, s.Key.Bytes(), s.Proof)
+		if err != nil {
+			return fmt.Errorf("storage proof of slot %s: %w", s.Key.Hex(), err)
+		}
+		var value common.Hash
+		if leaf != nil {
+			var content []byte
+			if err := rlp.DecodeBytes(leaf, &content); err != nil {
+				return fmt.Errorf("decode slot %s: %w", s.Key.Hex(), err)
+			}
+			value = common.BytesToHash(content)
+		}
+		if value != s.Value {
+			return fmt.Errorf("slot %s holds %s, not %s", s.Key.Hex(), value.Hex(), s.Value.Hex())
+		}
+	}
+	return nil
+}
+
+// verifyProof walks a proof for keccak(key) from root. A nil leaf with no
+// error proves the key is absent.
+func verifyProof(root common.Hash, key []byte, nodes []hexutil.Bytes) ([]byte, error) {
+	db := memorydb.New()
+	for _, node := range nodes {
+		if err := db.Put(crypto.Keccak256(node), node); err != nil {
+			return nil, err
+		}
+	}
+	return trie.VerifyProof(root, crypto.Keccak256(key), db)
+}
+
+// Slot returns the proven storage proof with the given key.
+func (b *Bundle) Slot(key common.Hash) (StorageProof, bool) {
+	for _, s := range b.Storage {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return StorageProof{}, false
+}
+
+// Save writes the bundle as indented JSON.
+func (b *Bundle) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Load reads a bundle written by Save. It does not verify it.
+func Load(path string) (*Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Bundle
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &b, nil
+}
@@ -0,0 +1,71 @@
+package proof
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/obingo31/go-eth/storestate"
+	"github.com/obingo31/go-eth/token"
+)
+
+// StoreItemsSlot is the storage slot of Store's items mapping (after the
+// version string at slot 0).
+const StoreItemsSlot = 1
+
+// MappingSlot returns the slot of mapping[key] for a mapping declared at
+// slot: keccak256(key . slot), both as 32-byte words.
+func MappingSlot(key common.Hash, slot uint64) common.Hash {
+	return crypto.Keccak256Hash(key.Bytes(), common.BigToHash(new(big.Int).SetUint64(slot)).Bytes())
+}
+
+// StoreItem returns the slot of Store's items[key], labelled with the key.
+func StoreItem(key [32]byte) Slot {
+	return Slot{
+		Key:   MappingSlot(key, StoreItemsSlot),
+		Label: fmt.Sprintf("items[%s]", storestate.Format(key)),
+	}
+}
+
+// Balance returns the slot of holder in an ERC-20 balances mapping declared
+// at mappingSlot.
+func Balance(holder common.Address, mappingSlot uint64) Slot {
+	return Slot{
+		Key:   MappingSlot(common.BytesToHash(holder.Bytes()), mappingSlot),
+		Label: fmt.Sprintf("balances[%s]", holder.Hex()),
+	}
+}
+
+// FindBalanceSlot finds the slot of a token's balances mapping by reading
+// holder's candidate slots 0..maxSlot and comparing them with balanceOf at
+// the same block. holder needs a non-zero balance for the match to mean
+// anything. Tokens with non-standard layouts (proxies with namespaced
+// storage, rebasing balances) are not found.
+func FindBalanceSlot(ctx context.Context, client *ethclient.Client, tokenAddr, holder common.Address, block *big.Int, maxSlot uint64) (uint64, error) {
+	caller, err := token.NewTokenCaller(tokenAddr, client)
+	if err != nil {
+		return 0, err
+	}
+	balance, err := caller.BalanceOf(&bind.CallOpts{Context: ctx, BlockNumber: block}, holder)
+	if err != nil {
+		return 0, fmt.Errorf("balanceOf(%s): %w", holder.Hex(), err)
+	}
+	if balance.Sign() == 0 {
+		return 0, fmt.Errorf("%s holds no tokens, so its balance slot cannot be told apart", holder.Hex())
+	}
+	for slot := uint64(0); slot <= maxSlot; slot++ {
+		word, err := client.StorageAt(ctx, tokenAddr, Balance(holder, slot).Key, block)
+		if err != nil {
+			return 0, fmt.Errorf("read slot: %w", err)
+		}
+		if new(big.Int).SetBytes(word).Cmp(balance) == 0 {
+			return slot, nil
+		}
+	}
+	return 0, fmt.Errorf("no balances mapping found in slots 0-%d of %s", maxSlot, tokenAddr.Hex())
+}
@@ -0,0 +1,292 @@
+// Package reorg detects chain reorganisations from a stream of new heads. A
+// Detector keeps a sliding window of canonical block hashes; a head that
+// does not extend the tip is walked back by parent hash to the common
+// ancestor, and the replaced and adopted branches are reported with the
+// transactions they affect.
+package reorg
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ChainReader is the part of ethclient.Client the detector needs.
+type ChainReader interface {
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+}
+
+// Config tunes a Detector. Zero fields take the defaults.
+type Config struct {
+	Window int // canonical blocks remembered, bounding the detectable depth (default 128)
+	// SkipTransactions leaves Event.Txs empty instead of fetching the
+	// blocks of both branches.
+	SkipTransactions bool
+}
+
+// Block identifies one block of a branch.
+type Block struct {
+	Number     uint64      `json:"number"`
+	Hash       common.Hash `json:"hash"`
+	ParentHash common.Hash `json:"parentHash"`
+	Time       uint64      `json:"time"`
+}
+
+func blockOf(h *types.Header) Block {
+	return Block{Number: h.Number.Uint64(), Hash: h.Hash(), ParentHash: h.ParentHash, Time: h.Time}
+}
+
+// TxChange is a transaction of the replaced branch.
+type TxChange struct {
+	Hash     common.Hash `json:"hash"`
+	OldBlock uint64      `json:"oldBlock"`
+	NewBlock uint64      `json:"newBlock,omitempty"` // 0 when the new branch does not include it
+}
+
+// Dropped reports whether the new branch no longer includes the transaction.
+func (t TxChange) Dropped() bool { return t.NewBlock == 0 }
+
+// Event describes one reorg.
+type Event struct {
+	Time     time.Time  `json:"time"`
+	Depth    int        `json:"depth"` // blocks replaced
+	Ancestor Block      `json:"ancestor"`
+	Old      []Block    `json:"old"` // replaced branch, oldest first
+	New      []Block    `json:"new"` // adopted branch, oldest first
+	Txs      []TxChange `json:"txs,omitempty"`
+	// TxsIncomplete is set when a block of either branch could not be
+	// fetched, so Txs may miss transactions.
+	TxsIncomplete bool `json:"txsIncomplete,omitempty"`
+}
+
+// DroppedTxs returns the number of transactions missing from the new branch.
+func (e *Event) DroppedTxs() int {
+	n := 0
+	for _, t := range e.Txs {
+		if t.Dropped() {
+			n++
+		}
+	}
+	return n
+}
+
+func (e *Event) String() string {
+	return fmt.Sprintf("reorg of depth %d at block %d: %s -> %s (%d txs affected, %d dropped)",
+		e.Depth, e.Ancestor.Number+1, e.Old[len(e.Old)-1].Hash.Hex(), e.New[len(e.New)-1].Hash.Hex(), len(e.Txs), e.DroppedTxs())
+}
+
+// Stats are the detector's monitoring counters.
+type Stats struct {
+	Heads      uint64    `json:"heads"`      // headers passed to Add
+	Duplicates uint64    `json:"duplicates"` // headers already in the window
+	Filled     uint64    `json:"filled"`     // missed blocks fetched by parent hash
+	Reorgs     uint64    `json:"reorgs"`
+	MaxDepth   int       `json:"maxDepth"`
+	Replaced   uint64    `json:"replaced"`   // blocks replaced across all reorgs
+	DroppedTxs uint64    `json:"droppedTxs"` // transactions not re-included
+	Resets     uint64    `json:"resets"`     // heads whose ancestor lay outside the window
+	TipNumber  uint64    `json:"tipNumber"`
+	TipHash    string    `json:"tipHash"`
+	LastReorg  time.Time `json:"lastReorg"`
+}
+
+// Detector tracks the canonical chain. Add is meant to be called from one
+// goroutine; Stats may be called concurrently.
+type Detector struct {
+	client ChainReader
+	cfg    Config
+
+	mu     sync.Mutex
+	window []Block // canonical, oldest first, consecutive numbers
+	stats  Stats
+}
+
+// NewDetector returns a Detector with an empty window.
+func NewDetector(client ChainReader, cfg Config) *Detector {
+	if cfg.Window <= 0 {
+		cfg.Window = 128
+	}
+	return &Detector{client: client, cfg: cfg}
+}
+
+// Stats returns a snapshot of the counters.
+func (d *Detector) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats
+}
+
+// Tip returns the current canonical head, if any.
+func (d *Detector) Tip() (Block, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.window) == 0 {
+		return Block{}, false
+	}
+	return d.window[len(d.window)-1], true
+}
+
+// Add processes a new head. It returns the reorg the head reveals, or nil
+// when the head extends the chain (filling in any skipped blocks), was
+// already seen, or branches off below the window, in which case the window
+// restarts from the head.
+func (d *Detector) Add(ctx context.Context, head *types.Header) (*Event, error) {
+	d.mu.Lock()
+	d.stats.Heads++
+	window := d.window
+	d.mu.Unlock()
+
+	b := blockOf(head)
+	if len(window) == 0 {
+		d.commit(0, []Block{b}, nil)
+		return nil, nil
+	}
+	tip := window[len(window)-1]
+	if b.ParentHash == tip.Hash {
+		d.commit(len(window), []Block{b}, nil)
+		return nil, nil
+	}
+	if d.index(window, b) >= 0 {
+		d.mu.Lock()
+		d.stats.Duplicates++
+		d.mu.Unlock()
+		return nil, nil
+	}
+
+	// Walk the new head's ancestry back until it meets the window. A head
+	// too far ahead, or one whose ancestor lies below the window, restarts it.
+	if b.Number > tip.Number+uint64(d.cfg.Window) {
+		d.reset(b)
+		return nil, nil
+	}
+	branch := []Block{b}
+	for {
+		cur := branch[0]
+		if cur.Number <= window[0].Number {
+			d.reset(b)
+			return nil, nil
+		}
+		if i := d.indexHash(window, cur.ParentHash, cur.Number-1); i >= 0 {
+			return d.resolve(ctx, window, i, branch)
+		}
+		parent, err := d.client.HeaderByHash(ctx, cur.ParentHash)
+		if err != nil {
+			return nil, fmt.Errorf("fetch parent %s of block %d: %w", cur.ParentHash.Hex(), cur.Number, err)
+		}
+		branch = append([]Block{blockOf(parent)}, branch...)
+	}
+}
+
+// resolve installs branch on top of window[ancestor] and reports a reorg if
+// that replaced any blocks.
+func (d *Detector) resolve(ctx context.Context, window []Block, ancestor int, branch []Block) (*Event, error) {
+	old := append([]Block(nil), window[ancestor+1:]...)
+	filled := uint64(len(branch) - 1)
+	if len(old) == 0 {
+		d.commit(ancestor+1, branch, func(s *Stats) { s.Filled += filled })
+		return nil, nil
+	}
+
+	ev := &Event{
+		Time:     time.Now().UTC(),
+		Depth:    len(old),
+		Ancestor: window[ancestor],
+		Old:      old,
+		New:      branch,
+	}
+	if !d.cfg.SkipTransactions {
+		ev.Txs, ev.TxsIncomplete = d.affected(ctx, old, branch)
+	}
+	d.commit(ancestor+1, branch, func(s *Stats) {
+		s.Reorgs++
+		s.Replaced += uint64(ev.Depth)
+		s.MaxDepth = max(s.MaxDepth, ev.Depth)
+		s.DroppedTxs += uint64(ev.DroppedTxs())
+		s.LastReorg = ev.Time
+	})
+	return ev, nil
+}
+
+// affected lists the transactions of the old branch and where, if anywhere,
+// the new branch includes them.
+func (d *Detector) affected(ctx context.Context, old, branch []Block) ([]TxChange, bool) {
+	incomplete := false
+	included := make(map[common.Hash]uint64)
+	for _, b := range branch {
+		block, err := d.client.BlockByHash(ctx, b.Hash)
+		if err != nil {
+			incomplete = true
+			continue
+		}
+		for _, tx := range block.Transactions() {
+			included[tx.Hash()] = b.Number
+		}
+	}
+	var txs []TxChange
+	for _, b := range old {
+		block, err := d.client.BlockByHash(ctx, b.Hash)
+		if err != nil {
+			incomplete = true
+			continue
+		}
+		for _, tx := range block.Transactions() {
+			txs = append(txs, TxChange{Hash: tx.Hash(), OldBlock: b.Number, NewBlock: included[tx.Hash()]})
+		}
+	}
+	return txs, incomplete
+}
+
+// commit replaces window[keep:] with blocks and trims the window to size.
+func (d *Detector) commit(keep int, blocks []Block, update func(*Stats)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.window = append(d.window[:keep:keep], blocks...)
+	if over := len(d.window) - d.cfg.Window; over > 0 {
+		d.window = append([]Block(nil), d.window[over:]...)
+	}
+	if update != nil {
+		update(&d.stats)
+	}
+	tip := d.window[len(d.window)-1]
+	d.stats.TipNumber, d.stats.TipHash = tip.Number, tip.Hash.Hex()
+}
+
+func (d *Detector) reset(b Block) {
+	d.commit(0, []Block{b}, func(s *Stats) { s.Resets++ })
+}
+
+func (d *Detector) index(window []Block, b Block) int {
+	return d.indexHash(window, b.Hash, b.Number)
+}
+
+// indexHash finds hash at height number in the window.
+func (d *Detector) indexHash(window []Block, hash common.Hash, number uint64) int {
+	if len(window) == 0 || number < window[0].Number {
+		return -1
+	}
+	i := int(number - window[0].Number)
+	if i >= len(window) || window[i].Hash != hash {
+		return -1
+	}
+	return i
+}
+
+// Seed fills the window with head and its ancestors, so a reorg right after
+// startup is detected at its full depth.
+func (d *Detector) Seed(ctx context.Context, head *types.Header) error {
+	blocks := []Block{blockOf(head)}
+	for len(blocks) < d.cfg.Window && blocks[0].Number > 0 {
+		parent, err := d.client.HeaderByHash(ctx, blocks[0].ParentHash)
+		if err != nil {
+			return fmt.Errorf("fetch parent of block %d: %w", blocks[0].Number, err)
+		}
+		blocks = append([]Block{blockOf(parent)}, blocks...)
+	}
+	d.commit(0, blocks, nil)
+	return nil
+}
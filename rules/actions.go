@@ -0,0 +1,257 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"os/exec"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/obingo31/go-eth/sigdb"
+	"github.com/obingo31/go-eth/transactor"
+	"github.com/obingo31/go-eth/webhook"
+)
+
+// runTransaction renders the transaction templates, packs the calldata and
+// sends it through transactor with the pending nonce. It does not wait for
+// the transaction to be mined.
+func (e *Engine) runTransaction(ctx context.Context, r *Rule, data Data) (string, error) {
+	a := &r.Action
+	toText, err := a.render("to", data)
+	if err != nil {
+		return "", err
+	}
+	to, err := e.resolve(strings.TrimSpace(toText))
+	if err != nil {
+		return "", fmt.Errorf("to: %w", err)
+	}
+
+	value := new(big.Int)
+	if text, err := a.render("value", data); err != nil {
+		return "", err
+	} else if text = strings.TrimSpace(text); text != "" {
+		if _, ok := value.SetString(text, 0); !ok {
+			return "", fmt.Errorf("value %q is not an integer amount of wei", text)
+		}
+	}
+
+	var calldata []byte
+	if a.Transaction.Signature != "" {
+		args := make([]string, len(a.Transaction.Args))
+		for i := range args {
+			if args[i], err = a.render(fmt.Sprintf("arg%d", i), data); err != nil {
+				return "", err
+			}
+		}
+		if calldata, err = packCall(a.Transaction.Signature, args); err != nil {
+			return "", err
+		}
+	} else {
+		text, err := a.render("data", data)
+		if err != nil {
+			return "", err
+		}
+		calldata = common.FromHex(strings.TrimSpace(text))
+	}
+
+	auth, err := transactor.New(ctx, e.client.Client, e.opts.Key, a.Transaction.GasLimit)
+	if err != nil {
+		return "", err
+	}
+	auth.Value = value
+	contract := bind.NewBoundContract(to, abi.ABI{}, e.client, e.client, e.client)
+	tx, err := contract.RawTransact(auth, calldata)
+	if err != nil {
+		return "", err
+	}
+	return "sent " + tx.Hash().Hex(), nil
+}
+
+// packCall encodes a call to a text signature such as
+// "transfer(address,uint256)" with arguments given as text.
+func packCall(signature string, args []string) ([]byte, error) {
+	canonical, err := sigdb.Canonical(signature)
+	if err != nil {
+		return nil, err
+	}
+	inputs, err := sigdb.Signature{Kind: sigdb.Function, Text: canonical}.Inputs()
+	if err != nil {
+		return nil, err
+	}
+	if len(args) != len(inputs) {
+		return nil, fmt.Errorf("%s takes %d arguments, got %d", canonical, len(inputs), len(args))
+	}
+	values := make([]interface{}, len(args))
+	for i, in := range inputs {
+		if values[i], err = convertArg(in.Type, strings.TrimSpace(args[i])); err != nil {
+			return nil, fmt.Errorf("argument %d (%s): %w", i, in.Type, err)
+		}
+	}
+	packed, err := inputs.Pack(values...)
+	if err != nil {
+		return nil, err
+	}
+	return append(crypto.Keccak256([]byte(canonical))[:4], packed...), nil
+}
+
+// convertArg turns text into the Go value abi packing expects for t.
+// Numbers may be decimal or 0x hex; fixed bytes may be hex or short text.
+func convertArg(t abi.Type, s string) (interface{}, error) {
+	switch t.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("%q is not an address", s)
+		}
+		return common.HexToAddress(s), nil
+	case abi.BoolTy:
+		return strconv.ParseBool(s)
+	case abi.StringTy:
+		return s, nil
+	case abi.BytesTy:
+		return common.FromHex(s), nil
+	case abi.FixedBytesTy:
+		raw := []byte(s)
+		if strings.HasPrefix(s, "0x") {
+			raw = common.FromHex(s)
+		}
+		if len(raw) > t.Size {
+			return nil, fmt.Errorf("%q is longer than %d bytes", s, t.Size)
+		}
+		v := reflect.New(t.GetType()).Elem()
+		reflect.Copy(v, reflect.ValueOf(raw))
+		return v.Interface(), nil
+	case abi.IntTy, abi.UintTy:
+		n, ok := new(big.Int).SetString(s, 0)
+		if !ok {
+			return nil, fmt.Errorf("%q is not an integer", s)
+		}
+		if t.Size > 64 {
+			return n, nil
+		}
+		v := reflect.New(t.GetType()).Elem()
+		if t.T == abi.UintTy {
+			if n.Sign() < 0 || n.BitLen() > t.Size {
+				return nil, fmt.Errorf("%s overflows %s", s, t)
+			}
+			v.SetUint(n.Uint64())
+		} else {
+			if !n.IsInt64() || v.OverflowInt(n.Int64()) {
+				return nil, fmt.Errorf("%s overflows %s", s, t)
+			}
+			v.SetInt(n.Int64())
+		}
+		return v.Interface(), nil
+	}
+	return nil, fmt.Errorf("type %s is not supported in rule arguments", t)
+}
+
+// runWebhook posts the relay's payload, extended with the rule name and the
+// match, with the relay's signature headers, so cmd/webhook-receiver and
+// other relay consumers accept it. Network errors and 5xx responses are
+// retried a few times.
+func (e *Engine) runWebhook(ctx context.Context, r *Rule, data Data) (string, error) {
+	url, err := r.Action.render("url", data)
+	if err != nil {
+		return "", err
+	}
+	payload := struct {
+		webhook.Payload
+		Rule  string `json:"rule"`
+		Match Data   `json:"match"`
+	}{Payload: webhook.Payload{ID: data.ID}, Rule: r.Name, Match: data}
+	if data.record != nil {
+		payload.Event = *data.record
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	backoff := 500 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		status, err := e.post(ctx, url, r.Action.Webhook.Secret, data.ID, body)
+		if err == nil && status < 300 {
+			return fmt.Sprintf("%s answered %d", url, status), nil
+		}
+		if err == nil {
+			err = fmt.Errorf("%s answered %d", url, status)
+			if status < 500 && status != http.StatusTooManyRequests {
+				return "", err
+			}
+		}
+		if attempt == 3 {
+			return "", err
+		}
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return "", err
+		}
+	}
+}
+
+func (e *Engine) post(ctx context.Context, url, secret, id string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.EventIDHeader, id)
+	req.Header.Set(webhook.TimestampHeader, timestamp)
+	if secret != "" {
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign(secret, timestamp, body))
+	}
+	resp, err := e.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	return resp.StatusCode, nil
+}
+
+// runCommand executes the rendered argv directly (no shell) with the match
+// in RULE_NAME, EVENT_ID and EVENT_JSON. If argv starts a shell, templates in
+// its script reopen the injection this avoids; see Action.
+func (e *Engine) runCommand(ctx context.Context, r *Rule, data Data) (string, error) {
+	argv := make([]string, len(r.Action.Command))
+	for i := range argv {
+		var err error
+		if argv[i], err = r.Action.render(fmt.Sprintf("command%d", i), data); err != nil {
+			return "", err
+		}
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Env = append(os.Environ(), "RULE_NAME="+r.Name, "EVENT_ID="+data.ID, "EVENT_JSON="+string(payload))
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if len(output) > 200 {
+		output = output[:200] + "..."
+	}
+	if err != nil {
+		if output != "" {
+			return "", fmt.Errorf("%w: %s", err, output)
+		}
+		return "", err
+	}
+	return fmt.Sprintf("%s exited 0: %s", argv[0], output), nil
+}
@@ -0,0 +1,278 @@
+// Package rules evaluates YAML rules against decoded contract events and new
+// heads and runs an action for every match: a templated transaction, a
+// webhook call or a local command. Each action runs at most once per log
+// (or head), tracked in a journal that survives restarts.
+package rules
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Events the engine can watch, each through its abigen binding.
+const (
+	EventTransfer = "Transfer" // token.Token WatchTransfer (ERC-20)
+	EventItemSet  = "ItemSet"  // store.Store WatchItemSet
+)
+
+// Config is the top level of a rules file.
+type Config struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Refs lists the contract references the rules resolve up front: event
+// contracts and literal transaction recipients. Templated recipients are
+// only known once rendered.
+func (c *Config) Refs() []string {
+	var refs []string
+	for _, r := range c.Rules {
+		if r.On.Contract != "" {
+			refs = append(refs, r.On.Contract)
+		}
+		if tx := r.Action.Transaction; tx != nil && !strings.Contains(tx.To, "{{") {
+			refs = append(refs, tx.To)
+		}
+	}
+	return refs
+}
+
+// Rule pairs a trigger and its conditions with an action.
+type Rule struct {
+	Name   string   `yaml:"name"`
+	On     Trigger  `yaml:"on"`
+	Where  []string `yaml:"where"`
+	Action Action   `yaml:"action"`
+
+	conditions []condition
+}
+
+// Trigger selects what a rule looks at: an event of a contract (an address
+// or a token list symbol), or every new head.
+type Trigger struct {
+	Contract string `yaml:"contract"`
+	Event    string `yaml:"event"`
+	Head     bool   `yaml:"head"`
+}
+
+// Action is exactly one of a transaction, a webhook or a command. String
+// fields are text/template templates over the matched event (see Data).
+// Event values come from whoever sent the transaction: a Command may pass
+// them as separate arguments, but must never render them into shell source
+// (sh -c); a shell script reads them from $EVENT_JSON instead.
+type Action struct {
+	Transaction *TxAction      `yaml:"transaction"`
+	Webhook     *WebhookAction `yaml:"webhook"`
+	Command     []string       `yaml:"command"`
+	Timeout     time.Duration  `yaml:"timeout"` // default 30s
+
+	templates map[string]*template.Template
+}
+
+// TxAction sends a transaction signed with the daemon's key. The calldata is
+// either Data (hex) or Signature packed with Args.
+type TxAction struct {
+	To        string   `yaml:"to"`
+	Value     string   `yaml:"value"` // wei
+	Signature string   `yaml:"signature"`
+	Args      []string `yaml:"args"`
+	Data      string   `yaml:"data"`
+	GasLimit  uint64   `yaml:"gasLimit"` // 0 estimates
+}
+
+// WebhookAction posts the match as JSON, signed like the webhook relay when
+// Secret is set.
+type WebhookAction struct {
+	URL    string `yaml:"url"`
+	Secret string `yaml:"secret"`
+}
+
+// Kind names the action type.
+func (a Action) Kind() string {
+	switch {
+	case a.Transaction != nil:
+		return "transaction"
+	case a.Webhook != nil:
+		return "webhook"
+	default:
+		return "command"
+	}
+}
+
+// Load reads a rules file, expanding ${VAR} references from the environment
+// first, and validates every rule. Bare $VAR is left alone so commands can
+// still use shell variables such as $RULE_NAME.
+func Load(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(expandEnv(string(raw))), &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(cfg.Rules) == 0 {
+		return nil, fmt.Errorf("%s defines no rules", path)
+	}
+	names := make(map[string]bool)
+	for i, r := range cfg.Rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[r.Name] {
+			return nil, fmt.Errorf("duplicate rule name %q", r.Name)
+		}
+		names[r.Name] = true
+		if err := r.compile(); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+	}
+	return &cfg, nil
+}
+
+func (r *Rule) compile() error {
+	switch {
+	case r.On.Head && (r.On.Contract != "" || r.On.Event != ""):
+		return fmt.Errorf("on: head cannot be combined with contract or event")
+	case r.On.Head:
+	case r.On.Contract == "":
+		return fmt.Errorf("on: contract is required")
+	case r.On.Event != EventTransfer && r.On.Event != EventItemSet:
+		return fmt.Errorf("on: event must be %s or %s (or use head: true)", EventTransfer, EventItemSet)
+	}
+
+	for _, w := range r.Where {
+		c, err := parseCondition(w)
+		if err != nil {
+			return err
+		}
+		r.conditions = append(r.conditions, c)
+	}
+
+	a := &r.Action
+	set := 0
+	for _, ok := range []bool{a.Transaction != nil, a.Webhook != nil, len(a.Command) > 0} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("action must set exactly one of transaction, webhook or command")
+	}
+	if a.Timeout <= 0 {
+		a.Timeout = 30 * time.Second
+	}
+
+	fields := make(map[string]string)
+	switch {
+	case a.Transaction != nil:
+		tx := a.Transaction
+		if tx.To == "" {
+			return fmt.Errorf("transaction: to is required")
+		}
+		if tx.Data != "" && tx.Signature != "" {
+			return fmt.Errorf("transaction: set data or signature, not both")
+		}
+		fields["to"], fields["value"], fields["data"] = tx.To, tx.Value, tx.Data
+		for i, arg := range tx.Args {
+			fields[fmt.Sprintf("arg%d", i)] = arg
+		}
+	case a.Webhook != nil:
+		if a.Webhook.URL == "" {
+			return fmt.Errorf("webhook: url is required")
+		}
+		fields["url"] = a.Webhook.URL
+	default:
+		for i, arg := range a.Command {
+			fields[fmt.Sprintf("command%d", i)] = arg
+		}
+	}
+	a.templates = make(map[string]*template.Template, len(fields))
+	for name, text := range fields {
+		t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return fmt.Errorf("action %s: %w", name, err)
+		}
+		a.templates[name] = t
+	}
+	return nil
+}
+
+// render executes the named action template.
+func (a *Action) render(name string, data Data) (string, error) {
+	t, ok := a.templates[name]
+	if !ok {
+		return "", nil
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("render %s: %w", name, err)
+	}
+	return b.String(), nil
+}
+
+var envRE = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+func expandEnv(s string) string {
+	return envRE.ReplaceAllStringFunc(s, func(ref string) string {
+		return os.Getenv(ref[2 : len(ref)-1])
+	})
+}
+
+// condition is one "field op value" clause of a rule's where list.
+type condition struct {
+	field, op, value string
+}
+
+var conditionRE = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*(==|!=|>=|<=|>|<)\s*(\S+)\s*$`)
+
+func parseCondition(s string) (condition, error) {
+	m := conditionRE.FindStringSubmatch(s)
+	if m == nil {
+		return condition{}, fmt.Errorf("bad condition %q (want <field> <op> <value>, op one of == != > >= < <=)", s)
+	}
+	return condition{field: m[1], op: m[2], value: m[3]}, nil
+}
+
+// match evaluates the clause against the fields of an event or head.
+// Numbers (decimal or 0x hex) compare numerically; anything else only
+// supports == and !=, case-insensitively (so addresses match regardless
+// of checksum casing).
+func (c condition) match(fields map[string]string) (bool, error) {
+	got, ok := fields[c.field]
+	if !ok {
+		return false, fmt.Errorf("no field %q", c.field)
+	}
+	a, aok := new(big.Int).SetString(got, 0)
+	b, bok := new(big.Int).SetString(c.value, 0)
+	if aok && bok {
+		cmp := a.Cmp(b)
+		switch c.op {
+		case "==":
+			return cmp == 0, nil
+		case "!=":
+			return cmp != 0, nil
+		case ">":
+			return cmp > 0, nil
+		case ">=":
+			return cmp >= 0, nil
+		case "<":
+			return cmp < 0, nil
+		default:
+			return cmp <= 0, nil
+		}
+	}
+	switch c.op {
+	case "==":
+		return strings.EqualFold(got, c.value), nil
+	case "!=":
+		return !strings.EqualFold(got, c.value), nil
+	}
+	return false, fmt.Errorf("%s %s %s: not numbers", got, c.op, c.value)
+}
@@ -0,0 +1,376 @@
+package rules
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+
+	store "github.com/obingo31/go-eth/contracts"
+	"github.com/obingo31/go-eth/logdecode"
+	"github.com/obingo31/go-eth/storestate"
+	"github.com/obingo31/go-eth/subscription"
+	"github.com/obingo31/go-eth/token"
+	"github.com/obingo31/go-eth/tokenlist"
+	"github.com/obingo31/go-eth/webhook"
+)
+
+// Data is what conditions and action templates see. Args holds the decoded
+// event parameters rendered as text (addresses checksummed, numbers in
+// decimal, bytes as hex); for heads it holds number, hash, parentHash,
+// baseFee, gasUsed, gasLimit, time and miner.
+type Data struct {
+	Rule    string            `json:"rule"`
+	ID      string            `json:"id"` // "<blockHash>:<logIndex>", or "head:<hash>"
+	Event   string            `json:"event"`
+	Address string            `json:"address,omitempty"`
+	Block   uint64            `json:"block"`
+	TxHash  string            `json:"txHash,omitempty"`
+	Args    map[string]string `json:"args"`
+
+	record *logdecode.Record // the decoded log; nil for heads
+}
+
+// fields are what where clauses can reference: the args plus block, address
+// and txHash unless an arg already uses the name.
+func (d Data) fields() map[string]string {
+	out := map[string]string{"block": strconv.FormatUint(d.Block, 10), "address": d.Address, "txHash": d.TxHash}
+	for k, v := range d.Args {
+		out[k] = v
+	}
+	return out
+}
+
+var templateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	// text decodes a bytes32 hex value written from a short string.
+	"text": func(s string) string {
+		b := common.FromHex(s)
+		if len(b) != 32 {
+			return s
+		}
+		if t, ok := storestate.Text([32]byte(b)); ok {
+			return t
+		}
+		return s
+	},
+}
+
+// Options configure an Engine.
+type Options struct {
+	Decoder  *logdecode.Decoder
+	Resolver *tokenlist.Resolver // resolves token symbols in on.contract and transaction.to
+	Journal  *Journal
+	Key      *ecdsa.PrivateKey // signs transaction actions
+	DryRun   bool              // log matches without running or journaling actions
+	Logf     func(format string, args ...interface{})
+}
+
+// Engine watches the rules' triggers and runs their actions.
+type Engine struct {
+	cfg    *Config
+	client *subscription.Client
+	opts   Options
+	http   *http.Client
+
+	contracts map[*Rule]common.Address
+}
+
+type match struct {
+	rule *Rule
+	data Data
+}
+
+// New resolves the rules' contracts and checks that every action can run.
+func New(cfg *Config, client *subscription.Client, opts Options) (*Engine, error) {
+	if opts.Decoder == nil {
+		opts.Decoder = logdecode.New()
+		if err := opts.Decoder.AddBindings(); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Journal == nil && !opts.DryRun {
+		return nil, fmt.Errorf("a journal is required")
+	}
+	if opts.Logf == nil {
+		opts.Logf = log.Printf
+	}
+	e := &Engine{
+		cfg:       cfg,
+		client:    client,
+		opts:      opts,
+		http:      &http.Client{},
+		contracts: make(map[*Rule]common.Address),
+	}
+	for _, r := range cfg.Rules {
+		if r.Action.Transaction != nil && opts.Key == nil && !opts.DryRun {
+			return nil, fmt.Errorf("rule %q sends transactions but no private key is configured", r.Name)
+		}
+		if r.On.Head {
+			continue
+		}
+		addr, err := e.resolve(r.On.Contract)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+		e.contracts[r] = addr
+	}
+	return e, nil
+}
+
+func (e *Engine) resolve(ref string) (common.Address, error) {
+	if common.IsHexAddress(ref) {
+		return common.HexToAddress(ref), nil
+	}
+	if e.opts.Resolver == nil {
+		return common.Address{}, fmt.Errorf("%q is not an address and no token list is loaded", ref)
+	}
+	return e.opts.Resolver.Resolve(ref)
+}
+
+// Run watches every rule's trigger until ctx is done. Matches are handled
+// one at a time, in arrival order.
+func (e *Engine) Run(ctx context.Context) error {
+	matches := make(chan match, 64)
+	for _, r := range e.cfg.Rules {
+		var err error
+		switch {
+		case r.On.Head:
+			err = e.watchHeads(ctx, r, matches)
+		case r.On.Event == EventTransfer:
+			err = e.watchTransfers(ctx, r, matches)
+		default:
+			err = e.watchItemSets(ctx, r, matches)
+		}
+		if err != nil {
+			return fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+	}
+	for {
+		select {
+		case m := <-matches:
+			e.handle(ctx, m)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// resubscribe keeps a binding subscription alive across connection drops.
+// Events emitted while reconnecting are not replayed.
+func (e *Engine) resubscribe(r *Rule, watch func(ctx context.Context) (event.Subscription, error)) event.Subscription {
+	return event.ResubscribeErr(30*time.Second, func(ctx context.Context, err error) (event.Subscription, error) {
+		if err != nil {
+			e.opts.Logf("rule %s: resubscribing after %v", r.Name, err)
+		}
+		return watch(ctx)
+	})
+}
+
+func (e *Engine) watchTransfers(ctx context.Context, r *Rule, out chan<- match) error {
+	filterer, err := token.NewTokenFilterer(e.contracts[r], e.client)
+	if err != nil {
+		return err
+	}
+	// Equality clauses on from/to become topic filters of the subscription.
+	from, to := r.addressFilter("from"), r.addressFilter("to")
+	sink := make(chan *token.TokenTransfer)
+	sub := e.resubscribe(r, func(ctx context.Context) (event.Subscription, error) {
+		return filterer.WatchTransfer(&bind.WatchOpts{Context: ctx}, sink, from, to)
+	})
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case ev := <-sink:
+				e.emit(ctx, r, ev.Raw, out)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (e *Engine) watchItemSets(ctx context.Context, r *Rule, out chan<- match) error {
+	filterer, err := store.NewStoreFilterer(e.contracts[r], e.client)
+	if err != nil {
+		return err
+	}
+	sink := make(chan *store.StoreItemSet)
+	sub := e.resubscribe(r, func(ctx context.Context) (event.Subscription, error) {
+		return filterer.WatchItemSet(&bind.WatchOpts{Context: ctx}, sink)
+	})
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case ev := <-sink:
+				e.emit(ctx, r, ev.Raw, out)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (e *Engine) watchHeads(ctx context.Context, r *Rule, out chan<- match) error {
+	sink := make(chan *types.Header)
+	sub := e.resubscribe(r, func(ctx context.Context) (event.Subscription, error) {
+		return e.client.SubscribeNewHead(ctx, sink)
+	})
+	go func() {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case h := <-sink:
+				select {
+				case out <- match{rule: r, data: headData(r, h)}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// emit decodes a log delivered by a binding and queues it for evaluation.
+// Logs removed by a reorg never trigger; if an action already ran for one,
+// that is reported since it cannot be undone.
+func (e *Engine) emit(ctx context.Context, r *Rule, l types.Log, out chan<- match) {
+	rec := e.opts.Decoder.Decode(l)
+	data := Data{
+		Rule:    r.Name,
+		ID:      webhook.EventID(rec),
+		Event:   rec.Event,
+		Address: rec.Address.Hex(),
+		Block:   rec.BlockNumber,
+		TxHash:  rec.TxHash.Hex(),
+		Args:    make(map[string]string, len(rec.Args)),
+		record:  &rec,
+	}
+	for i, a := range rec.Args {
+		name := a.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		data.Args[name] = logdecode.FormatValue(a.Value)
+	}
+	if l.Removed {
+		if e.opts.Journal != nil {
+			if status, ok := e.opts.Journal.Status(r.Name + "/" + data.ID); ok {
+				e.opts.Logf("rule %s: %s was removed by a reorg after its action ran (%s)", r.Name, data.ID, status)
+			}
+		}
+		return
+	}
+	select {
+	case out <- match{rule: r, data: data}:
+	case <-ctx.Done():
+	}
+}
+
+func headData(r *Rule, h *types.Header) Data {
+	args := map[string]string{
+		"number":     h.Number.String(),
+		"hash":       h.Hash().Hex(),
+		"parentHash": h.ParentHash.Hex(),
+		"gasUsed":    strconv.FormatUint(h.GasUsed, 10),
+		"gasLimit":   strconv.FormatUint(h.GasLimit, 10),
+		"time":       strconv.FormatUint(h.Time, 10),
+		"miner":      h.Coinbase.Hex(),
+		"baseFee":    "0",
+	}
+	if h.BaseFee != nil {
+		args["baseFee"] = h.BaseFee.String()
+	}
+	return Data{
+		Rule:  r.Name,
+		ID:    "head:" + h.Hash().Hex(),
+		Event: "head",
+		Block: h.Number.Uint64(),
+		Args:  args,
+	}
+}
+
+// addressFilter returns the addresses of "<field> == <address>" clauses.
+func (r *Rule) addressFilter(field string) []common.Address {
+	var out []common.Address
+	for _, c := range r.conditions {
+		if c.field == field && c.op == "==" && common.IsHexAddress(c.value) {
+			out = append(out, common.HexToAddress(c.value))
+		}
+	}
+	return out
+}
+
+// handle evaluates a match and runs its action unless the journal says it
+// already ran.
+func (e *Engine) handle(ctx context.Context, m match) {
+	r, data := m.rule, m.data
+	fields := data.fields()
+	for _, c := range r.conditions {
+		ok, err := c.match(fields)
+		if err != nil {
+			e.opts.Logf("rule %s: %s: %v", r.Name, data.ID, err)
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+
+	key := r.Name + "/" + data.ID
+	if e.opts.DryRun {
+		e.opts.Logf("rule %s: matched %s in block %d (dry run, %s not run)", r.Name, data.ID, data.Block, r.Action.Kind())
+		return
+	}
+	if status, ok := e.opts.Journal.Status(key); ok {
+		e.opts.Logf("rule %s: %s already handled (%s), skipping", r.Name, data.ID, status)
+		return
+	}
+	if err := e.opts.Journal.Record(Entry{Key: key, Rule: r.Name, ID: data.ID, Status: StatusStarted}); err != nil {
+		e.opts.Logf("rule %s: journal: %v", r.Name, err)
+		return
+	}
+
+	actx, cancel := context.WithTimeout(ctx, r.Action.Timeout)
+	detail, err := e.run(actx, r, data)
+	cancel()
+	entry := Entry{Key: key, Rule: r.Name, ID: data.ID, Status: StatusDone, Detail: detail}
+	if err != nil {
+		entry.Status, entry.Detail = StatusFailed, err.Error()
+		e.opts.Logf("rule %s: %s for %s failed: %v", r.Name, r.Action.Kind(), data.ID, err)
+	} else {
+		e.opts.Logf("rule %s: %s for %s: %s", r.Name, r.Action.Kind(), data.ID, detail)
+	}
+	if err := e.opts.Journal.Record(entry); err != nil {
+		e.opts.Logf("rule %s: journal: %v", r.Name, err)
+	}
+}
+
+func (e *Engine) run(ctx context.Context, r *Rule, data Data) (string, error) {
+	switch {
+	case r.Action.Transaction != nil:
+		return e.runTransaction(ctx, r, data)
+	case r.Action.Webhook != nil:
+		return e.runWebhook(ctx, r, data)
+	default:
+		return e.runCommand(ctx, r, data)
+	}
+}
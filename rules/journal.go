@@ -0,0 +1,106 @@
+package rules
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Journal statuses. An action is journaled as started before it runs, so a
+// crash mid-action never repeats it: actions run at most once per key.
+const (
+	StatusStarted = "started"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+// Entry is one line of the journal.
+type Entry struct {
+	Key    string    `json:"key"` // "<rule>/<id>"
+	Rule   string    `json:"rule"`
+	ID     string    `json:"id"`
+	Status string    `json:"status"`
+	Detail string    `json:"detail,omitempty"`
+	Time   time.Time `json:"time"`
+}
+
+// Journal is an append-only NDJSON record of the actions run, keyed by rule
+// and log ID.
+type Journal struct {
+	mu     sync.Mutex
+	f      *os.File
+	status map[string]string
+}
+
+// OpenJournal loads the journal at path, creating it if needed.
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{status: make(map[string]string)}
+	if f, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(f)
+		for n := 1; scanner.Scan(); n++ {
+			var e Entry
+			if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+			}
+			j.status[e.Key] = e.Status
+		}
+		f.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	j.f = f
+	return j, nil
+}
+
+// Status returns the last recorded status of key.
+func (j *Journal) Status(key string) (string, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	s, ok := j.status[key]
+	return s, ok
+}
+
+// Len returns the number of keys recorded.
+func (j *Journal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.status)
+}
+
+// Record appends e and syncs it to disk.
+func (j *Journal) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := j.f.Sync(); err != nil {
+		return err
+	}
+	j.status[e.Key] = e.Status
+	return nil
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	return j.f.Close()
+}
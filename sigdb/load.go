@@ -0,0 +1,228 @@
+package sigdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// AddABI registers every function and named event of an ABI and returns how
+// many were new.
+func (db *DB) AddABI(source string, parsed abi.ABI) int {
+	added := 0
+	for _, m := range parsed.Methods {
+		if ok, _ := db.Add(Function, m.Sig, source); ok {
+			added++
+		}
+	}
+	for _, ev := range parsed.Events {
+		if ev.Anonymous {
+			continue
+		}
+		if ok, _ := db.Add(Event, ev.Sig, source); ok {
+			added++
+		}
+	}
+	return added
+}
+
+// AddJSON registers an ABI given as a bare array or as a build artifact with
+// an "abi" field.
+func (db *DB) AddJSON(source string, r io.Reader) (int, error) {
+	parsed, err := ReadABI(r)
+	if err != nil {
+		return 0, err
+	}
+	return db.AddABI(source, parsed), nil
+}
+
+// ReadABI parses a bare ABI array or a build artifact with an "abi" field.
+func ReadABI(r io.Reader) (abi.ABI, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	if trimmed := bytes.TrimSpace(raw); bytes.HasPrefix(trimmed, []byte("{")) {
+		var artifact struct {
+			ABI json.RawMessage `json:"abi"`
+		}
+		if err := json.Unmarshal(trimmed, &artifact); err != nil {
+			return abi.ABI{}, err
+		}
+		if len(artifact.ABI) == 0 {
+			return abi.ABI{}, fmt.Errorf("artifact has no abi field")
+		}
+		raw = artifact.ABI
+	}
+	return abi.JSON(bytes.NewReader(raw))
+}
+
+// LoadFiles registers every ABI matched by patterns (see ExpandPatterns) and
+// returns the number of files and of new signatures.
+func (db *DB) LoadFiles(patterns string) (files, added int, err error) {
+	paths, err := ExpandPatterns(patterns)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return 0, 0, err
+		}
+		n, err := db.AddJSON(filepath.Base(path), f)
+		f.Close()
+		if err != nil {
+			return 0, 0, fmt.Errorf("load %s: %w", path, err)
+		}
+		added += n
+	}
+	return len(paths), added, nil
+}
+
+// ExpandPatterns resolves a comma-separated list of globs, files or
+// directories (directories contribute their *.abi and *.json files).
+func ExpandPatterns(patterns string) ([]string, error) {
+	var files []string
+	for _, pattern := range strings.Split(patterns, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			for _, ext := range []string{"*.abi", "*.json"} {
+				matches, _ := filepath.Glob(filepath.Join(pattern, ext))
+				files = append(files, matches...)
+			}
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("bad pattern %q: %w", pattern, err)
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// ImportStats summarises a text import.
+type ImportStats struct {
+	Added      int // new signatures
+	Duplicates int // already known
+	Invalid    int // lines that are not a signature
+	Mismatched int // lines whose ID does not hash from their signature
+}
+
+// Import reads a 4byte-style dump, one entry per line:
+//
+//	0xa9059cbb transfer(address,uint256)
+//	a9059cbb,transfer(address,uint256)
+//	0xddf252ad...b3ef	Transfer(address,address,uint256)
+//	approve(address,uint256)
+//
+// The ID may be separated by spaces, a tab, a comma or a colon. 32-byte IDs
+// are event topics; 4-byte IDs and bare signatures are functions. Blank
+// lines and lines starting with # are skipped.
+func (db *DB) Import(source string, r io.Reader) (ImportStats, error) {
+	var stats ImportStats
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, text := splitEntry(line)
+		kind := Function
+		if len(id) == 32 {
+			kind = Event
+		}
+		canonical, err := Canonical(text)
+		if err != nil {
+			stats.Invalid++
+			continue
+		}
+		if id != nil && !bytes.Equal(id, Signature{Kind: kind, Text: canonical}.ID()) {
+			stats.Mismatched++
+			continue
+		}
+		if ok, _ := db.Add(kind, canonical, source); ok {
+			stats.Added++
+		} else {
+			stats.Duplicates++
+		}
+	}
+	return stats, scanner.Err()
+}
+
+// splitEntry separates a leading 4- or 32-byte hex ID from the signature.
+func splitEntry(line string) ([]byte, string) {
+	body := strings.TrimPrefix(line, "0x")
+	end := strings.IndexFunc(body, func(r rune) bool {
+		return !strings.ContainsRune("0123456789abcdefABCDEF", r)
+	})
+	if end < 0 || end != 8 && end != 64 || !strings.ContainsRune(" \t,:", rune(body[end])) {
+		return nil, line
+	}
+	id, _ := hex.DecodeString(body[:end])
+	return id, strings.TrimLeft(body[end:], " \t,:")
+}
+
+// Open loads a database written by Save. A missing file yields an empty
+// database.
+func Open(path string) (*DB, error) {
+	db := New()
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("%s:%d: want 4 tab-separated fields", path, n)
+		}
+		kind, err := ParseKind(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		if _, err := db.Add(kind, fields[2], fields[3]); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Save writes the database as sorted tab-separated lines (kind, ID,
+// signature, source), replacing path atomically.
+func (db *DB) Save(path string) error {
+	var buf bytes.Buffer
+	buf.WriteString("# kind\tid\tsignature\tsource\n")
+	for _, s := range db.all() {
+		fmt.Fprintf(&buf, "%s\t%s\t%s\t%s\n", s.Kind, hexID(s.ID()), s.Text, s.Source)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
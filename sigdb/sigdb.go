@@ -0,0 +1,220 @@
+// Package sigdb is a local database of function selectors and event topics,
+// built from contract ABIs and 4byte-style text dumps, used to label calls
+// and logs that no loaded ABI describes.
+package sigdb
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultPath is where the commands look for a database built by cmd/sigdb.
+const DefaultPath = "signatures.tsv"
+
+// Kind tells function selectors and event topics apart.
+type Kind uint8
+
+const (
+	Function Kind = iota
+	Event
+)
+
+func (k Kind) String() string {
+	if k == Event {
+		return "event"
+	}
+	return "function"
+}
+
+// MarshalText renders the kind as "function" or "event".
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText is the inverse of MarshalText.
+func (k *Kind) UnmarshalText(text []byte) error {
+	parsed, err := ParseKind(string(text))
+	*k = parsed
+	return err
+}
+
+// ParseKind is the inverse of Kind.String.
+func ParseKind(s string) (Kind, error) {
+	switch s {
+	case "function":
+		return Function, nil
+	case "event":
+		return Event, nil
+	}
+	return 0, fmt.Errorf("unknown signature kind %q", s)
+}
+
+// Signature is one canonical text signature, e.g. "transfer(address,uint256)",
+// and where it was learned from.
+type Signature struct {
+	Kind   Kind   `json:"kind"`
+	Text   string `json:"signature"`
+	Source string `json:"source"`
+}
+
+// Name is the part of the signature before the parameter list.
+func (s Signature) Name() string {
+	name, _, _ := strings.Cut(s.Text, "(")
+	return name
+}
+
+// ID is the selector (functions) or topic (events) of the signature.
+func (s Signature) ID() []byte {
+	hash := crypto.Keccak256([]byte(s.Text))
+	if s.Kind == Function {
+		return hash[:4]
+	}
+	return hash
+}
+
+// Collision is an ID claimed by more than one text signature.
+type Collision struct {
+	Kind       Kind        `json:"kind"`
+	ID         string      `json:"id"`
+	Signatures []Signature `json:"signatures"`
+}
+
+// DB maps selectors and topics to the signatures that produce them. Most IDs
+// have exactly one signature; several mean a collision (or a deliberately
+// crafted clash), which lookups return in full.
+type DB struct {
+	funcs  map[[4]byte][]Signature
+	events map[common.Hash][]Signature
+}
+
+// New returns an empty database.
+func New() *DB {
+	return &DB{
+		funcs:  make(map[[4]byte][]Signature),
+		events: make(map[common.Hash][]Signature),
+	}
+}
+
+// Add registers a text signature, canonicalising whitespace first. It
+// reports false when the signature was already known.
+func (db *DB) Add(kind Kind, text, source string) (bool, error) {
+	text, err := Canonical(text)
+	if err != nil {
+		return false, err
+	}
+	sig := Signature{Kind: kind, Text: text, Source: source}
+	id := sig.ID()
+	if kind == Function {
+		key := [4]byte(id)
+		if contains(db.funcs[key], text) {
+			return false, nil
+		}
+		db.funcs[key] = append(db.funcs[key], sig)
+	} else {
+		key := common.BytesToHash(id)
+		if contains(db.events[key], text) {
+			return false, nil
+		}
+		db.events[key] = append(db.events[key], sig)
+	}
+	return true, nil
+}
+
+func contains(sigs []Signature, text string) bool {
+	for _, s := range sigs {
+		if s.Text == text {
+			return true
+		}
+	}
+	return false
+}
+
+// Function returns the signatures whose selector is sel.
+func (db *DB) Function(sel [4]byte) []Signature {
+	return db.funcs[sel]
+}
+
+// Event returns the signatures whose topic is topic.
+func (db *DB) Event(topic common.Hash) []Signature {
+	return db.events[topic]
+}
+
+// Lookup resolves a hex selector (4 bytes) or topic (32 bytes).
+func (db *DB) Lookup(id string) ([]Signature, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(id), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("bad id %q: %w", id, err)
+	}
+	switch len(raw) {
+	case 4:
+		return db.Function([4]byte(raw)), nil
+	case 32:
+		return db.Event(common.BytesToHash(raw)), nil
+	}
+	return nil, fmt.Errorf("bad id %q: want a 4-byte selector or a 32-byte topic", id)
+}
+
+// Len returns the number of function and event signatures.
+func (db *DB) Len() (functions, events int) {
+	for _, sigs := range db.funcs {
+		functions += len(sigs)
+	}
+	for _, sigs := range db.events {
+		events += len(sigs)
+	}
+	return functions, events
+}
+
+// Collisions lists every ID with more than one signature, functions first,
+// each group ordered by ID.
+func (db *DB) Collisions() []Collision {
+	var out []Collision
+	for sel, sigs := range db.funcs {
+		if len(sigs) > 1 {
+			out = append(out, Collision{Kind: Function, ID: hexID(sel[:]), Signatures: sigs})
+		}
+	}
+	for topic, sigs := range db.events {
+		if len(sigs) > 1 {
+			out = append(out, Collision{Kind: Event, ID: topic.Hex(), Signatures: sigs})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
+// all returns every signature ordered by kind, ID and text.
+func (db *DB) all() []Signature {
+	var out []Signature
+	for _, sigs := range db.funcs {
+		out = append(out, sigs...)
+	}
+	for _, sigs := range db.events {
+		out = append(out, sigs...)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if ida, idb := hexID(a.ID()), hexID(b.ID()); ida != idb {
+			return ida < idb
+		}
+		return a.Text < b.Text
+	})
+	return out
+}
+
+func hexID(id []byte) string {
+	return "0x" + hex.EncodeToString(id)
+}
@@ -0,0 +1,137 @@
+package sigdb
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// Canonical strips whitespace from a text signature and checks that it is a
+// name followed by a list of valid ABI types, so junk lines from dumps are
+// rejected instead of producing meaningless IDs.
+func Canonical(text string) (string, error) {
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, text)
+	open := strings.IndexByte(text, '(')
+	if open <= 0 || !strings.HasSuffix(text, ")") || !validName(text[:open]) {
+		return "", fmt.Errorf("bad signature %q", text)
+	}
+	if _, err := parseArguments(text[open+1 : len(text)-1]); err != nil {
+		return "", fmt.Errorf("bad signature %q: %w", text, err)
+	}
+	return text, nil
+}
+
+// Inputs returns the unnamed parameter list of the signature. Whether event
+// parameters are indexed is not part of a text signature and is left unset.
+func (s Signature) Inputs() (abi.Arguments, error) {
+	open := strings.IndexByte(s.Text, '(')
+	if open < 0 || !strings.HasSuffix(s.Text, ")") {
+		return nil, fmt.Errorf("bad signature %q", s.Text)
+	}
+	return parseArguments(s.Text[open+1 : len(s.Text)-1])
+}
+
+func validName(name string) bool {
+	for i, r := range name {
+		if r == '_' || r == '$' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return name != ""
+}
+
+func parseArguments(list string) (abi.Arguments, error) {
+	if list == "" {
+		return nil, nil
+	}
+	parts, err := splitTopLevel(list)
+	if err != nil {
+		return nil, err
+	}
+	args := make(abi.Arguments, len(parts))
+	for i, part := range parts {
+		m, err := marshaling(fmt.Sprintf("f%d", i), part)
+		if err != nil {
+			return nil, err
+		}
+		typ, err := abi.NewType(m.Type, "", m.Components)
+		if err != nil {
+			return nil, fmt.Errorf("type %q: %w", part, err)
+		}
+		args[i] = abi.Argument{Type: typ}
+	}
+	return args, nil
+}
+
+// marshaling converts one type, possibly a "(...)[]" tuple, into the form
+// abi.NewType expects.
+func marshaling(name, typ string) (abi.ArgumentMarshaling, error) {
+	if !strings.HasPrefix(typ, "(") {
+		if typ == "" {
+			return abi.ArgumentMarshaling{}, fmt.Errorf("empty type")
+		}
+		return abi.ArgumentMarshaling{Name: name, Type: typ}, nil
+	}
+	depth, end := 0, -1
+	for i, r := range typ {
+		if r == '(' {
+			depth++
+		} else if r == ')' {
+			if depth--; depth == 0 {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("unbalanced tuple %q", typ)
+	}
+	m := abi.ArgumentMarshaling{Name: name, Type: "tuple" + typ[end+1:]}
+	if inner := typ[1:end]; inner != "" {
+		parts, err := splitTopLevel(inner)
+		if err != nil {
+			return m, err
+		}
+		for i, part := range parts {
+			c, err := marshaling(fmt.Sprintf("f%d", i), part)
+			if err != nil {
+				return m, err
+			}
+			m.Components = append(m.Components, c)
+		}
+	}
+	return m, nil
+}
+
+// splitTopLevel splits a parameter list on commas outside parentheses.
+func splitTopLevel(s string) ([]string, error) {
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth < 0 {
+				return nil, fmt.Errorf("unbalanced parentheses in %q", s)
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced parentheses in %q", s)
+	}
+	return append(parts, s[start:]), nil
+}
@@ -0,0 +1,177 @@
+// Package storestate rebuilds the contents of a Store contract's items
+// mapping by replaying its ItemSet events, and checks the result against
+// items() at the same block.
+package storestate
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	store "github.com/obingo31/go-eth/contracts"
+	"github.com/obingo31/go-eth/logscan"
+	"github.com/obingo31/go-eth/parallel"
+)
+
+// ItemSetTopic is topic0 of ItemSet(bytes32,bytes32).
+var ItemSetTopic = crypto.Keccak256Hash([]byte("ItemSet(bytes32,bytes32)"))
+
+// Item is the replayed state of one key.
+type Item struct {
+	Key    [32]byte
+	Value  [32]byte
+	Writes int         // ItemSet events seen for the key
+	Block  uint64      // block of the last write
+	TxHash common.Hash // transaction of the last write
+}
+
+// State is the mapping as of Block.
+type State struct {
+	Address common.Address
+	Block   uint64
+	Events  int
+	items   map[[32]byte]*Item
+}
+
+// Replay rebuilds the mapping of the Store at address from the ItemSet
+// events in [from, to], fetched with a logscan scanner configured by cfg.
+func Replay(ctx context.Context, client logscan.Filterer, address common.Address, from, to uint64, cfg logscan.Config) (*State, error) {
+	filterer, err := store.NewStoreFilterer(address, nil)
+	if err != nil {
+		return nil, err
+	}
+	st := &State{Address: address, Block: to, items: make(map[[32]byte]*Item)}
+	q := ethereum.FilterQuery{
+		Addresses: []common.Address{address},
+		Topics:    [][]common.Hash{{ItemSetTopic}},
+	}
+	err = logscan.New(client, cfg).Scan(ctx, q, from, to, func(_, _ uint64, logs []types.Log) error {
+		for _, l := range logs {
+			ev, err := filterer.ParseItemSet(l)
+			if err != nil {
+				return fmt.Errorf("parse ItemSet in tx %s: %w", l.TxHash.Hex(), err)
+			}
+			st.apply(ev)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (s *State) apply(ev *store.StoreItemSet) {
+	item, ok := s.items[ev.Key]
+	if !ok {
+		item = &Item{Key: ev.Key}
+		s.items[ev.Key] = item
+	}
+	item.Value = ev.Value
+	item.Writes++
+	item.Block = ev.Raw.BlockNumber
+	item.TxHash = ev.Raw.TxHash
+	s.Events++
+}
+
+// Len returns the number of keys ever written.
+func (s *State) Len() int {
+	return len(s.items)
+}
+
+// Get returns the replayed state of key.
+func (s *State) Get(key [32]byte) (Item, bool) {
+	item, ok := s.items[key]
+	if !ok {
+		return Item{}, false
+	}
+	return *item, true
+}
+
+// Items returns every key ever written, ordered by key bytes. Keys last set
+// to zero are included, since the mapping cannot tell them from unset keys.
+func (s *State) Items() []Item {
+	out := make([]Item, 0, len(s.items))
+	for _, item := range s.items {
+		out = append(out, *item)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return bytes.Compare(out[i].Key[:], out[j].Key[:]) < 0
+	})
+	return out
+}
+
+// Drift is a key whose replayed value differs from items() on chain.
+type Drift struct {
+	Key      [32]byte
+	Replayed [32]byte
+	OnChain  [32]byte
+}
+
+// Verify reads items(key) at s.Block for every replayed key, with up to
+// concurrency calls in flight, and returns the keys that disagree.
+func (s *State) Verify(ctx context.Context, caller bind.ContractCaller, concurrency int) ([]Drift, error) {
+	instance, err := store.NewStoreCaller(s.Address, caller)
+	if err != nil {
+		return nil, err
+	}
+	opts := bind.CallOpts{BlockNumber: new(big.Int).SetUint64(s.Block)}
+
+	items := s.Items()
+	onChain := make([][32]byte, len(items))
+	err = parallel.Run(ctx, len(items), concurrency, func(ctx context.Context, i int) error {
+		call := opts
+		call.Context = ctx
+		var err error
+		if onChain[i], err = instance.Items(&call, items[i].Key); err != nil {
+			return fmt.Errorf("read items(%s) at block %d: %w", Format(items[i].Key), s.Block, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var drift []Drift
+	for i, item := range items {
+		if onChain[i] != item.Value {
+			drift = append(drift, Drift{Key: item.Key, Replayed: item.Value, OnChain: onChain[i]})
+		}
+	}
+	return drift, nil
+}
+
+// Text decodes a bytes32 written from a short string (left-aligned and
+// zero-padded, as contract_write.go does). It reports false unless the
+// bytes before the padding are non-empty printable UTF-8.
+func Text(b [32]byte) (string, bool) {
+	trimmed := bytes.TrimRight(b[:], "\x00")
+	if len(trimmed) == 0 || bytes.IndexByte(trimmed, 0) >= 0 || !utf8.Valid(trimmed) {
+		return "", false
+	}
+	for _, r := range string(trimmed) {
+		if !unicode.IsPrint(r) {
+			return "", false
+		}
+	}
+	return string(trimmed), true
+}
+
+// Format renders b as quoted text when Text can decode it, else as hex.
+func Format(b [32]byte) string {
+	if s, ok := Text(b); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	return hexutil.Encode(b[:])
+}
@@ -0,0 +1,152 @@
+// Package subscription provides head and log subscriptions that work on any
+// endpoint: native eth_subscribe over WebSocket/IPC, eth_newBlockFilter and
+// eth_newFilter polled with eth_getFilterChanges over HTTP, or plain polling
+// when the node offers no filters.
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Mode selects how subscriptions are served.
+type Mode string
+
+const (
+	ModeAuto   Mode = "auto"   // first of ws, filter, poll that works
+	ModeWS     Mode = "ws"     // eth_subscribe only
+	ModeFilter Mode = "filter" // eth_newBlockFilter / eth_newFilter + eth_getFilterChanges
+	ModePoll   Mode = "poll"   // eth_blockNumber / eth_getLogs polling
+)
+
+// ParseMode validates a --mode style flag value.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case ModeAuto, ModeWS, ModeFilter, ModePoll:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown subscription mode %q (expected auto, ws, filter or poll)", s)
+}
+
+// Options tune the polling fallbacks.
+type Options struct {
+	Mode     Mode          // default ModeAuto
+	Interval time.Duration // polling interval, default 2s
+	Timeout  time.Duration // per RPC call, default 10s
+	// MaxFailures is how many consecutive polling errors end a subscription
+	// (delivered on its Err channel). Default 5.
+	MaxFailures int
+}
+
+// Client is an *ethclient.Client whose SubscribeNewHead and
+// SubscribeFilterLogs fall back to polling when the endpoint cannot push
+// notifications. It satisfies the client interfaces of logstream and the
+// abigen Watch* bindings.
+type Client struct {
+	*ethclient.Client
+	rpc  *rpc.Client
+	opts Options
+
+	mu        sync.Mutex
+	mode      Mode
+	recreated int
+}
+
+// Dial connects to url (ws://, http:// or an IPC path).
+func Dial(ctx context.Context, url string, opts Options) (*Client, error) {
+	c, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c, opts), nil
+}
+
+// NewClient wraps an existing RPC client.
+func NewClient(c *rpc.Client, opts Options) *Client {
+	if opts.Mode == "" {
+		opts.Mode = ModeAuto
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = 2 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.MaxFailures <= 0 {
+		opts.MaxFailures = 5
+	}
+	return &Client{Client: ethclient.NewClient(c), rpc: c, opts: opts}
+}
+
+// Mode reports the mechanism used by the most recent subscription.
+func (c *Client) Mode() Mode {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mode
+}
+
+// FiltersRecreated counts filters reinstalled after the node expired them.
+func (c *Client) FiltersRecreated() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.recreated
+}
+
+func (c *Client) setMode(m Mode) {
+	c.mu.Lock()
+	c.mode = m
+	c.mu.Unlock()
+}
+
+func (c *Client) countRecreated() {
+	c.mu.Lock()
+	c.recreated++
+	c.mu.Unlock()
+}
+
+// call runs one RPC with the configured timeout.
+func (c *Client) call(result interface{}, method string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.Timeout)
+	defer cancel()
+	return c.rpc.CallContext(ctx, result, method, args...)
+}
+
+func (c *Client) callCtx() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.opts.Timeout)
+}
+
+// isFilterNotFound matches the errors nodes return for expired or unknown
+// filter IDs (geth uninstalls filters not polled for five minutes).
+func isFilterNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "filter not found") || strings.Contains(msg, "filter does not exist") || strings.Contains(msg, "unknown filter")
+}
+
+// failures ends a polling subscription after too many consecutive errors.
+type failures struct {
+	max, n int
+}
+
+func (f *failures) record(err error) error {
+	if err == nil {
+		f.n = 0
+		return nil
+	}
+	f.n++
+	if f.n >= f.max {
+		return fmt.Errorf("%d consecutive polling errors: %w", f.n, err)
+	}
+	return nil
+}
+
+var errUnsubscribed = errors.New("unsubscribed")
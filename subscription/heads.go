@@ -0,0 +1,196 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// maxCatchUp bounds how many missed headers are fetched by number after a
+// polling gap.
+const maxCatchUp = 128
+
+// SubscribeNewHead delivers new headers over eth_subscribe when possible,
+// otherwise by polling a block filter, otherwise by polling the head.
+func (c *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	mode := c.opts.Mode
+	if mode == ModeAuto || mode == ModeWS {
+		sub, err := c.Client.SubscribeNewHead(ctx, ch)
+		if err == nil {
+			c.setMode(ModeWS)
+			return sub, nil
+		}
+		if mode == ModeWS {
+			return nil, err
+		}
+	}
+	if mode == ModeAuto || mode == ModeFilter {
+		var id string
+		err := c.rpc.CallContext(ctx, &id, "eth_newBlockFilter")
+		if err == nil {
+			c.setMode(ModeFilter)
+			return event.NewSubscription(func(quit <-chan struct{}) error {
+				return c.pollBlockFilter(id, ch, quit)
+			}), nil
+		}
+		if mode == ModeFilter {
+			return nil, fmt.Errorf("install block filter: %w", err)
+		}
+	}
+
+	head, err := c.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch head: %w", err)
+	}
+	c.setMode(ModePoll)
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		return c.pollHeads(head, ch, quit)
+	}), nil
+}
+
+func (c *Client) pollBlockFilter(id string, ch chan<- *types.Header, quit <-chan struct{}) error {
+	defer func() { c.call(nil, "eth_uninstallFilter", id) }()
+
+	t := newHeadTracker(c, ch, quit)
+	fails := failures{max: c.opts.MaxFailures}
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-quit:
+			return nil
+		case <-ticker.C:
+		}
+
+		var hashes []common.Hash
+		err := c.call(&hashes, "eth_getFilterChanges", id)
+		if isFilterNotFound(err) {
+			if err = c.call(&id, "eth_newBlockFilter"); err == nil {
+				c.countRecreated()
+				// Blocks mined while no filter was installed are fetched by number.
+				var head *types.Header
+				if head, err = t.header(nil); err == nil {
+					err = t.catchUp(head)
+				}
+			}
+		} else if err == nil {
+			for _, hash := range hashes {
+				var header *types.Header
+				ctx, cancel := c.callCtx()
+				header, err = c.HeaderByHash(ctx, hash)
+				cancel()
+				if err != nil {
+					break
+				}
+				if err = t.emit(header); err != nil {
+					break
+				}
+			}
+		}
+		if err == errUnsubscribed {
+			return nil
+		}
+		if err := fails.record(err); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) pollHeads(start *types.Header, ch chan<- *types.Header, quit <-chan struct{}) error {
+	t := newHeadTracker(c, ch, quit)
+	t.mark(start)
+	fails := failures{max: c.opts.MaxFailures}
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-quit:
+			return nil
+		case <-ticker.C:
+		}
+		head, err := t.header(nil)
+		if err == nil {
+			err = t.catchUp(head)
+		}
+		if err == errUnsubscribed {
+			return nil
+		}
+		if err := fails.record(err); err != nil {
+			return err
+		}
+	}
+}
+
+// headTracker emits each header once and fills gaps by number.
+type headTracker struct {
+	c    *Client
+	ch   chan<- *types.Header
+	quit <-chan struct{}
+	last uint64
+	seen map[common.Hash]uint64
+}
+
+func newHeadTracker(c *Client, ch chan<- *types.Header, quit <-chan struct{}) *headTracker {
+	return &headTracker{c: c, ch: ch, quit: quit, seen: make(map[common.Hash]uint64)}
+}
+
+func (t *headTracker) header(number *big.Int) (*types.Header, error) {
+	ctx, cancel := t.c.callCtx()
+	defer cancel()
+	return t.c.HeaderByNumber(ctx, number)
+}
+
+func (t *headTracker) mark(h *types.Header) {
+	n := h.Number.Uint64()
+	t.seen[h.Hash()] = n
+	t.last = max(t.last, n)
+	if len(t.seen) > 4*maxCatchUp {
+		for hash, num := range t.seen {
+			if num+2*maxCatchUp < t.last {
+				delete(t.seen, hash)
+			}
+		}
+	}
+}
+
+func (t *headTracker) emit(h *types.Header) error {
+	if _, ok := t.seen[h.Hash()]; ok {
+		return nil
+	}
+	select {
+	case t.ch <- h:
+	case <-t.quit:
+		return errUnsubscribed
+	}
+	t.mark(h)
+	return nil
+}
+
+// catchUp emits the canonical headers between the last one seen and head
+// (at most maxCatchUp of them), then head itself. A head at or below the
+// last height with a new hash (a reorg) is emitted on its own.
+func (t *headTracker) catchUp(head *types.Header) error {
+	number := head.Number.Uint64()
+	if t.last > 0 && number > t.last+1 {
+		from := t.last + 1
+		if number-from > maxCatchUp {
+			from = number - maxCatchUp
+		}
+		for n := from; n < number; n++ {
+			h, err := t.header(new(big.Int).SetUint64(n))
+			if err != nil {
+				return err
+			}
+			if err := t.emit(h); err != nil {
+				return err
+			}
+		}
+	}
+	return t.emit(head)
+}
@@ -0,0 +1,191 @@
+package subscription
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// SubscribeFilterLogs delivers logs matching q (its block bounds are
+// ignored) over eth_subscribe when possible, otherwise by polling an
+// eth_newFilter, otherwise by polling eth_getLogs for each new block range.
+// Filter polling passes removed logs through; plain polling cannot see them.
+func (c *Client) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	q.FromBlock, q.ToBlock, q.BlockHash = nil, nil, nil
+	mode := c.opts.Mode
+	if mode == ModeAuto || mode == ModeWS {
+		sub, err := c.Client.SubscribeFilterLogs(ctx, q, ch)
+		if err == nil {
+			c.setMode(ModeWS)
+			return sub, nil
+		}
+		if mode == ModeWS {
+			return nil, err
+		}
+	}
+
+	covered, err := c.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch block number: %w", err)
+	}
+	if mode == ModeAuto || mode == ModeFilter {
+		var id string
+		err := c.rpc.CallContext(ctx, &id, "eth_newFilter", filterArg(q))
+		if err == nil {
+			c.setMode(ModeFilter)
+			return event.NewSubscription(func(quit <-chan struct{}) error {
+				return c.pollLogFilter(id, q, covered, ch, quit)
+			}), nil
+		}
+		if mode == ModeFilter {
+			return nil, fmt.Errorf("install log filter: %w", err)
+		}
+	}
+
+	c.setMode(ModePoll)
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		return c.pollLogs(q, covered, ch, quit)
+	}), nil
+}
+
+type logKey struct {
+	block   common.Hash
+	index   uint
+	removed bool
+}
+
+func (c *Client) pollLogFilter(id string, q ethereum.FilterQuery, covered uint64, ch chan<- types.Log, quit <-chan struct{}) error {
+	defer func() { c.call(nil, "eth_uninstallFilter", id) }()
+
+	// recovered holds logs already delivered by a catch-up query, which the
+	// next filter poll may report again.
+	var recovered map[logKey]bool
+	fails := failures{max: c.opts.MaxFailures}
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-quit:
+			return nil
+		case <-ticker.C:
+		}
+
+		// The head is read before the changes, so every log up to it is
+		// included in what the filter returns.
+		var (
+			head    hexutil.Uint64
+			changes []types.Log
+		)
+		batch := []rpc.BatchElem{
+			{Method: "eth_blockNumber", Result: &head},
+			{Method: "eth_getFilterChanges", Args: []interface{}{id}, Result: &changes},
+		}
+		ctx, cancel := c.callCtx()
+		err := c.rpc.BatchCallContext(ctx, batch)
+		cancel()
+		if err == nil {
+			err = batch[0].Error
+		}
+		if err == nil && isFilterNotFound(batch[1].Error) {
+			// Install the new filter first, then fetch what happened since the
+			// last successful poll, so nothing falls in between.
+			if err = c.call(&id, "eth_newFilter", filterArg(q)); err == nil {
+				c.countRecreated()
+				var logs []types.Log
+				if logs, err = c.rangeLogs(q, covered+1, uint64(head)); err == nil {
+					recovered = make(map[logKey]bool, len(logs))
+					for _, l := range logs {
+						recovered[logKey{l.BlockHash, l.Index, l.Removed}] = true
+					}
+					err = emitLogs(ch, quit, logs, nil)
+					covered = max(covered, uint64(head))
+				}
+			}
+		} else if err == nil {
+			if err = batch[1].Error; err == nil {
+				err = emitLogs(ch, quit, changes, recovered)
+				recovered = nil
+				covered = max(covered, uint64(head))
+			}
+		}
+		if err == errUnsubscribed {
+			return nil
+		}
+		if err := fails.record(err); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) pollLogs(q ethereum.FilterQuery, covered uint64, ch chan<- types.Log, quit <-chan struct{}) error {
+	fails := failures{max: c.opts.MaxFailures}
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-quit:
+			return nil
+		case <-ticker.C:
+		}
+
+		ctx, cancel := c.callCtx()
+		head, err := c.BlockNumber(ctx)
+		cancel()
+		if err == nil && head > covered {
+			var logs []types.Log
+			if logs, err = c.rangeLogs(q, covered+1, head); err == nil {
+				if err = emitLogs(ch, quit, logs, nil); err == nil {
+					covered = head
+				}
+			}
+		}
+		if err == errUnsubscribed {
+			return nil
+		}
+		if err := fails.record(err); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) rangeLogs(q ethereum.FilterQuery, from, to uint64) ([]types.Log, error) {
+	if from > to {
+		return nil, nil
+	}
+	q.FromBlock = new(big.Int).SetUint64(from)
+	q.ToBlock = new(big.Int).SetUint64(to)
+	ctx, cancel := c.callCtx()
+	defer cancel()
+	return c.FilterLogs(ctx, q)
+}
+
+func emitLogs(ch chan<- types.Log, quit <-chan struct{}, logs []types.Log, skip map[logKey]bool) error {
+	for _, l := range logs {
+		if skip[logKey{l.BlockHash, l.Index, l.Removed}] {
+			continue
+		}
+		select {
+		case ch <- l:
+		case <-quit:
+			return errUnsubscribed
+		}
+	}
+	return nil
+}
+
+// filterArg mirrors ethclient's filter encoding for eth_newFilter, which
+// only watches blocks from the point of installation.
+func filterArg(q ethereum.FilterQuery) interface{} {
+	return map[string]interface{}{
+		"address": q.Addresses,
+		"topics":  q.Topics,
+	}
+}
@@ -0,0 +1,35 @@
+package token
+
+import (
+	"math/big"
+	"strings"
+)
+
+// FormatUnits renders value scaled down by 10^decimals without going through
+// floating point, e.g. FormatUnits(1500000000000000000, 18) == "1.5".
+// Trailing fractional zeros are trimmed.
+func FormatUnits(value *big.Int, decimals uint8) string {
+	if value == nil {
+		return "0"
+	}
+	sign := ""
+	abs := new(big.Int).Set(value)
+	if abs.Sign() < 0 {
+		sign = "-"
+		abs.Neg(abs)
+	}
+
+	digits := abs.String()
+	if decimals == 0 {
+		return sign + digits
+	}
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+	whole := digits[:len(digits)-int(decimals)]
+	frac := strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
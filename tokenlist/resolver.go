@@ -0,0 +1,126 @@
+package tokenlist
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DefaultPath is the token list bundled with the repository.
+const DefaultPath = "tokenlists/default.tokenlist.json"
+
+// ChainIDReader is the part of *ethclient.Client needed to pick a chain.
+type ChainIDReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Resolver maps symbols and addresses to tokens for a single chain.
+type Resolver struct {
+	chainID   uint64
+	bySymbol  map[string][]Token
+	byAddress map[common.Address]Token
+}
+
+// NewResolver indexes the tokens of lists that belong to chainID. When the
+// same address appears in several lists the first one wins.
+func NewResolver(chainID uint64, lists ...*List) *Resolver {
+	r := &Resolver{
+		chainID:   chainID,
+		bySymbol:  make(map[string][]Token),
+		byAddress: make(map[common.Address]Token),
+	}
+	for _, list := range lists {
+		for _, t := range list.Tokens {
+			if t.ChainID != chainID {
+				continue
+			}
+			addr := t.ContractAddress()
+			if _, ok := r.byAddress[addr]; ok {
+				continue
+			}
+			r.byAddress[addr] = t
+			key := strings.ToUpper(t.Symbol)
+			r.bySymbol[key] = append(r.bySymbol[key], t)
+		}
+	}
+	return r
+}
+
+// Open loads the comma-separated list files in paths and builds a resolver
+// for the chain client is connected to. An empty paths value yields a
+// resolver that only accepts hex addresses.
+func Open(ctx context.Context, client ChainIDReader, paths string) (*Resolver, error) {
+	var lists []*List
+	for _, path := range strings.Split(paths, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		list, err := Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("load token list: %w", err)
+		}
+		lists = append(lists, list)
+	}
+	if len(lists) == 0 {
+		return NewResolver(0), nil
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch chain id: %w", err)
+	}
+	return NewResolver(chainID.Uint64(), lists...), nil
+}
+
+// OpenFor is Open for resolving refs: when every ref is already a hex
+// address it neither reads the lists nor asks for the chain ID, so commands
+// given addresses work from any directory.
+func OpenFor(ctx context.Context, client ChainIDReader, paths string, refs ...string) (*Resolver, error) {
+	for _, ref := range refs {
+		if !common.IsHexAddress(strings.TrimSpace(ref)) {
+			return Open(ctx, client, paths)
+		}
+	}
+	return NewResolver(0), nil
+}
+
+// ChainID returns the chain the resolver was built for (0 without lists).
+func (r *Resolver) ChainID() uint64 {
+	return r.chainID
+}
+
+// Resolve turns a symbol (case-insensitive) or hex address into a contract
+// address. Symbols shared by several listed tokens are rejected as ambiguous.
+func (r *Resolver) Resolve(ref string) (common.Address, error) {
+	ref = strings.TrimSpace(ref)
+	if common.IsHexAddress(ref) {
+		return common.HexToAddress(ref), nil
+	}
+	matches := r.bySymbol[strings.ToUpper(ref)]
+	switch len(matches) {
+	case 0:
+		if r.chainID == 0 {
+			return common.Address{}, fmt.Errorf("%q is not an address and no token list is loaded", ref)
+		}
+		return common.Address{}, fmt.Errorf("token %q not found in token lists for chain %d", ref, r.chainID)
+	case 1:
+		return matches[0].ContractAddress(), nil
+	default:
+		addrs := make([]string, len(matches))
+		for i, t := range matches {
+			addrs[i] = t.ContractAddress().Hex()
+		}
+		sort.Strings(addrs)
+		return common.Address{}, fmt.Errorf("token %q is ambiguous on chain %d: %s", ref, r.chainID, strings.Join(addrs, ", "))
+	}
+}
+
+// Lookup returns the listed metadata for addr, if any.
+func (r *Resolver) Lookup(addr common.Address) (Token, bool) {
+	t, ok := r.byAddress[addr]
+	return t, ok
+}
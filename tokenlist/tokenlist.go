@@ -0,0 +1,150 @@
+// Package tokenlist loads Uniswap Token Lists (https://tokenlists.org) from
+// local files and resolves token symbols to contract addresses and decimals.
+package tokenlist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Version is the semantic version of a list.
+type Version struct {
+	Major int `json:"major"`
+	Minor int `json:"minor"`
+	Patch int `json:"patch"`
+}
+
+// Token is a single tokenInfo entry.
+type Token struct {
+	ChainID    uint64                 `json:"chainId"`
+	Address    string                 `json:"address"`
+	Decimals   int                    `json:"decimals"`
+	Name       string                 `json:"name"`
+	Symbol     string                 `json:"symbol"`
+	LogoURI    string                 `json:"logoURI,omitempty"`
+	Tags       []string               `json:"tags,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// List is a parsed token list document.
+type List struct {
+	Name      string                     `json:"name"`
+	Timestamp string                     `json:"timestamp"`
+	Version   Version                    `json:"version"`
+	Tokens    []Token                    `json:"tokens"`
+	TokenMap  map[string]json.RawMessage `json:"tokenMap,omitempty"`
+	Keywords  []string                   `json:"keywords,omitempty"`
+	Tags      map[string]json.RawMessage `json:"tags,omitempty"`
+	LogoURI   string                     `json:"logoURI,omitempty"`
+}
+
+// ContractAddress returns the token address as a common.Address.
+func (t Token) ContractAddress() common.Address {
+	return common.HexToAddress(t.Address)
+}
+
+// Patterns and limits from tokenlist.schema.json.
+const maxChainID = 1<<53 - 1
+
+var (
+	listNamePattern  = regexp.MustCompile(`^[\w ]+$`)
+	addressPattern   = regexp.MustCompile(`^0x[a-fA-F0-9]{40}$`)
+	tokenNamePattern = regexp.MustCompile(`^[ \S+]+$`)
+	symbolPattern    = regexp.MustCompile(`^\S+$`)
+	tagPattern       = regexp.MustCompile(`^[\w]+$`)
+	keywordPattern   = regexp.MustCompile(`^[\w ]+$`)
+)
+
+// Load reads and validates a token list file.
+func Load(path string) (*List, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var list List
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&list); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := list.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &list, nil
+}
+
+// Validate checks the list against the rules of the token list JSON schema
+// and rejects duplicate chainId/address pairs.
+func (l *List) Validate() error {
+	if n := utf8.RuneCountInString(l.Name); n < 1 || n > 30 || !listNamePattern.MatchString(l.Name) {
+		return fmt.Errorf("invalid list name %q", l.Name)
+	}
+	if _, err := time.Parse(time.RFC3339, l.Timestamp); err != nil {
+		return fmt.Errorf("invalid timestamp %q: %w", l.Timestamp, err)
+	}
+	if l.Version.Major < 0 || l.Version.Minor < 0 || l.Version.Patch < 0 {
+		return fmt.Errorf("invalid version %d.%d.%d", l.Version.Major, l.Version.Minor, l.Version.Patch)
+	}
+	if len(l.Keywords) > 20 {
+		return fmt.Errorf("too many keywords (%d > 20)", len(l.Keywords))
+	}
+	for _, kw := range l.Keywords {
+		if n := utf8.RuneCountInString(kw); n < 1 || n > 20 || !keywordPattern.MatchString(kw) {
+			return fmt.Errorf("invalid keyword %q", kw)
+		}
+	}
+	if len(l.Tokens) < 1 || len(l.Tokens) > 10000 {
+		return fmt.Errorf("list must contain between 1 and 10000 tokens, has %d", len(l.Tokens))
+	}
+
+	seen := make(map[string]bool, len(l.Tokens))
+	for i, t := range l.Tokens {
+		if err := t.validate(); err != nil {
+			return fmt.Errorf("token %d (%s): %w", i, t.Symbol, err)
+		}
+		key := fmt.Sprintf("%d:%s", t.ChainID, strings.ToLower(t.Address))
+		if seen[key] {
+			return fmt.Errorf("token %d (%s): duplicate address %s on chain %d", i, t.Symbol, t.Address, t.ChainID)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func (t Token) validate() error {
+	if t.ChainID < 1 || t.ChainID > maxChainID {
+		return fmt.Errorf("invalid chainId %d", t.ChainID)
+	}
+	if !addressPattern.MatchString(t.Address) {
+		return fmt.Errorf("invalid address %q", t.Address)
+	}
+	if t.Decimals < 0 || t.Decimals > 255 {
+		return fmt.Errorf("decimals %d out of range 0-255", t.Decimals)
+	}
+	if n := utf8.RuneCountInString(t.Name); n > 60 || (n > 0 && !tokenNamePattern.MatchString(t.Name)) {
+		return fmt.Errorf("invalid name %q", t.Name)
+	}
+	if n := utf8.RuneCountInString(t.Symbol); n > 20 || (n > 0 && !symbolPattern.MatchString(t.Symbol)) {
+		return fmt.Errorf("invalid symbol %q", t.Symbol)
+	}
+	if len(t.Tags) > 10 {
+		return fmt.Errorf("too many tags (%d > 10)", len(t.Tags))
+	}
+	for _, tag := range t.Tags {
+		if n := utf8.RuneCountInString(tag); n > 10 || !tagPattern.MatchString(tag) {
+			return fmt.Errorf("invalid tag %q", tag)
+		}
+	}
+	if len(t.Extensions) > 10 {
+		return fmt.Errorf("too many extensions (%d > 10)", len(t.Extensions))
+	}
+	return nil
+}
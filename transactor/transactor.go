@@ -0,0 +1,78 @@
+// Package transactor builds signed-transaction options the same way
+// contract_deploy.go and contract_write.go do, so commands that send
+// transactions share one nonce and fee policy.
+package transactor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DefaultGasLimit matches the fixed limit used by the Store examples.
+const DefaultGasLimit = 500000
+
+// ParseKey decodes a hex private key with or without a 0x prefix.
+func ParseKey(hexKey string) (*ecdsa.PrivateKey, error) {
+	if hexKey == "" {
+		return nil, fmt.Errorf("private key is empty")
+	}
+	return crypto.HexToECDSA(strings.TrimPrefix(hexKey, "0x"))
+}
+
+// New returns transact options for privateKey with the pending nonce and
+// EIP-1559 fees when the node supports eth_maxPriorityFeePerGas, falling back
+// to a legacy gas price otherwise. gasLimit 0 lets the binding estimate gas.
+func New(ctx context.Context, client *ethclient.Client, privateKey *ecdsa.PrivateKey, gasLimit uint64) (*bind.TransactOpts, error) {
+	chainID, err := client.NetworkID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch chain id: %w", err)
+	}
+
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("build transactor: %w", err)
+	}
+
+	nonce, err := client.PendingNonceAt(ctx, auth.From)
+	if err != nil {
+		return nil, fmt.Errorf("fetch nonce: %w", err)
+	}
+	auth.Nonce = new(big.Int).SetUint64(nonce)
+
+	tipCap, err := client.SuggestGasTipCap(ctx)
+	supports1559 := err == nil
+	if err != nil {
+		log.Printf("warn: eth_maxPriorityFeePerGas unavailable, using legacy gas price: %v", err)
+	}
+
+	baseFee, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("suggest gas price: %w", err)
+	}
+
+	if supports1559 {
+		auth.GasTipCap = tipCap
+		auth.GasFeeCap = new(big.Int).Add(baseFee, tipCap)
+	} else {
+		auth.GasPrice = baseFee
+	}
+
+	auth.GasLimit = gasLimit
+	auth.Value = big.NewInt(0)
+	auth.Context = ctx
+	return auth, nil
+}
+
+// Next advances the nonce after a transaction has been sent with auth, so
+// the same options can be reused for a follow-up transaction.
+func Next(auth *bind.TransactOpts) {
+	auth.Nonce = new(big.Int).Add(auth.Nonce, big.NewInt(1))
+}
@@ -0,0 +1,334 @@
+// Package webhook relays decoded events to HTTP endpoints as signed JSON,
+// retrying with backoff, preserving order per contract and dead-lettering
+// what cannot be delivered.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/obingo31/go-eth/logdecode"
+)
+
+// Endpoint is one receiver and the secret its deliveries are signed with.
+type Endpoint struct {
+	URL    string
+	Secret string
+}
+
+// Config controls delivery. Zero fields take the defaults noted below.
+type Config struct {
+	Endpoints   []Endpoint
+	DeadLetter  string        // NDJSON file for undeliverable events (required)
+	MaxAttempts int           // default 8
+	MinBackoff  time.Duration // default 500ms, doubled per retry
+	MaxBackoff  time.Duration // default 30s
+	Timeout     time.Duration // per request, default 10s
+	QueueSize   int           // events buffered per endpoint and contract, default 1024
+}
+
+// Payload is the JSON body of a delivery.
+type Payload struct {
+	ID      string           `json:"id"`
+	Removed bool             `json:"removed"`
+	Event   logdecode.Record `json:"event"`
+}
+
+// EventID identifies a log independently of whether it is being added or
+// removed: "<blockHash>:<logIndex>".
+func EventID(rec logdecode.Record) string {
+	return fmt.Sprintf("%s:%d", rec.BlockHash.Hex(), rec.LogIndex)
+}
+
+// EndpointStatus is the delivery report of one endpoint.
+type EndpointStatus struct {
+	URL             string    `json:"url"`
+	Delivered       int       `json:"delivered"`
+	Retries         int       `json:"retries"`
+	DeadLettered    int       `json:"deadLettered"`
+	Pending         int       `json:"pending"`
+	LastError       string    `json:"lastError,omitempty"`
+	LastErrorAt     time.Time `json:"lastErrorAt,omitzero"`
+	LastDeliveredAt time.Time `json:"lastDeliveredAt,omitzero"`
+}
+
+type queueKey struct {
+	endpoint int
+	contract common.Address
+}
+
+// Relay fans events out to the configured endpoints. Each endpoint/contract
+// pair has its own queue and worker, so one slow contract or receiver does
+// not hold up the others while events of a contract stay in order.
+type Relay struct {
+	cfg    Config
+	client *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// sendMu is held shared by Send and exclusively by Close, so queues are
+	// never closed while an event is being pushed.
+	sendMu sync.RWMutex
+	closed bool
+
+	mu     sync.Mutex
+	queues map[queueKey]chan Payload
+	status []EndpointStatus
+
+	deadMu sync.Mutex
+	dead   *os.File
+}
+
+// New validates cfg and returns a relay ready to Send.
+func New(cfg Config) (*Relay, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, fmt.Errorf("no webhook endpoints configured")
+	}
+	if cfg.DeadLetter == "" {
+		return nil, fmt.Errorf("a dead-letter file is required")
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = 500 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 30 * time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+	dead, err := os.OpenFile(cfg.DeadLetter, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open dead-letter file: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Relay{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		ctx:    ctx,
+		cancel: cancel,
+		queues: make(map[queueKey]chan Payload),
+		status: make([]EndpointStatus, len(cfg.Endpoints)),
+		dead:   dead,
+	}
+	for i, ep := range cfg.Endpoints {
+		r.status[i].URL = ep.URL
+	}
+	return r, nil
+}
+
+// Send queues rec for every endpoint. It blocks when a queue is full, which
+// slows the event source down instead of dropping events.
+func (r *Relay) Send(rec logdecode.Record) error {
+	r.sendMu.RLock()
+	defer r.sendMu.RUnlock()
+	if r.closed {
+		return fmt.Errorf("relay is closed")
+	}
+
+	p := Payload{ID: EventID(rec), Removed: rec.Removed, Event: rec}
+	for i := range r.cfg.Endpoints {
+		r.mu.Lock()
+		key := queueKey{endpoint: i, contract: rec.Address}
+		q, ok := r.queues[key]
+		if !ok {
+			q = make(chan Payload, r.cfg.QueueSize)
+			r.queues[key] = q
+			r.wg.Add(1)
+			go r.worker(i, q)
+		}
+		r.status[i].Pending++
+		r.mu.Unlock()
+		q <- p
+	}
+	return nil
+}
+
+// Status returns a snapshot of the per-endpoint delivery report.
+func (r *Relay) Status() []EndpointStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]EndpointStatus, len(r.status))
+	copy(out, r.status)
+	return out
+}
+
+// StatusHandler serves the delivery report as JSON.
+func (r *Relay) StatusHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(r.Status())
+	})
+}
+
+// Close stops accepting events and waits for the queues to drain. Events
+// still queued or being retried when ctx is done are dead-lettered.
+func (r *Relay) Close(ctx context.Context) error {
+	r.sendMu.Lock()
+	if !r.closed {
+		r.closed = true
+		r.mu.Lock()
+		for _, q := range r.queues {
+			close(q)
+		}
+		r.mu.Unlock()
+	}
+	r.sendMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		r.cancel()
+		<-done
+	}
+	r.cancel()
+	r.deadMu.Lock()
+	defer r.deadMu.Unlock()
+	return r.dead.Close()
+}
+
+func (r *Relay) worker(endpoint int, q <-chan Payload) {
+	defer r.wg.Done()
+	for p := range q {
+		r.deliver(endpoint, p)
+		r.mu.Lock()
+		r.status[endpoint].Pending--
+		r.mu.Unlock()
+	}
+}
+
+// deliver posts p until it is accepted, the receiver rejects it permanently,
+// attempts run out or the relay shuts down; the last three dead-letter it.
+func (r *Relay) deliver(endpoint int, p Payload) {
+	ep := r.cfg.Endpoints[endpoint]
+	body, err := json.Marshal(p)
+	if err != nil {
+		r.deadLetter(endpoint, p, 0, fmt.Errorf("encode payload: %w", err))
+		return
+	}
+
+	backoff := r.cfg.MinBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := r.post(ep, p.ID, body)
+		if err == nil {
+			r.mu.Lock()
+			r.status[endpoint].Delivered++
+			r.status[endpoint].LastDeliveredAt = time.Now().UTC()
+			r.mu.Unlock()
+			return
+		}
+		r.mu.Lock()
+		r.status[endpoint].LastError = err.Error()
+		r.status[endpoint].LastErrorAt = time.Now().UTC()
+		r.mu.Unlock()
+		if !retry || attempt >= r.cfg.MaxAttempts {
+			r.deadLetter(endpoint, p, attempt, err)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-r.ctx.Done():
+			r.deadLetter(endpoint, p, attempt, fmt.Errorf("shutdown before delivery: %w", err))
+			return
+		}
+		backoff = min(2*backoff, r.cfg.MaxBackoff)
+		r.mu.Lock()
+		r.status[endpoint].Retries++
+		r.mu.Unlock()
+	}
+}
+
+// post sends one attempt. Client errors other than 408 and 429 are treated
+// as permanent; everything else is retried.
+func (r *Relay) post(ep Endpoint, id string, body []byte) (bool, error) {
+	if r.ctx.Err() != nil {
+		return false, r.ctx.Err()
+	}
+	req, err := http.NewRequestWithContext(r.ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, id)
+	req.Header.Set(TimestampHeader, timestamp)
+	if ep.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(ep.Secret, timestamp, body))
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("%s: %s", ep.URL, resp.Status)
+	default:
+		return false, fmt.Errorf("%s: %s", ep.URL, resp.Status)
+	}
+}
+
+// deadLetter appends one NDJSON line describing the failed delivery.
+func (r *Relay) deadLetter(endpoint int, p Payload, attempts int, cause error) {
+	entry := struct {
+		Endpoint string    `json:"endpoint"`
+		Attempts int       `json:"attempts"`
+		Error    string    `json:"error"`
+		Time     time.Time `json:"time"`
+		Payload  Payload   `json:"payload"`
+	}{r.cfg.Endpoints[endpoint].URL, attempts, cause.Error(), time.Now().UTC(), p}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		line = []byte(fmt.Sprintf(`{"endpoint":%q,"error":%q,"id":%q}`, entry.Endpoint, err.Error(), p.ID))
+	}
+
+	r.deadMu.Lock()
+	r.dead.Write(append(line, '\n'))
+	r.deadMu.Unlock()
+
+	r.mu.Lock()
+	r.status[endpoint].DeadLettered++
+	r.mu.Unlock()
+}
+
+// ParseEndpoints pairs comma-separated URLs with a shared secret.
+func ParseEndpoints(urls, secret string) []Endpoint {
+	var out []Endpoint
+	for _, u := range strings.Split(urls, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			out = append(out, Endpoint{URL: u, Secret: secret})
+		}
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers set on every delivery.
+const (
+	SignatureHeader = "X-Webhook-Signature" // "sha256=" + hex HMAC of "<timestamp>.<body>"
+	TimestampHeader = "X-Webhook-Timestamp" // unix seconds
+	EventIDHeader   = "X-Webhook-Event-Id"
+)
+
+// Sign returns the signature header value for body sent at timestamp.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a received delivery. Deliveries
+// whose timestamp is further than tolerance from now are rejected to limit
+// replays; a zero tolerance skips that check.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration) error {
+	timestamp := header.Get(TimestampHeader)
+	signature := header.Get(SignatureHeader)
+	if timestamp == "" || signature == "" {
+		return errors.New("missing signature headers")
+	}
+	if tolerance > 0 {
+		secs, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid timestamp %q", timestamp)
+		}
+		if age := time.Since(time.Unix(secs, 0)); age > tolerance || age < -tolerance {
+			return fmt.Errorf("timestamp outside tolerance (%s)", age.Round(time.Second))
+		}
+	}
+	want := Sign(secret, timestamp, body)
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(want)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
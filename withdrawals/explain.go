@@ -0,0 +1,180 @@
+package withdrawals
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Client is the part of ethclient.Client needed by Explain.
+type Client interface {
+	BlockReader
+	BalanceAt(ctx context.Context, account common.Address, block *big.Int) (*big.Int, error)
+	BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Explanation breaks an account's balance change between two blocks into
+// the parts visible in blocks, transactions and receipts. Other is what is
+// left: value moved by internal calls, self-destructs and the like.
+type Explanation struct {
+	Address     common.Address `json:"address"`
+	From        uint64         `json:"from"` // balance taken after this block
+	To          uint64         `json:"to"`
+	Before      *big.Int       `json:"before"`
+	After       *big.Int       `json:"after"`
+	Change      *big.Int       `json:"change"`
+	Withdrawals []Withdrawal   `json:"withdrawals"`
+	Withdrawn   *big.Int       `json:"withdrawn"` // credited by withdrawals
+	Received    *big.Int       `json:"received"`  // value of successful transactions to the account
+	Sent        *big.Int       `json:"sent"`      // value of successful transactions from the account
+	Fees        *big.Int       `json:"fees"`      // gas and blob gas paid by transactions from the account
+	Tips        *big.Int       `json:"tips"`      // priority fees earned as the blocks' fee recipient
+	Txs         int            `json:"txs"`       // transactions sent or received
+	Other       *big.Int       `json:"other"`
+}
+
+// explained is one block's contribution to an Explanation.
+type explained struct {
+	withdrawals                []Withdrawal
+	received, sent, fees, tips *big.Int
+	txs                        int
+}
+
+// Explain compares addr's balance after block from with its balance after
+// block to, and attributes the difference by scanning the blocks in
+// between. Both balances need state for those blocks.
+func Explain(ctx context.Context, client Client, addr common.Address, from, to uint64, concurrency int) (*Explanation, error) {
+	if to < from {
+		return nil, fmt.Errorf("empty range %d-%d", from, to)
+	}
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+	e := &Explanation{Address: addr, From: from, To: to}
+	var err error
+	if e.Before, err = client.BalanceAt(ctx, addr, new(big.Int).SetUint64(from)); err != nil {
+		return nil, fmt.Errorf("balance at block %d: %w", from, err)
+	}
+	if e.After, err = client.BalanceAt(ctx, addr, new(big.Int).SetUint64(to)); err != nil {
+		return nil, fmt.Errorf("balance at block %d: %w", to, err)
+	}
+	e.Change = new(big.Int).Sub(e.After, e.Before)
+	e.Withdrawn, e.Received, e.Sent, e.Fees, e.Tips = new(big.Int), new(big.Int), new(big.Int), new(big.Int), new(big.Int)
+	if to == from {
+		e.Other = new(big.Int)
+		return e, nil
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch chain ID: %w", err)
+	}
+	signer := types.LatestSignerForChainID(chainID)
+
+	parts := make([]explained, to-from)
+	err = forEachBlock(ctx, client, from+1, to, concurrency, func(i int, block *types.Block) error {
+		var err error
+		parts[i], err = explainBlock(ctx, client, signer, addr, block)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range parts {
+		e.Withdrawals = append(e.Withdrawals, p.withdrawals...)
+		e.Received.Add(e.Received, p.received)
+		e.Sent.Add(e.Sent, p.sent)
+		e.Fees.Add(e.Fees, p.fees)
+		e.Tips.Add(e.Tips, p.tips)
+		e.Txs += p.txs
+	}
+	for _, w := range e.Withdrawals {
+		e.Withdrawn.Add(e.Withdrawn, w.Wei())
+	}
+	explainedChange := new(big.Int).Add(e.Withdrawn, e.Received)
+	explainedChange.Add(explainedChange, e.Tips)
+	explainedChange.Sub(explainedChange, e.Sent)
+	explainedChange.Sub(explainedChange, e.Fees)
+	e.Other = new(big.Int).Sub(e.Change, explainedChange)
+	return e, nil
+}
+
+func explainBlock(ctx context.Context, client Client, signer types.Signer, addr common.Address, block *types.Block) (explained, error) {
+	p := explained{received: new(big.Int), sent: new(big.Int), fees: new(big.Int), tips: new(big.Int)}
+	for _, w := range block.Withdrawals() {
+		if w.Address == addr {
+			p.withdrawals = append(p.withdrawals, newWithdrawal(block, w))
+		}
+	}
+
+	// Receipts are only needed when the account sent or received a
+	// transaction, or collected the block's priority fees.
+	coinbase := block.Coinbase() == addr
+	type involvement struct {
+		index int
+		sent  bool
+	}
+	var involved []involvement
+	for i, tx := range block.Transactions() {
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return p, fmt.Errorf("recover sender of %s: %w", tx.Hash().Hex(), err)
+		}
+		if from == addr || (tx.To() != nil && *tx.To() == addr) {
+			involved = append(involved, involvement{i, from == addr})
+		}
+	}
+	if len(involved) == 0 && (!coinbase || len(block.Transactions()) == 0) {
+		return p, nil
+	}
+	receipts, err := client.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+	if err != nil {
+		return p, fmt.Errorf("fetch receipts: %w", err)
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return p, fmt.Errorf("%d receipts for %d transactions", len(receipts), len(block.Transactions()))
+	}
+
+	txs := block.Transactions()
+	for _, inv := range involved {
+		tx, r := txs[inv.index], receipts[inv.index]
+		p.txs++
+		if inv.sent {
+			p.fees.Add(p.fees, fee(r))
+		}
+		if r.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+		if inv.sent {
+			p.sent.Add(p.sent, tx.Value())
+		}
+		if tx.To() != nil && *tx.To() == addr {
+			p.received.Add(p.received, tx.Value())
+		}
+	}
+	if coinbase {
+		for _, r := range receipts {
+			tip := new(big.Int).Set(r.EffectiveGasPrice)
+			if block.BaseFee() != nil {
+				tip.Sub(tip, block.BaseFee())
+			}
+			p.tips.Add(p.tips, tip.Mul(tip, new(big.Int).SetUint64(r.GasUsed)))
+		}
+	}
+	return p, nil
+}
+
+// fee is what a transaction's sender paid for gas and blob gas.
+func fee(r *types.Receipt) *big.Int {
+	total := new(big.Int).Mul(r.EffectiveGasPrice, new(big.Int).SetUint64(r.GasUsed))
+	if r.BlobGasPrice != nil {
+		total.Add(total, new(big.Int).Mul(r.BlobGasPrice, new(big.Int).SetUint64(r.BlobGasUsed)))
+	}
+	return total
+}
@@ -0,0 +1,207 @@
+// Package withdrawals scans post-Shanghai blocks for beacon chain
+// withdrawals (EIP-4895), filters them by recipient or validator, totals
+// them per address and period, and explains how an account's balance
+// changed between two blocks.
+package withdrawals
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/obingo31/go-eth/parallel"
+)
+
+// BlockReader is the part of ethclient.Client needed to scan blocks.
+type BlockReader interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// Config selects the withdrawals Scan returns. Zero fields take the
+// defaults; empty filters match everything.
+type Config struct {
+	Concurrency int // blocks fetched in parallel (default 8)
+	Addresses   []common.Address
+	Validators  []uint64
+}
+
+func (c Config) withDefaults() Config {
+	if c.Concurrency <= 0 {
+		c.Concurrency = 8
+	}
+	return c
+}
+
+// Withdrawal is one withdrawal with the block that credited it.
+type Withdrawal struct {
+	Block     uint64         `json:"block"`
+	Time      uint64         `json:"time"`
+	Index     uint64         `json:"index"`
+	Validator uint64         `json:"validator"`
+	Address   common.Address `json:"address"`
+	Amount    uint64         `json:"amount"` // gwei
+}
+
+// Wei returns the amount credited in wei.
+func (w Withdrawal) Wei() *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(w.Amount), big.NewInt(params.GWei))
+}
+
+func newWithdrawal(block *types.Block, w *types.Withdrawal) Withdrawal {
+	return Withdrawal{
+		Block:     block.NumberU64(),
+		Time:      block.Time(),
+		Index:     w.Index,
+		Validator: w.Validator,
+		Address:   w.Address,
+		Amount:    w.Amount,
+	}
+}
+
+// Scan returns the withdrawals in blocks from-to that match cfg, in chain
+// order. Blocks from before Shanghai have none.
+func Scan(ctx context.Context, client BlockReader, from, to uint64, cfg Config) ([]Withdrawal, error) {
+	if to < from {
+		return nil, fmt.Errorf("empty range %d-%d", from, to)
+	}
+	cfg = cfg.withDefaults()
+	addresses := make(map[common.Address]bool, len(cfg.Addresses))
+	for _, a := range cfg.Addresses {
+		addresses[a] = true
+	}
+	validators := make(map[uint64]bool, len(cfg.Validators))
+	for _, v := range cfg.Validators {
+		validators[v] = true
+	}
+
+	found := make([][]Withdrawal, to-from+1)
+	err := forEachBlock(ctx, client, from, to, cfg.Concurrency, func(i int, block *types.Block) error {
+		for _, w := range block.Withdrawals() {
+			if len(addresses) > 0 && !addresses[w.Address] {
+				continue
+			}
+			if len(validators) > 0 && !validators[w.Validator] {
+				continue
+			}
+			found[i] = append(found[i], newWithdrawal(block, w))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var out []Withdrawal
+	for _, ws := range found {
+		out = append(out, ws...)
+	}
+	return out, nil
+}
+
+// forEachBlock fetches blocks from-to with concurrency workers and calls fn
+// (concurrently) with each block and its offset from from. The first error
+// stops the walk.
+func forEachBlock(ctx context.Context, client BlockReader, from, to uint64, concurrency int, fn func(i int, block *types.Block) error) error {
+	return parallel.Run(ctx, int(to-from+1), concurrency, func(ctx context.Context, i int) error {
+		number := from + uint64(i)
+		block, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return fmt.Errorf("fetch block %d: %w", number, err)
+		}
+		if err := fn(i, block); err != nil {
+			return fmt.Errorf("block %d: %w", number, err)
+		}
+		return nil
+	})
+}
+
+// Periods accepted by Totals.
+const (
+	PeriodDay   = "day"
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+	PeriodAll   = "all"
+)
+
+// PeriodLabel returns the period (in UTC) a block timestamp falls in:
+// 2024-01-31, the Monday starting its ISO week, 2024-01, or "all".
+func PeriodLabel(timestamp uint64, period string) (string, error) {
+	t := time.Unix(int64(timestamp), 0).UTC()
+	switch period {
+	case PeriodDay:
+		return t.Format("2006-01-02"), nil
+	case PeriodWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		return t.AddDate(0, 0, -offset).Format("2006-01-02"), nil
+	case PeriodMonth:
+		return t.Format("2006-01"), nil
+	case PeriodAll, "":
+		return PeriodAll, nil
+	default:
+		return "", fmt.Errorf("unknown period %q (want day, week, month or all)", period)
+	}
+}
+
+// Total is the sum of one address's withdrawals in one period.
+type Total struct {
+	Address    common.Address `json:"address"`
+	Period     string         `json:"period"`
+	Count      int            `json:"count"`
+	Validators int            `json:"validators"` // distinct validators withdrawing to the address
+	Amount     uint64         `json:"amount"`     // gwei
+	FirstBlock uint64         `json:"firstBlock"`
+	LastBlock  uint64         `json:"lastBlock"`
+}
+
+// Wei returns the total in wei.
+func (t Total) Wei() *big.Int {
+	return Withdrawal{Amount: t.Amount}.Wei()
+}
+
+// Totals groups withdrawals by address and period, ordered by period and
+// then by amount, largest first.
+func Totals(ws []Withdrawal, period string) ([]Total, error) {
+	type key struct {
+		addr   common.Address
+		period string
+	}
+	totals := make(map[key]*Total)
+	validators := make(map[key]map[uint64]bool)
+	for _, w := range ws {
+		label, err := PeriodLabel(w.Time, period)
+		if err != nil {
+			return nil, err
+		}
+		k := key{w.Address, label}
+		t, ok := totals[k]
+		if !ok {
+			t = &Total{Address: w.Address, Period: label, FirstBlock: w.Block}
+			totals[k] = t
+			validators[k] = make(map[uint64]bool)
+		}
+		t.Count++
+		t.Amount += w.Amount
+		t.LastBlock = w.Block
+		validators[k][w.Validator] = true
+		t.Validators = len(validators[k])
+	}
+	out := make([]Total, 0, len(totals))
+	for _, t := range totals {
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Period != out[j].Period {
+			return out[i].Period < out[j].Period
+		}
+		if out[i].Amount != out[j].Amount {
+			return out[i].Amount > out[j].Amount
+		}
+		return out[i].Address.Cmp(out[j].Address) < 0
+	})
+	return out, nil
+}